@@ -3,10 +3,22 @@ package main
 import (
 	_ "effective_mobile_tz/docs"
 	"effective_mobile_tz/internal/app"
+	"os"
 )
 
 const configPath = "config/config.yaml"
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			app.Import(configPath, os.Args[2:])
+			return
+		case "mockinfo":
+			app.MockInfo(os.Args[2:])
+			return
+		}
+	}
+
 	app.Run(configPath)
 }
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"effective_mobile_tz/internal/app"
+	"os"
+)
+
+func main() {
+	app.MockInfo(os.Args[1:])
+}
@@ -11,10 +11,17 @@ import (
 
 type (
 	Config struct {
-		HTTP        `yaml:"http"`
-		Log         `yaml:"log"`
-		PG          `yaml:"postgres"`
-		ExternalAPI `yaml:"externalAPI"`
+		HTTP          `yaml:"http"`
+		Log           `yaml:"log"`
+		Storage       `yaml:"storage"`
+		PG            `yaml:"postgres"`
+		ExternalAPI   `yaml:"externalAPI"`
+		Metadata      `yaml:"metadata"`
+		MetadataCache `yaml:"metadataCache"`
+		Search        `yaml:"search"`
+		FuzzySearch   `yaml:"fuzzySearch"`
+		Pagination    `yaml:"pagination"`
+		Enrichment    `yaml:"enrichment"`
 	}
 
 	HTTP struct {
@@ -26,13 +33,64 @@ type (
 		Level string `env-required:"false" yaml:"level"`
 	}
 
+	Storage struct {
+		Driver string `env-required:"false" env-default:"postgres" env:"STORAGE_DRIVER" yaml:"driver"`
+	}
+
 	PG struct {
-		URL           string `env-required:"false" env:"PG_URL"`
-		MigrationPath string `env-required:"false" env-default:"./migrations" env:"PG_MIGRATION_PATH"`
+		URL               string        `env-required:"false" env:"PG_URL"`
+		MigrationPath     string        `env-required:"false" env-default:"./migrations" env:"PG_MIGRATION_PATH"`
+		MaxConns          int32         `env-required:"false" env-default:"10" env:"PG_MAX_CONNS" yaml:"maxConns"`
+		MinConns          int32         `env-required:"false" env-default:"2" env:"PG_MIN_CONNS" yaml:"minConns"`
+		MaxConnLifetime   time.Duration `env-required:"false" env-default:"1h" env:"PG_MAX_CONN_LIFETIME" yaml:"maxConnLifetime"`
+		MaxConnIdleTime   time.Duration `env-required:"false" env-default:"30m" env:"PG_MAX_CONN_IDLE_TIME" yaml:"maxConnIdleTime"`
+		HealthCheckPeriod time.Duration `env-required:"false" env-default:"1m" env:"PG_HEALTH_CHECK_PERIOD" yaml:"healthCheckPeriod"`
+		ConnectTimeout    time.Duration `env-required:"false" env-default:"5s" env:"PG_CONNECT_TIMEOUT" yaml:"connectTimeout"`
 	}
 
 	ExternalAPI struct {
-		URL string `env-required:"false" yaml:"URL"`
+		URL              string        `env-required:"false" env:"EXTERNAL_API_URL" yaml:"URL"`
+		Timeout          time.Duration `env-required:"false" env-default:"5s" env:"EXTERNAL_API_TIMEOUT" yaml:"timeout"`
+		MaxRetries       int           `env-required:"false" env-default:"2" env:"EXTERNAL_API_MAX_RETRIES" yaml:"maxRetries"`
+		RetryBackoff     time.Duration `env-required:"false" env-default:"200ms" env:"EXTERNAL_API_RETRY_BACKOFF" yaml:"retryBackoff"`
+		RetryMaxBackoff  time.Duration `env-required:"false" env-default:"2s" env:"EXTERNAL_API_RETRY_MAX_BACKOFF" yaml:"retryMaxBackoff"`
+		BreakerThreshold int           `env-required:"false" env-default:"5" env:"EXTERNAL_API_BREAKER_THRESHOLD" yaml:"breakerThreshold"`
+		BreakerCooldown  time.Duration `env-required:"false" env-default:"30s" env:"EXTERNAL_API_BREAKER_COOLDOWN" yaml:"breakerCooldown"`
+	}
+
+	Metadata struct {
+		Providers  []string `env-required:"false" env-default:"api" env-separator:"," env:"METADATA_PROVIDERS" yaml:"providers"`
+		FixtureDir string   `env-required:"false" env-default:"./fixtures" env:"METADATA_FIXTURE_DIR" yaml:"fixtureDir"`
+	}
+
+	MetadataCache struct {
+		Disabled    bool          `env:"METADATA_CACHE_DISABLED" yaml:"disabled"`
+		Size        int           `env-required:"false" env-default:"1000" env:"METADATA_CACHE_SIZE" yaml:"size"`
+		TTL         time.Duration `env-required:"false" env-default:"24h" env:"METADATA_CACHE_TTL" yaml:"ttl"`
+		NegativeTTL time.Duration `env-required:"false" env-default:"1h" env:"METADATA_CACHE_NEGATIVE_TTL" yaml:"negativeTTL"`
+		Persistent  bool          `env:"METADATA_CACHE_PERSISTENT" yaml:"persistent"`
+	}
+
+	Search struct {
+		Language string `env-required:"false" env-default:"english" env:"SEARCH_LANGUAGE" yaml:"language"`
+	}
+
+	FuzzySearch struct {
+		SimilarityThreshold float64 `env-required:"false" env-default:"0.3" env:"FUZZY_SIMILARITY_THRESHOLD" yaml:"similarityThreshold"`
+		SuggestionLimit     int     `env-required:"false" env-default:"10" env:"FUZZY_SUGGESTION_LIMIT" yaml:"suggestionLimit"`
+	}
+
+	Pagination struct {
+		CursorSecret string `env:"PAGINATION_CURSOR_SECRET" yaml:"cursorSecret"`
+	}
+
+	Enrichment struct {
+		Workers         int           `env-required:"false" env-default:"2" env:"ENRICHMENT_WORKERS" yaml:"workers"`
+		PollInterval    time.Duration `env-required:"false" env-default:"1s" env:"ENRICHMENT_POLL_INTERVAL" yaml:"pollInterval"`
+		Lease           time.Duration `env-required:"false" env-default:"1m" env:"ENRICHMENT_LEASE" yaml:"lease"`
+		MaxAttempts     int           `env-required:"false" env-default:"5" env:"ENRICHMENT_MAX_ATTEMPTS" yaml:"maxAttempts"`
+		RetryBackoff    time.Duration `env-required:"false" env-default:"10s" env:"ENRICHMENT_RETRY_BACKOFF" yaml:"retryBackoff"`
+		RetryMaxBackoff time.Duration `env-required:"false" env-default:"10m" env:"ENRICHMENT_RETRY_MAX_BACKOFF" yaml:"retryMaxBackoff"`
 	}
 )
 
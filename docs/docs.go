@@ -15,9 +15,2586 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/albums": {
+            "get": {
+                "description": "This endpoint retrieves albums, optionally filtered by group ID and title.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Get albums",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by group ID",
+                        "name": "groupId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by title",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of albums retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint creates a new album (or single) of the group. The release date is in YYYY-MM-DD format.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Creates a new album",
+                "parameters": [
+                    {
+                        "description": "Album creation input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.albumCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Album created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/albums/{album_id}": {
+            "get": {
+                "description": "This endpoint retrieves an album with its tracklist by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Get an album by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Album ID to retrieve",
+                        "name": "album_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Album retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - album not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint updates an album's details. An empty release date clears it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Update an album",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Album ID to update",
+                        "name": "album_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Album update input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.AlbumUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Album updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or album not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes an album by its ID. Its songs are kept and detached from the album.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Delete an album",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Album ID to delete",
+                        "name": "album_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Album deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - album not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/jobs": {
+            "get": {
+                "description": "This endpoint lists the enrichment jobs, most recently updated first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "List enrichment jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending, running, done or failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (must be provided with page)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enrichment jobs retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/requeue": {
+            "post": {
+                "description": "This endpoint puts every failed enrichment job back in the queue with its attempts reset and returns how many were requeued.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Requeue every failed enrichment",
+                "responses": {
+                    "200": {
+                        "description": "Failed enrichments requeued successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
             "get": {
-                "description": "This endpoint retrieves songs from the library based on various filter criteria such as title, group, link, text, release date range, and pagination.",
+                "description": "This endpoint retrieves all groups together with the number of songs each of them has.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get groups",
+                "responses": {
+                    "200": {
+                        "description": "List of groups retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group_id}": {
+            "get": {
+                "description": "This endpoint retrieves a group with its discography by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get a group by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID to retrieve",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - group not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint renames a group by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Rename a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID to rename",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Group rename input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.groupRenameInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group renamed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input, group not found or name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a group by its ID. If the group still has songs, the request is refused unless cascade is set, in which case the songs are deleted as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID to delete",
+                        "name": "group_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete the songs of the group as well",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - group not found or group still has songs",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/library/export": {
+            "get": {
+                "description": "This endpoint streams every group, album, tag, song (with its tag assignments) and lyrics verse as a versioned archive, read within a single transaction. The json format is one object with the version, the export time and an array per record type; ndjson writes a header record followed by one {\"type\",\"data\"} record per line. With gzip=true the archive is gzip-compressed.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "library"
+                ],
+                "summary": "Export the whole library",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Archive format (json or ndjson, default json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compress the archive with gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Library archive",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/library/restore": {
+            "post": {
+                "description": "This endpoint restores a json or ndjson archive produced by the export, gzip-compressed or not, sent either as the raw request body or as the \"file\" field of a multipart form. Records keep their IDs and the whole restore runs in a single transaction. On a clash with an existing record (same ID or same name) the skip policy keeps the existing record and attaches the archived records referring to it; overwrite replaces records with the same ID, or the existing record with the same name in place of an archived record with another ID; fail (default) aborts the restore. The report lists how many records of each type were restored and skipped.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/gzip",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "library"
+                ],
+                "summary": "Restore the library from an archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conflict policy (skip, overwrite or fail, default fail)",
+                        "name": "policy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Archive to restore",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restore report",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid policy or archive, or a record clashes with an existing one",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metadata/cache": {
+            "get": {
+                "description": "This endpoint returns the state of the cache in front of the metadata provider: its capacity and entries, the entries kept in the database when the cache is persistent, and the hits, negative hits (songs the provider did not know), misses and evictions since the start.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metadata"
+                ],
+                "summary": "Get song metadata cache stats",
+                "responses": {
+                    "200": {
+                        "description": "Cache stats retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint drops the cached lookup of the song when group and title are given, or every cached lookup when neither is, and returns how many entries were dropped from memory and from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metadata"
+                ],
+                "summary": "Purge the song metadata cache",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name (must be provided with title)",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song title (must be provided with group)",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cache purged successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists": {
+            "get": {
+                "description": "This endpoint retrieves all playlists with the number of entries each of them has.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Get playlists",
+                "responses": {
+                    "200": {
+                        "description": "List of playlists retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint creates a new empty playlist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Creates a new playlist",
+                "parameters": [
+                    {
+                        "description": "Playlist creation input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.playlistCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist_id}": {
+            "get": {
+                "description": "This endpoint retrieves a playlist with its ordered entries and the details of their songs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Get a playlist by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID to retrieve",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint renames a playlist or changes its description.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Update a playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID to update",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playlist update input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.PlaylistUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a playlist with all of its entries.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Delete a playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID to delete",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist_id}/entries": {
+            "post": {
+                "description": "This endpoint appends a song to the playlist, or inserts it at the given 1-based position shifting the following entries down.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Add a song to a playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playlist entry input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.playlistEntryInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input, playlist or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist_id}/entries/{entry_id}": {
+            "delete": {
+                "description": "This endpoint removes an entry from the playlist and closes the gap it leaves.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Remove a playlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Playlist entry ID",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist_id}/entries/{entry_id}/position": {
+            "put": {
+                "description": "This endpoint moves an entry to the given 1-based position, shifting the entries in between.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Move a playlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist ID",
+                        "name": "playlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Playlist entry ID",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New position",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.playlistEntryMoveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid position, playlist or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "This endpoint searches the lyrics in the configured text search language, so that the words match in any of their forms. Songs are ranked by relevance and come with a snippet of the matching verses, the matching words wrapped in \u003cb\u003e\u003c/b\u003e, and the numbers of the matching verses. The query takes words, which must all occur in the song, \"quoted phrases\", prefixes ending with * (love*), words or phrases excluded with a leading - (-rain) and alternatives joined by OR (night OR day).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Search songs by lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (must be provided with page)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching songs retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid query or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/suggest": {
+            "get": {
+                "description": "This endpoint autocompletes a song title or a group name that may be incomplete or misspelt. It returns the titles and the groups whose word similarity to the query reaches the configured threshold, best first, each with its similarity score from 0 to 1.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Suggest song titles and groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Title or group name, or a part of it",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of suggestions of each kind (at most 50, default configured)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Suggestions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - empty query or invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "This endpoint retrieves songs from the library based on various filter criteria such as title, group, link, text, release date range, and pagination, sorted by one or more keys. The content holds the songs of the page as items, the number of songs matching the filter as total, and the page and limit (0 when not paginated). Paginated listings also hold nextCursor and prevCursor, which are given to after and before for the next and the previous page; unlike pages, cursors never skip or repeat songs when songs are added or removed meanwhile. A cursor is only valid with the sort order it was returned for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Get songs by filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match title and group despite typos, by trigram similarity",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by lyrics containing all of the words, in any of their forms",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the song has lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album title",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album ID",
+                        "name": "albumId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by any of the comma-separated tag names",
+                        "name": "anyTag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by all of the comma-separated tag names",
+                        "name": "allTags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (the first page without page)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated details loaded along with the songs among lyrics and tags (all of them by default, none when empty)",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the song the page follows (nextCursor of the previous page, must be provided with limit)",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the song the page precedes (prevCursor of the next page, must be provided with limit)",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of songs retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint updates a song's details. The song ID must be provided in the request body. An empty albumId detaches the song from its album, which must otherwise belong to the group of the song. The release date may be partial (DD.MM.YYYY, YYYY-MM-DD, YYYY-MM, YYYY and common variants); how much of it is known is returned as releaseDatePrecision (day, month or year).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Update a song",
+                "parameters": [
+                    {
+                        "description": "Song update input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.SongUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint creates a new song by specifying the group and title. The song is stored right away with the pending enrichment status; its release date, link and lyrics are filled in from the metadata provider in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Creates a new song",
+                "parameters": [
+                    {
+                        "description": "Song creation input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.songCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - song already exists",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export": {
+            "get": {
+                "description": "This endpoint exports songs as an M3U8, XSPF or PLS playlist. Songs are selected either by the song listing filters or by an explicit list of IDs (repeated id query parameter or ids in the JSON body of a POST request), in which case their order is kept. The format is taken from the format parameter, otherwise from the Accept header, and defaults to M3U8. Songs without a link are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/vnd.apple.mpegurl",
+                    "application/xspf+xml",
+                    "audio/x-scpls"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Export songs as a playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist format (m3u8, xspf or pls)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Song IDs to export",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match title and group despite typos, by trigram similarity",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by lyrics containing all of the words, in any of their forms",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the song has lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album title",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album ID",
+                        "name": "albumId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by any of the comma-separated tag names",
+                        "name": "anyTag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by all of the comma-separated tag names",
+                        "name": "allTags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (the first page without page)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Explicit list of song IDs (POST only)",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.songExportInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter parameters or format",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint exports songs as an M3U8, XSPF or PLS playlist. Songs are selected either by the song listing filters or by an explicit list of IDs (repeated id query parameter or ids in the JSON body of a POST request), in which case their order is kept. The format is taken from the format parameter, otherwise from the Accept header, and defaults to M3U8. Songs without a link are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/vnd.apple.mpegurl",
+                    "application/xspf+xml",
+                    "audio/x-scpls"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Export songs as a playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist format (m3u8, xspf or pls)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Song IDs to export",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match title and group despite typos, by trigram similarity",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by lyrics containing all of the words, in any of their forms",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the song has lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album title",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album ID",
+                        "name": "albumId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by any of the comma-separated tag names",
+                        "name": "anyTag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by all of the comma-separated tag names",
+                        "name": "allTags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (the first page without page)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Explicit list of song IDs (POST only)",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.songExportInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter parameters or format",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "description": "This endpoint creates songs out of a CSV (with a header row), JSON array or NDJSON file of group, title and optionally releaseDate (DD.MM.YYYY, YYYY-MM-DD, YYYY-MM or YYYY), link and lyrics. The file is sent either as the raw request body or as the \"file\" field of a multipart form. The format is taken from the format parameter, the file extension or the content type. With enrich=auto (default) only rows that supply none of releaseDate, link and lyrics are completed from the external API; always enriches every row and never skips enrichment. Rows are committed in batches, and the report lists every row as created, duplicate or failed. A dry run reports the outcome without storing anything or calling the external API.",
+                "consumes": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Bulk import songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Input format (csv, json or ndjson)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichment mode (auto, always or never)",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report the outcome without storing anything",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of rows committed together (default 100)",
+                        "name": "batchSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to import",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters or undecodable file",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/lyrics/{song_id}": {
+            "get": {
+                "description": "This endpoint retrieves paginated lyrics for a specific song by its ID, line by line or, with by=stanza, stanza by stanza along with the lines and the label of each stanza. With page and limit it returns the lines or stanzas of the page. With limit alone, or with the after or before cursor, it returns the lines or stanzas following or preceding the cursor (from the first one without a cursor) along with nextCursor and prevCursor, to be given to after and before for the next and the previous page. Cursors only work with the by value they were returned with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Get paginated lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "stanza"
+                        ],
+                        "type": "string",
+                        "description": "Paginate by line (default) or by stanza",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the line or stanza the page follows (nextCursor of the previous page)",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the line or stanza the page precedes (prevCursor of the next page)",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lyrics retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input parameters",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/refresh": {
+            "post": {
+                "description": "This endpoint refreshes every song matched by the listing filters or by the id parameters, at most 100 at once, as /songs/{song_id}/refresh does. A song that could not be looked up is reported with its error instead of failing the whole batch.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Refresh songs from the metadata provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Refresh mode (preview, fill or apply, default preview)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song IDs, comma-separated or repeated",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Match title and group despite typos, by trigram similarity",
+                        "name": "fuzzy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by lyrics containing all of the words, in any of their forms",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the song has lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album title",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by album ID",
+                        "name": "albumId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by any of the comma-separated tag names",
+                        "name": "anyTag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by all of the comma-separated tag names",
+                        "name": "allTags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (must be provided with limit)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of items per page (the first page without page)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Songs refreshed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters or too many songs matched",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}": {
+            "get": {
+                "description": "This endpoint retrieves a song's details by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Get a song by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID to retrieve",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - song not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a song by its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Delete a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID to delete",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - song not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/enrichment": {
+            "get": {
+                "description": "This endpoint returns the job filling the song in with the details of the metadata provider: its status (pending, running, done or failed), the number of attempts made, when it is due and the last error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Get the enrichment job of a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enrichment job retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - the song has no enrichment job",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/enrichment/requeue": {
+            "post": {
+                "description": "This endpoint puts the failed enrichment job of the song back in the queue with its attempts reset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Requeue the failed enrichment of a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enrichment requeued successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - the song has no enrichment job or it has not failed",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/lyrics/stanzas/{stanza_number}": {
+            "put": {
+                "description": "This endpoint sets the label of a stanza, such as Verse 1, Chorus or Bridge, written as [Label] above the stanza in the lyrics text. A null or empty label removes it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Label a stanza of the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Stanza number",
+                        "name": "stanza_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New label of the stanza",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.stanzaInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stanza updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid label, song or stanza not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/lyrics/verses": {
+            "post": {
+                "description": "This endpoint appends a verse to the song's lyrics, or inserts it at the given 1-based verse number renumbering the following verses. A verse is a single line and may be empty. It joins the stanza of the verse it takes the place of, or the last stanza when appended.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Insert a verse into the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Verse and the number it takes (appended when omitted)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.verseInsertInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verse inserted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid verse or position, song not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/lyrics/verses/{verse_number}": {
+            "get": {
+                "description": "This endpoint retrieves a single verse of the song's lyrics by its 1-based number.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Get a verse of the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verse number",
+                        "name": "verse_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verse retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid verse number, song or verse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint replaces the text of a single verse of the song's lyrics. A verse is a single line and may be empty.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Replace a verse of the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verse number",
+                        "name": "verse_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New text of the verse",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.verseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verse updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid verse, song or verse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a verse of the song's lyrics, renumbering the following verses. A stanza left without verses is deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Delete a verse of the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verse number",
+                        "name": "verse_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verse deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid verse number, song or verse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/lyrics/verses/{verse_number}/position": {
+            "put": {
+                "description": "This endpoint moves a verse to the given 1-based verse number, renumbering the verses in between. The verse joins the stanza of the verse it takes the place of, and a stanza left without verses is deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Move a verse of the lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verse number",
+                        "name": "verse_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New verse number",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.verseMoveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verse moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid position, song or verse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/refresh": {
+            "post": {
+                "description": "This endpoint looks the song up again in the metadata provider and returns a field-level diff of its release date, link and lyrics against the stored values. With mode=preview (default) nothing is stored, fill stores only the fields the song lacks, and apply stores every difference, replacing the lyrics as the song update does.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Refresh a song from the metadata provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh mode (preview, fill or apply, default preview)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song refreshed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid mode, song not found or no metadata was found for it",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Metadata source returned an invalid response",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Metadata source unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Metadata source timed out",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{song_id}/tags/{tag_id}": {
+            "put": {
+                "description": "This endpoint attaches a tag to a song. Attaching an already attached tag is a no-op.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Attach a tag to a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag attached successfully",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - song or tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint detaches a tag from a song.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,68 +2602,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "tags"
                 ],
-                "summary": "Get songs by filter",
+                "summary": "Detach a tag from a song",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by title",
-                        "name": "title",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by group name",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by link",
-                        "name": "link",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by text (contains)",
-                        "name": "text",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by start date (YYYY-MM-DD)",
-                        "name": "startDate",
-                        "in": "query"
+                        "description": "Song ID",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Filter by end date (YYYY-MM-DD)",
-                        "name": "endDate",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination (must be provided with limit)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit of items per page (must be provided with page)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of songs retrieved successfully",
+                        "description": "Tag detached successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid filter parameters",
+                        "description": "Bad request - tag is not attached to the song",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -98,9 +2641,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "This endpoint updates a song's details. The song ID must be provided in the request body.",
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "This endpoint retrieves tags with their usage counts, most used first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -108,33 +2653,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "tags"
                 ],
-                "summary": "Update a song",
+                "summary": "Get tags",
                 "parameters": [
                     {
-                        "description": "Song update input",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.SongUpdate"
-                        }
+                        "type": "string",
+                        "description": "Filter by category (genre, mood or tag)",
+                        "name": "category",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song updated successfully",
+                        "description": "List of tags retrieved successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request - invalid input or song not found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -144,7 +2680,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "This endpoint creates a new song by specifying the group and title.",
+                "description": "This endpoint creates a new tag. The category is one of genre, mood or tag (free-form, the default). Tag names are case-insensitive.",
                 "consumes": [
                     "application/json"
                 ],
@@ -152,23 +2688,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "tags"
                 ],
-                "summary": "Creates a new song",
+                "summary": "Creates a new tag",
                 "parameters": [
                     {
-                        "description": "Song creation input",
+                        "description": "Tag creation input",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.songCreateInput"
+                            "$ref": "#/definitions/v1.tagCreateInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song created successfully",
+                        "description": "Tag created successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
@@ -188,9 +2724,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/lyrics/{song_id}": {
+        "/tags/{tag_id}": {
             "get": {
-                "description": "This endpoint retrieves paginated lyrics for a specific song by its ID.",
+                "description": "This endpoint retrieves a tag with its usage count by its ID.",
                 "consumes": [
                     "application/json"
                 ],
@@ -198,41 +2734,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lyrics"
+                    "tags"
                 ],
-                "summary": "Get paginated lyrics",
+                "summary": "Get a tag by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song ID",
-                        "name": "song_id",
+                        "description": "Tag ID to retrieve",
+                        "name": "tag_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (must be provided with limit)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit of items per page (must be provided with page)",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lyrics retrieved successfully",
+                        "description": "Tag retrieved successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid input parameters",
+                        "description": "Bad request - tag not found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -244,11 +2766,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/songs/{song_id}": {
-            "get": {
-                "description": "This endpoint retrieves a song's details by its ID.",
+            },
+            "put": {
+                "description": "This endpoint renames a tag or changes its category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -256,27 +2776,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "tags"
                 ],
-                "summary": "Get a song by ID",
+                "summary": "Update a tag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song ID to retrieve",
-                        "name": "song_id",
+                        "description": "Tag ID to update",
+                        "name": "tag_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Tag update input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.TagUpdate"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song retrieved successfully",
+                        "description": "Tag updated successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request - song not found",
+                        "description": "Bad request - invalid input, tag not found or name already taken",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -290,7 +2819,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "This endpoint deletes a song by its ID.",
+                "description": "This endpoint deletes a tag by its ID and detaches it from all songs.",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,27 +2827,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "tags"
                 ],
-                "summary": "Delete a song",
+                "summary": "Delete a tag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song ID to delete",
-                        "name": "song_id",
+                        "description": "Tag ID to delete",
+                        "name": "tag_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song deleted successfully",
+                        "description": "Tag deleted successfully",
                         "schema": {
                             "$ref": "#/definitions/v1.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request - song not found",
+                        "description": "Bad request - tag not found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -334,9 +2863,40 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "entity.AlbumUpdate": {
+            "type": "object",
+            "properties": {
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.PlaylistUpdate": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "entity.SongUpdate": {
             "type": "object",
             "properties": {
+                "albumId": {
+                    "type": "string"
+                },
+                "discNumber": {
+                    "type": "integer"
+                },
                 "groupID": {
                     "type": "string"
                 },
@@ -357,6 +2917,20 @@ const docTemplate = `{
                 },
                 "title": {
                     "type": "string"
+                },
+                "trackNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.TagUpdate": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
@@ -377,6 +2951,88 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.albumCreateInput": {
+            "type": "object",
+            "required": [
+                "group",
+                "title"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "album",
+                        "single",
+                        "ep",
+                        "compilation"
+                    ]
+                }
+            }
+        },
+        "v1.groupRenameInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.playlistCreateInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.playlistEntryInput": {
+            "type": "object",
+            "required": [
+                "songId"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "songId": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.playlistEntryMoveInput": {
+            "type": "object",
+            "required": [
+                "position"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
         "v1.songCreateInput": {
             "type": "object",
             "required": [
@@ -391,6 +3047,79 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "v1.songExportInput": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "v1.stanzaInput": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.tagCreateInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "genre",
+                        "mood",
+                        "tag"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.verseInput": {
+            "type": "object",
+            "properties": {
+                "verse": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.verseInsertInput": {
+            "type": "object",
+            "properties": {
+                "verse": {
+                    "type": "string"
+                },
+                "verseNumber": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "v1.verseMoveInput": {
+            "type": "object",
+            "required": [
+                "position"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
         }
     }
 }`
@@ -4,11 +4,8 @@ import (
 	"context"
 	"effective_mobile_tz/config"
 	v1 "effective_mobile_tz/internal/controller/http/v1"
-	"effective_mobile_tz/internal/repository"
 	"effective_mobile_tz/internal/service"
 	"effective_mobile_tz/pkg/validator"
-	"fmt"
-	"github.com/jackc/pgx/v4"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"net/http"
@@ -35,33 +32,65 @@ func Run(configPath string) {
 	// Logger
 	SetupLogrus(cfg.Log.Level)
 
-	// Connecting to Postgres
-	log.Info("Connecting postgres...")
-	pg, err := pgx.Connect(ctx, cfg.PG.URL)
+	// Repositories
+	log.Info("Initializing repositories...")
+	repositories, closeStorage, err := NewRepository(ctx, cfg)
 	if err != nil {
-		log.Fatal(fmt.Errorf("error connecting postgres: %w", err))
+		log.Fatal(err)
 	}
-	defer pg.Close(ctx)
+	defer closeStorage()
 
-	// Running Migrations
-	log.Info("Running migrations...")
-	err = RunMigrations(cfg.PG.URL, cfg.PG.MigrationPath)
+	// Metadata
+	log.Info("Initializing metadata providers...")
+	metadataProvider, err := NewMetadataProvider(cfg)
 	if err != nil {
-		log.Debug(fmt.Errorf("error running migrations: %w", err))
+		log.Fatal(err)
 	}
 
-	// Repositories
-	log.Info("Initializing repositories...")
-	repositories := repository.NewRepository(pg)
-
 	// Service
 	log.Info("Initializing services")
 	dependencies := service.Dependencies{
-		Repository:     repositories,
-		ExternalApiURL: cfg.ExternalAPI.URL,
+		Repository: repositories,
+		Metadata:   metadataProvider,
+		MetadataCache: service.MetadataCacheConfig{
+			Enabled:     !cfg.MetadataCache.Disabled,
+			Size:        cfg.MetadataCache.Size,
+			TTL:         cfg.MetadataCache.TTL,
+			NegativeTTL: cfg.MetadataCache.NegativeTTL,
+			Persistent:  cfg.MetadataCache.Persistent,
+		},
+		Enrichment: service.EnrichmentConfig{
+			Workers:         cfg.Enrichment.Workers,
+			PollInterval:    cfg.Enrichment.PollInterval,
+			Lease:           cfg.Enrichment.Lease,
+			MaxAttempts:     cfg.Enrichment.MaxAttempts,
+			RetryBackoff:    cfg.Enrichment.RetryBackoff,
+			RetryMaxBackoff: cfg.Enrichment.RetryMaxBackoff,
+		},
+		FuzzySearch: service.FuzzySearchConfig{
+			SimilarityThreshold: cfg.FuzzySearch.SimilarityThreshold,
+			SuggestionLimit:     cfg.FuzzySearch.SuggestionLimit,
+		},
+		CursorSecret: cfg.Pagination.CursorSecret,
+	}
+	if cfg.Pagination.CursorSecret == "" {
+		log.Warn("No pagination cursor secret configured, cursors will be invalid after a restart")
 	}
 	services := service.NewService(dependencies)
 
+	// Search
+	reindexed, err := services.SetSearchLanguage(ctx, cfg.Search.Language)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if reindexed {
+		log.Infof("Lyrics reindexed for the %s search language", cfg.Search.Language)
+	}
+
+	// Enrichment workers
+	log.Infof("Starting %d enrichment workers...", cfg.Enrichment.Workers)
+	services.StartWorkers()
+
 	// Handler
 	log.Info("Initializing handlers and routes...")
 	handler := echo.New()
@@ -94,8 +123,14 @@ func Run(configPath string) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 		defer cancel()
 
+		// the workers are drained even when requests outlast the timeout
 		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Fatalf("error shutdown: %v", err)
+			log.Errorf("error shutdown: %v", err)
+		}
+
+		log.Info("Draining enrichment workers...")
+		if err := services.StopWorkers(ctx); err != nil {
+			log.Error(err)
 		}
 
 		close(done)
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"effective_mobile_tz/config"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"encoding/json"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+// Import runs the import subcommand: it bulk imports songs from a file and
+// prints the import report as JSON.
+func Import(configPath string, args []string) {
+	ctx := context.Background()
+
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "input format: csv, json or ndjson (detected from the file extension by default)")
+	enrich := flags.String("enrich", entity.ImportEnrichAuto, "enrichment mode: auto, always or never")
+	dryRun := flags.Bool("dry-run", false, "report the outcome without storing anything")
+	batchSize := flags.Int("batch-size", 100, "number of rows committed together")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [flags] <file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	filePath := flags.Arg(0)
+
+	// Configurations set up
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	// Logger
+	SetupLogrus(cfg.Log.Level)
+	log.SetOutput(os.Stderr)
+
+	if *format == "" {
+		*format = service.DetectImportFormat(filePath, "")
+	}
+	if *format == "" {
+		log.Fatal("unable to detect the format, provide the -format flag")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Fatal(fmt.Errorf("error opening import file: %w", err))
+	}
+	defer file.Close()
+
+	repositories, closeStorage, err := NewRepository(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStorage()
+
+	metadataProvider, err := NewMetadataProvider(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Service
+	services := service.NewService(service.Dependencies{
+		Repository: repositories,
+		Metadata:   metadataProvider,
+	})
+
+	log.Infof("Importing %s...", filePath)
+	report, err := services.ImportSongs(ctx, file, entity.SongImportOptions{
+		Format:    *format,
+		Enrich:    *enrich,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+	if err != nil {
+		log.Fatal(fmt.Errorf("error importing songs: %w", err))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(fmt.Errorf("error writing import report: %w", err))
+	}
+
+	log.Infof("Import finished: %d created, %d duplicates, %d failed", report.Created, report.Duplicates, report.Failed)
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"effective_mobile_tz/config"
+	"effective_mobile_tz/internal/metadata"
+	"effective_mobile_tz/internal/service"
+	"effective_mobile_tz/internal/webapi"
+)
+
+// NewMetadataProvider creates the metadata providers listed in the
+// configuration, chained in the configured order.
+func NewMetadataProvider(cfg *config.Config) (service.MetadataProvider, error) {
+	registry := metadata.NewRegistry()
+	registry.Register(metadata.ProviderAPI, func() (metadata.Provider, error) {
+		return metadata.NewAPIProvider(NewSongInfo(cfg.ExternalAPI)), nil
+	})
+	registry.Register(metadata.ProviderFixture, func() (metadata.Provider, error) {
+		return metadata.NewFixtureProvider(cfg.Metadata.FixtureDir)
+	})
+	registry.Register(metadata.ProviderNone, func() (metadata.Provider, error) {
+		return metadata.NewNoneProvider(), nil
+	})
+
+	return registry.Build(cfg.Metadata.Providers)
+}
+
+// NewSongInfo creates the client of the external song info API.
+func NewSongInfo(cfg config.ExternalAPI) webapi.SongInfo {
+	return webapi.NewSongInfoClient(webapi.Config{
+		URL:              cfg.URL,
+		Timeout:          cfg.Timeout,
+		MaxRetries:       cfg.MaxRetries,
+		RetryBackoff:     cfg.RetryBackoff,
+		RetryMaxBackoff:  cfg.RetryMaxBackoff,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	})
+}
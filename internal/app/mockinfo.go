@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"effective_mobile_tz/internal/metadata"
+	"effective_mobile_tz/internal/mockinfo"
+	"errors"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// MockInfo runs the mockinfo subcommand: a local stand-in for the external
+// song info API serving songs from fixture files.
+func MockInfo(args []string) {
+	flags := flag.NewFlagSet("mockinfo", flag.ExitOnError)
+	addr := flags.String("addr", ":8081", "address to listen on")
+	fixtures := flags.String("fixtures", "./fixtures", "fixture file, or directory of .json and .yaml fixture files")
+	latency := flags.Duration("latency", 0, "delay of every response")
+	jitter := flags.Duration("jitter", 0, "random delay added to the latency, up to this much")
+	errorRate := flags.Float64("error-rate", 0, "share of requests answered with an error, between 0 and 1")
+	errorStatus := flags.Int("error-status", http.StatusInternalServerError, "status of the injected errors")
+	verbose := flags.Bool("v", false, "log every request")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s mockinfo [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *errorRate < 0 || *errorRate > 1 {
+		log.Fatal("error-rate must be between 0 and 1")
+	}
+
+	level := "info"
+	if *verbose {
+		level = "debug"
+	}
+	SetupLogrus(level)
+
+	songs, err := metadata.NewFixtureProvider(*fixtures)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: mockinfo.NewHandler(songs, mockinfo.Config{
+			Latency:     *latency,
+			Jitter:      *jitter,
+			ErrorRate:   *errorRate,
+			ErrorStatus: *errorStatus,
+		}),
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	log.Infof("Mock song info API listening on %s, serving %s", *addr, *fixtures)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"effective_mobile_tz/config"
+	"effective_mobile_tz/internal/repository"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
+)
+
+// NewRepository sets up the storage picked by the configuration: it connects
+// postgres and runs the migrations, or creates an empty in-memory store. The
+// returned function releases the storage.
+func NewRepository(ctx context.Context, cfg *config.Config) (*repository.Repository, func(), error) {
+	switch cfg.Storage.Driver {
+	case StorageDriverMemory:
+		log.Info("Using in-memory storage, data will be lost on exit")
+		return repository.NewMemoryRepository(), func() {}, nil
+
+	case StorageDriverPostgres, "":
+		// Connecting to Postgres
+		log.Info("Connecting postgres...")
+		pg, err := ConnectPostgres(ctx, cfg.PG)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error connecting postgres: %w", err)
+		}
+
+		// Running Migrations
+		log.Info("Running migrations...")
+		err = RunMigrations(cfg.PG.URL, cfg.PG.MigrationPath)
+		if err != nil {
+			log.Debug(fmt.Errorf("error running migrations: %w", err))
+		}
+
+		return repository.NewRepository(pg), pg.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
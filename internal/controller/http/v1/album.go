@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type albumRoutes struct {
+	albumService service.Album
+}
+
+func newAlbumRoutes(g *echo.Group, albumService service.Album) {
+	r := &albumRoutes{
+		albumService: albumService,
+	}
+
+	g.POST("", r.create)
+	g.GET("", r.getAlbums)
+	g.GET("/:album_id", r.getByID)
+	g.PUT("/:album_id", r.update)
+	g.DELETE("/:album_id", r.delete)
+}
+
+type albumCreateInput struct {
+	Group       string `json:"group" validate:"required"`
+	Title       string `json:"title" validate:"required"`
+	ReleaseDate string `json:"releaseDate"`
+	Type        string `json:"type" validate:"omitempty,oneof=album single ep compilation"`
+}
+
+// @Summary Creates a new album
+// @Description This endpoint creates a new album (or single) of the group. The release date is in YYYY-MM-DD format.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param input body albumCreateInput true "Album creation input"
+// @Success 200 {object} SuccessResponse "Album created successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /albums [post]
+func (r *albumRoutes) create(c echo.Context) error {
+	var input albumCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	album := entity.Album{
+		Title:     input.Title,
+		GroupName: input.Group,
+		Type:      input.Type,
+	}
+	if album.Type == "" {
+		album.Type = "album"
+	}
+	if input.ReleaseDate != "" {
+		releaseDate, err := time.Parse("2006-01-02", input.ReleaseDate)
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid releaseDate"))
+		}
+		album.ReleaseDate = &releaseDate
+	}
+
+	id, err := r.albumService.CreateAlbum(c.Request().Context(), &album)
+	if err != nil {
+		if errors.Is(err, service.ErrAlbumAlreadyExists) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		ID string
+	}{
+		ID: id,
+	}
+
+	return newSuccessResponse(c, "album created", responseContent)
+}
+
+// @Summary Get albums
+// @Description This endpoint retrieves albums, optionally filtered by group ID and title.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param groupId query string false "Filter by group ID"
+// @Param title query string false "Filter by title"
+// @Success 200 {object} SuccessResponse "List of albums retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /albums [get]
+func (r *albumRoutes) getAlbums(c echo.Context) error {
+	filter := entity.AlbumFilter{
+		GroupID: c.QueryParams().Get("groupId"),
+		Title:   c.QueryParams().Get("title"),
+	}
+
+	albums, err := r.albumService.GetAlbums(c.Request().Context(), &filter)
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "albums retrieved", albums)
+}
+
+// @Summary Get an album by ID
+// @Description This endpoint retrieves an album with its tracklist by its ID.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param album_id path string true "Album ID to retrieve"
+// @Success 200 {object} SuccessResponse "Album retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - album not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /albums/{album_id} [get]
+func (r *albumRoutes) getByID(c echo.Context) error {
+	albumID := c.Param("album_id")
+
+	album, err := r.albumService.GetAlbumByID(c.Request().Context(), albumID)
+	if err != nil {
+		if errors.Is(err, service.ErrAlbumNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "album retrieved", album)
+}
+
+// @Summary Update an album
+// @Description This endpoint updates an album's details. An empty release date clears it.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param album_id path string true "Album ID to update"
+// @Param input body entity.AlbumUpdate true "Album update input"
+// @Success 200 {object} SuccessResponse "Album updated successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input or album not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /albums/{album_id} [put]
+func (r *albumRoutes) update(c echo.Context) error {
+	var input entity.AlbumUpdate
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid request body"))
+	}
+	input.ID = c.Param("album_id")
+
+	if input.ReleaseDate != nil && *input.ReleaseDate != "" {
+		if _, err := time.Parse("2006-01-02", *input.ReleaseDate); err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid releaseDate"))
+		}
+	}
+	if input.Type != nil {
+		switch *input.Type {
+		case "album", "single", "ep", "compilation":
+		default:
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid type"))
+		}
+	}
+
+	err := r.albumService.UpdateAlbum(c.Request().Context(), &input)
+	if err != nil {
+		if errors.Is(err, service.ErrAlbumNotFound) || errors.Is(err, service.ErrAlbumAlreadyExists) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "album updated", nil)
+}
+
+// @Summary Delete an album
+// @Description This endpoint deletes an album by its ID. Its songs are kept and detached from the album.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param album_id path string true "Album ID to delete"
+// @Success 200 {object} SuccessResponse "Album deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - album not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /albums/{album_id} [delete]
+func (r *albumRoutes) delete(c echo.Context) error {
+	albumID := c.Param("album_id")
+
+	err := r.albumService.DeleteAlbum(c.Request().Context(), albumID)
+	if err != nil {
+		if errors.Is(err, service.ErrAlbumNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "album deleted", nil)
+}
@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type enrichmentRoutes struct {
+	enrichmentService service.Enrichment
+}
+
+func newEnrichmentRoutes(songs, enrichment *echo.Group, enrichmentService service.Enrichment) {
+	r := &enrichmentRoutes{
+		enrichmentService: enrichmentService,
+	}
+
+	songs.GET("/:song_id/enrichment", r.getJob)
+	songs.POST("/:song_id/enrichment/requeue", r.requeueJob)
+	enrichment.GET("/jobs", r.getJobs)
+	enrichment.POST("/requeue", r.requeueFailedJobs)
+}
+
+// @Summary Get the enrichment job of a song
+// @Description This endpoint returns the job filling the song in with the details of the metadata provider: its status (pending, running, done or failed), the number of attempts made, when it is due and the last error.
+// @Tags enrichment
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Success 200 {object} SuccessResponse "Enrichment job retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - the song has no enrichment job"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/enrichment [get]
+func (r *enrichmentRoutes) getJob(c echo.Context) error {
+	job, err := r.enrichmentService.GetEnrichmentJob(c.Request().Context(), c.Param("song_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrEnrichmentJobNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "enrichment job retrieved", job)
+}
+
+// @Summary Requeue the failed enrichment of a song
+// @Description This endpoint puts the failed enrichment job of the song back in the queue with its attempts reset.
+// @Tags enrichment
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Success 200 {object} SuccessResponse "Enrichment requeued successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - the song has no enrichment job or it has not failed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/enrichment/requeue [post]
+func (r *enrichmentRoutes) requeueJob(c echo.Context) error {
+	err := r.enrichmentService.RequeueEnrichment(c.Request().Context(), c.Param("song_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrEnrichmentJobNotFound) || errors.Is(err, service.ErrEnrichmentNotFailed) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "enrichment requeued", nil)
+}
+
+// @Summary List enrichment jobs
+// @Description This endpoint lists the enrichment jobs, most recently updated first.
+// @Tags enrichment
+// @Produce json
+// @Param status query string false "Filter by status (pending, running, done or failed)"
+// @Param page query int false "Page number for pagination (must be provided with limit)"
+// @Param limit query int false "Limit of items per page (must be provided with page)"
+// @Success 200 {object} SuccessResponse "Enrichment jobs retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /enrichment/jobs [get]
+func (r *enrichmentRoutes) getJobs(c echo.Context) error {
+	params := c.QueryParams()
+	filter := &entity.EnrichmentJobFilter{Status: params.Get("status")}
+
+	switch filter.Status {
+	case "", entity.EnrichmentStatusPending, entity.EnrichmentStatusRunning, entity.EnrichmentStatusDone, entity.EnrichmentStatusFailed:
+	default:
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid status, must be pending, running, done or failed"))
+	}
+
+	page := params.Get("page")
+	limit := params.Get("limit")
+	if (page == "" && limit != "") || (page != "" && limit == "") {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both page and limit should be provided, or neither of them"))
+	} else if page != "" && limit != "" {
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid page number"))
+		}
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid limit number"))
+		}
+		filter.Offset = (pageInt - 1) * filter.Limit
+	}
+
+	jobs, err := r.enrichmentService.GetEnrichmentJobs(c.Request().Context(), filter)
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "enrichment jobs retrieved", jobs)
+}
+
+// @Summary Requeue every failed enrichment
+// @Description This endpoint puts every failed enrichment job back in the queue with its attempts reset and returns how many were requeued.
+// @Tags enrichment
+// @Produce json
+// @Success 200 {object} SuccessResponse "Failed enrichments requeued successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /enrichment/requeue [post]
+func (r *enrichmentRoutes) requeueFailedJobs(c echo.Context) error {
+	requeued, err := r.enrichmentService.RequeueFailedEnrichments(c.Request().Context())
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		Requeued int
+	}{
+		Requeued: requeued,
+	}
+
+	return newSuccessResponse(c, "failed enrichments requeued", responseContent)
+}
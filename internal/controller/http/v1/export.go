@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/pkg/playlistfmt"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const exportPlaylistTitle = "Song Library"
+
+type songExportInput struct {
+	IDs    []string `json:"ids"`
+	Format string   `json:"format"`
+}
+
+// @Summary Export songs as a playlist
+// @Description This endpoint exports songs as an M3U8, XSPF or PLS playlist. Songs are selected either by the song listing filters or by an explicit list of IDs (repeated id query parameter or ids in the JSON body of a POST request), in which case their order is kept. The format is taken from the format parameter, otherwise from the Accept header, and defaults to M3U8. Songs without a link are skipped.
+// @Tags songs
+// @Accept json
+// @Produce application/vnd.apple.mpegurl
+// @Produce application/xspf+xml
+// @Produce audio/x-scpls
+// @Param format query string false "Playlist format (m3u8, xspf or pls)"
+// @Param id query []string false "Song IDs to export" collectionFormat(multi)
+// @Param title query string false "Filter by title"
+// @Param group query string false "Filter by group name"
+// @Param fuzzy query bool false "Match title and group despite typos, by trigram similarity"
+// @Param link query string false "Filter by link"
+// @Param text query string false "Filter by lyrics containing all of the words, in any of their forms"
+// @Param hasLyrics query bool false "Filter by whether the song has lyrics"
+// @Param album query string false "Filter by album title"
+// @Param albumId query string false "Filter by album ID"
+// @Param tag query string false "Filter by tag name"
+// @Param anyTag query string false "Filter by any of the comma-separated tag names"
+// @Param allTags query string false "Filter by all of the comma-separated tag names"
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)"
+// @Param sort query string false "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)"
+// @Param page query int false "Page number for pagination (must be provided with limit)"
+// @Param limit query int false "Limit of items per page (the first page without page)"
+// @Param input body songExportInput false "Explicit list of song IDs (POST only)"
+// @Success 200 {string} string "Playlist file"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid filter parameters or format"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/export [get]
+// @Router /songs/export [post]
+func (r *songRoutes) export(c echo.Context) error {
+	var input songExportInput
+	if c.Request().Method == http.MethodPost && c.Request().ContentLength != 0 {
+		if err := (&echo.DefaultBinder{}).BindBody(c, &input); err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		}
+	}
+
+	format, err := negotiateExportFormat(c, input.Format)
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	filter, err := parseSongFilter(c.QueryParams())
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter.IDs = append(splitQueryList(c.QueryParams()["id"]), input.IDs...)
+	// playlists hold neither lyrics nor tags
+	filter.Include = []string{}
+
+	songs, err := r.songService.GetSongsByFilter(c.Request().Context(), filter)
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	if len(filter.IDs) > 0 {
+		songs = orderSongsByIDs(songs, filter.IDs)
+	}
+
+	tracks := make([]playlistfmt.Track, 0, len(songs))
+	for _, song := range songs {
+		track := playlistfmt.Track{
+			Title:    song.Title,
+			Creator:  song.GroupName,
+			Location: song.Link,
+		}
+		if song.AlbumTitle != nil {
+			track.Album = *song.AlbumTitle
+		}
+		tracks = append(tracks, track)
+	}
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, format.ContentType())
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="songs.%s"`, format.Extension()))
+	response.WriteHeader(http.StatusOK)
+
+	return playlistfmt.Encode(response, format, exportPlaylistTitle, tracks)
+}
+
+// negotiateExportFormat prefers an explicit format over the Accept header.
+func negotiateExportFormat(c echo.Context, bodyFormat string) (playlistfmt.Format, error) {
+	name := c.QueryParams().Get("format")
+	if name == "" {
+		name = bodyFormat
+	}
+	if name != "" {
+		return playlistfmt.ParseFormat(name)
+	}
+
+	if format, ok := playlistfmt.Negotiate(c.Request().Header.Get(echo.HeaderAccept)); ok {
+		return format, nil
+	}
+
+	return playlistfmt.M3U8, nil
+}
+
+// orderSongsByIDs returns the songs in the order their IDs were requested.
+func orderSongsByIDs(songs []entity.Song, ids []string) []entity.Song {
+	byID := make(map[string]entity.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+
+	ordered := make([]entity.Song, 0, len(songs))
+	for _, id := range ids {
+		if song, ok := byID[id]; ok {
+			ordered = append(ordered, song)
+			delete(byID, id)
+		}
+	}
+
+	return ordered
+}
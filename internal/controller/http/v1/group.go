@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type groupRoutes struct {
+	groupService service.Group
+}
+
+func newGroupRoutes(g *echo.Group, groupService service.Group) {
+	r := &groupRoutes{
+		groupService: groupService,
+	}
+
+	g.GET("", r.getGroups)
+	g.GET("/:group_id", r.getByID)
+	g.PUT("/:group_id", r.rename)
+	g.DELETE("/:group_id", r.delete)
+}
+
+// @Summary Get groups
+// @Description This endpoint retrieves all groups together with the number of songs each of them has.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse "List of groups retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups [get]
+func (r *groupRoutes) getGroups(c echo.Context) error {
+	groups, err := r.groupService.GetGroups(c.Request().Context())
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "groups retrieved", groups)
+}
+
+// @Summary Get a group by ID
+// @Description This endpoint retrieves a group with its discography by its ID.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "Group ID to retrieve"
+// @Success 200 {object} SuccessResponse "Group retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{group_id} [get]
+func (r *groupRoutes) getByID(c echo.Context) error {
+	groupID := c.Param("group_id")
+
+	group, err := r.groupService.GetGroupByID(c.Request().Context(), groupID)
+	if err != nil {
+		if errors.Is(err, service.ErrGroupNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "group retrieved", group)
+}
+
+type groupRenameInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// @Summary Rename a group
+// @Description This endpoint renames a group by its ID.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "Group ID to rename"
+// @Param input body groupRenameInput true "Group rename input"
+// @Success 200 {object} SuccessResponse "Group renamed successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input, group not found or name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{group_id} [put]
+func (r *groupRoutes) rename(c echo.Context) error {
+	groupID := c.Param("group_id")
+
+	var input groupRenameInput
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	err := r.groupService.RenameGroup(c.Request().Context(), groupID, input.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrGroupNotFound) || errors.Is(err, service.ErrGroupAlreadyExists) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "group renamed", nil)
+}
+
+// @Summary Delete a group
+// @Description This endpoint deletes a group by its ID. If the group still has songs, the request is refused unless cascade is set, in which case the songs are deleted as well.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "Group ID to delete"
+// @Param cascade query bool false "Delete the songs of the group as well"
+// @Success 200 {object} SuccessResponse "Group deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - group not found or group still has songs"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{group_id} [delete]
+func (r *groupRoutes) delete(c echo.Context) error {
+	groupID := c.Param("group_id")
+
+	cascade := false
+	if cascadeStr := c.QueryParams().Get("cascade"); cascadeStr != "" {
+		var err error
+		cascade, err = strconv.ParseBool(cascadeStr)
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid cascade value"))
+		}
+	}
+
+	err := r.groupService.DeleteGroup(c.Request().Context(), groupID, cascade)
+	if err != nil {
+		if errors.Is(err, service.ErrGroupNotFound) || errors.Is(err, service.ErrGroupHasSongs) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "group deleted", nil)
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type importRoutes struct {
+	importService service.Import
+}
+
+func newImportRoutes(g *echo.Group, importService service.Import) {
+	r := &importRoutes{
+		importService: importService,
+	}
+
+	g.POST("/import", r.importSongs)
+}
+
+// @Summary Bulk import songs
+// @Description This endpoint creates songs out of a CSV (with a header row), JSON array or NDJSON file of group, title and optionally releaseDate (DD.MM.YYYY, YYYY-MM-DD, YYYY-MM or YYYY), link and lyrics. The file is sent either as the raw request body or as the "file" field of a multipart form. The format is taken from the format parameter, the file extension or the content type. With enrich=auto (default) only rows that supply none of releaseDate, link and lyrics are completed from the external API; always enriches every row and never skips enrichment. Rows are committed in batches, and the report lists every row as created, duplicate or failed. A dry run reports the outcome without storing anything or calling the external API.
+// @Tags songs
+// @Accept text/csv
+// @Accept json
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "Input format (csv, json or ndjson)"
+// @Param enrich query string false "Enrichment mode (auto, always or never)"
+// @Param dryRun query bool false "Report the outcome without storing anything"
+// @Param batchSize query int false "Number of rows committed together (default 100)"
+// @Param file formData file false "File to import"
+// @Success 200 {object} SuccessResponse "Import report"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters or undecodable file"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/import [post]
+func (r *importRoutes) importSongs(c echo.Context) error {
+	params := c.QueryParams()
+
+	opts := entity.SongImportOptions{
+		Format: params.Get("format"),
+		Enrich: params.Get("enrich"),
+	}
+
+	if dryRun := params.Get("dryRun"); dryRun != "" {
+		var err error
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid dryRun value"))
+		}
+	}
+
+	if batchSize := params.Get("batchSize"); batchSize != "" {
+		var err error
+		opts.BatchSize, err = strconv.Atoi(batchSize)
+		if err != nil || opts.BatchSize < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid batchSize number"))
+		}
+	}
+
+	var body io.Reader = c.Request().Body
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("file not provided"))
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("failed to open the file"))
+		}
+		defer file.Close()
+
+		body = file
+		if opts.Format == "" {
+			opts.Format = service.DetectImportFormat(fileHeader.Filename, fileHeader.Header.Get(echo.HeaderContentType))
+		}
+	}
+
+	if opts.Format == "" {
+		opts.Format = service.DetectImportFormat("", contentType)
+	}
+	if opts.Format == "" {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("unable to detect the format, provide the format parameter"))
+	}
+
+	report, err := r.importService.ImportSongs(c.Request().Context(), body, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImport) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "songs imported", report)
+}
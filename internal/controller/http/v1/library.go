@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type libraryRoutes struct {
+	backupService service.Backup
+}
+
+func newLibraryRoutes(g *echo.Group, backupService service.Backup) {
+	r := &libraryRoutes{
+		backupService: backupService,
+	}
+
+	g.GET("/export", r.exportLibrary)
+	g.POST("/restore", r.restoreLibrary)
+}
+
+// @Summary Export the whole library
+// @Description This endpoint streams every group, album, tag, song (with its tag assignments) and lyrics verse as a versioned archive, read within a single transaction. The json format is one object with the version, the export time and an array per record type; ndjson writes a header record followed by one {"type","data"} record per line. With gzip=true the archive is gzip-compressed.
+// @Tags library
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce application/gzip
+// @Param format query string false "Archive format (json or ndjson, default json)"
+// @Param gzip query bool false "Compress the archive with gzip"
+// @Success 200 {string} string "Library archive"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /library/export [get]
+func (r *libraryRoutes) exportLibrary(c echo.Context) error {
+	params := c.QueryParams()
+
+	format := params.Get("format")
+	if format == "" {
+		format = service.ArchiveFormatJSON
+	}
+	if format != service.ArchiveFormatJSON && format != service.ArchiveFormatNDJSON {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid format, must be json or ndjson"))
+	}
+
+	var compress bool
+	if value := params.Get("gzip"); value != "" {
+		var err error
+		compress, err = strconv.ParseBool(value)
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid gzip value"))
+		}
+	}
+
+	contentType := echo.MIMEApplicationJSON
+	if format == service.ArchiveFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	filename := fmt.Sprintf("library-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if compress {
+		contentType = "application/gzip"
+		filename += ".gz"
+	}
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, contentType)
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	response.WriteHeader(http.StatusOK)
+
+	// the status is already sent, a failure can only cut the archive short
+	return r.backupService.ExportLibrary(c.Request().Context(), response, format, compress)
+}
+
+// @Summary Restore the library from an archive
+// @Description This endpoint restores a json or ndjson archive produced by the export, gzip-compressed or not, sent either as the raw request body or as the "file" field of a multipart form. Records keep their IDs and the whole restore runs in a single transaction. On a clash with an existing record (same ID or same name) the skip policy keeps the existing record and attaches the archived records referring to it; overwrite replaces records with the same ID, or the existing record with the same name in place of an archived record with another ID; fail (default) aborts the restore. The report lists how many records of each type were restored and skipped.
+// @Tags library
+// @Accept json
+// @Accept application/x-ndjson
+// @Accept application/gzip
+// @Accept multipart/form-data
+// @Produce json
+// @Param policy query string false "Conflict policy (skip, overwrite or fail, default fail)"
+// @Param file formData file false "Archive to restore"
+// @Success 200 {object} SuccessResponse "Restore report"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid policy or archive, or a record clashes with an existing one"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /library/restore [post]
+func (r *libraryRoutes) restoreLibrary(c echo.Context) error {
+	policy := c.QueryParams().Get("policy")
+	if policy == "" {
+		policy = entity.RestorePolicyFail
+	}
+
+	var body io.Reader = c.Request().Body
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("file not provided"))
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("failed to open the file"))
+		}
+		defer file.Close()
+
+		body = file
+	}
+
+	report, err := r.backupService.RestoreLibrary(c.Request().Context(), body, policy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidArchive) || errors.Is(err, service.ErrRestoreConflict) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "library restored", report)
+}
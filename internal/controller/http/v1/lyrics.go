@@ -0,0 +1,285 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type verseOutput struct {
+	VerseNumber  int
+	StanzaNumber int
+	Verse        string
+}
+
+type stanzaOutput struct {
+	StanzaNumber int
+	Label        *string
+	Verses       []verseOutput
+}
+
+func newVerseOutputs(verses []entity.LyricsVerse) []verseOutput {
+	var outputs []verseOutput
+	for _, verse := range verses {
+		outputs = append(outputs, verseOutput{
+			VerseNumber:  verse.VerseNumber,
+			StanzaNumber: verse.StanzaNumber,
+			Verse:        verse.Verse,
+		})
+	}
+
+	return outputs
+}
+
+func newStanzaOutputs(stanzas []entity.LyricsStanza) []stanzaOutput {
+	var outputs []stanzaOutput
+	for _, stanza := range stanzas {
+		outputs = append(outputs, stanzaOutput{
+			StanzaNumber: stanza.StanzaNumber,
+			Label:        stanza.Label,
+			Verses:       newVerseOutputs(stanza.Verses),
+		})
+	}
+
+	return outputs
+}
+
+// @Summary Get a verse of the lyrics
+// @Description This endpoint retrieves a single verse of the song's lyrics by its 1-based number.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param verse_number path int true "Verse number"
+// @Success 200 {object} SuccessResponse "Verse retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid verse number, song or verse not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/verses/{verse_number} [get]
+func (r *songRoutes) getVerse(c echo.Context) error {
+	verseNumber, err := parseVerseNumber(c)
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	verse, err := r.songService.GetLyricsVerse(c.Request().Context(), c.Param("song_id"), verseNumber)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrVerseNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "verse retrieved", verseOutput{
+		VerseNumber:  verse.VerseNumber,
+		StanzaNumber: verse.StanzaNumber,
+		Verse:        verse.Verse,
+	})
+}
+
+type verseInput struct {
+	Verse string `json:"verse"`
+}
+
+// @Summary Replace a verse of the lyrics
+// @Description This endpoint replaces the text of a single verse of the song's lyrics. A verse is a single line and may be empty.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param verse_number path int true "Verse number"
+// @Param input body verseInput true "New text of the verse"
+// @Success 200 {object} SuccessResponse "Verse updated successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid verse, song or verse not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/verses/{verse_number} [put]
+func (r *songRoutes) updateVerse(c echo.Context) error {
+	verseNumber, err := parseVerseNumber(c)
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	var input verseInput
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	err = r.songService.UpdateLyricsVerse(c.Request().Context(), c.Param("song_id"), verseNumber, input.Verse)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrVerseNotFound) || errors.Is(err, service.ErrInvalidVerse) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "verse updated", nil)
+}
+
+type verseInsertInput struct {
+	Verse       string `json:"verse"`
+	VerseNumber int    `json:"verseNumber" validate:"gte=0"`
+}
+
+// @Summary Insert a verse into the lyrics
+// @Description This endpoint appends a verse to the song's lyrics, or inserts it at the given 1-based verse number renumbering the following verses. A verse is a single line and may be empty. It joins the stanza of the verse it takes the place of, or the last stanza when appended.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param input body verseInsertInput true "Verse and the number it takes (appended when omitted)"
+// @Success 200 {object} SuccessResponse "Verse inserted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid verse or position, song not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/verses [post]
+func (r *songRoutes) insertVerse(c echo.Context) error {
+	var input verseInsertInput
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	verse := entity.LyricsVerse{
+		SongID:      c.Param("song_id"),
+		Verse:       input.Verse,
+		VerseNumber: input.VerseNumber,
+	}
+
+	verseNumber, err := r.songService.InsertLyricsVerse(c.Request().Context(), &verse)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrInvalidVerse) || errors.Is(err, service.ErrInvalidPosition) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		VerseNumber int
+	}{
+		VerseNumber: verseNumber,
+	}
+
+	return newSuccessResponse(c, "verse inserted", responseContent)
+}
+
+type verseMoveInput struct {
+	Position int `json:"position" validate:"required,gte=1"`
+}
+
+// @Summary Move a verse of the lyrics
+// @Description This endpoint moves a verse to the given 1-based verse number, renumbering the verses in between. The verse joins the stanza of the verse it takes the place of, and a stanza left without verses is deleted.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param verse_number path int true "Verse number"
+// @Param input body verseMoveInput true "New verse number"
+// @Success 200 {object} SuccessResponse "Verse moved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid position, song or verse not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/verses/{verse_number}/position [put]
+func (r *songRoutes) moveVerse(c echo.Context) error {
+	verseNumber, err := parseVerseNumber(c)
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	var input verseMoveInput
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	err = r.songService.MoveLyricsVerse(c.Request().Context(), c.Param("song_id"), verseNumber, input.Position)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrVerseNotFound) || errors.Is(err, service.ErrInvalidPosition) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "verse moved", nil)
+}
+
+// @Summary Delete a verse of the lyrics
+// @Description This endpoint deletes a verse of the song's lyrics, renumbering the following verses. A stanza left without verses is deleted.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param verse_number path int true "Verse number"
+// @Success 200 {object} SuccessResponse "Verse deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid verse number, song or verse not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/verses/{verse_number} [delete]
+func (r *songRoutes) deleteVerse(c echo.Context) error {
+	verseNumber, err := parseVerseNumber(c)
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	err = r.songService.DeleteLyricsVerse(c.Request().Context(), c.Param("song_id"), verseNumber)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrVerseNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "verse deleted", nil)
+}
+
+type stanzaInput struct {
+	Label *string `json:"label"`
+}
+
+// @Summary Label a stanza of the lyrics
+// @Description This endpoint sets the label of a stanza, such as Verse 1, Chorus or Bridge, written as [Label] above the stanza in the lyrics text. A null or empty label removes it.
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param stanza_number path int true "Stanza number"
+// @Param input body stanzaInput true "New label of the stanza"
+// @Success 200 {object} SuccessResponse "Stanza updated successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid label, song or stanza not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/lyrics/stanzas/{stanza_number} [put]
+func (r *songRoutes) updateStanza(c echo.Context) error {
+	stanzaNumber, err := strconv.Atoi(c.Param("stanza_number"))
+	if err != nil || stanzaNumber < 1 {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid stanza number"))
+	}
+
+	var input stanzaInput
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	err = r.songService.UpdateLyricsStanza(c.Request().Context(), c.Param("song_id"), stanzaNumber, input.Label)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrStanzaNotFound) || errors.Is(err, service.ErrInvalidStanzaLabel) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "stanza updated", nil)
+}
+
+func parseVerseNumber(c echo.Context) (int, error) {
+	verseNumber, err := strconv.Atoi(c.Param("verse_number"))
+	if err != nil || verseNumber < 1 {
+		return 0, errors.New("invalid verse number")
+	}
+
+	return verseNumber, nil
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+type metadataCacheRoutes struct {
+	metadataCacheService service.MetadataCache
+}
+
+func newMetadataCacheRoutes(g *echo.Group, metadataCacheService service.MetadataCache) {
+	r := &metadataCacheRoutes{
+		metadataCacheService: metadataCacheService,
+	}
+
+	g.GET("/cache", r.getStats)
+	g.DELETE("/cache", r.purge)
+}
+
+// @Summary Get song metadata cache stats
+// @Description This endpoint returns the state of the cache in front of the metadata provider: its capacity and entries, the entries kept in the database when the cache is persistent, and the hits, negative hits (songs the provider did not know), misses and evictions since the start.
+// @Tags metadata
+// @Produce json
+// @Success 200 {object} SuccessResponse "Cache stats retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /metadata/cache [get]
+func (r *metadataCacheRoutes) getStats(c echo.Context) error {
+	stats, err := r.metadataCacheService.GetMetadataCacheStats(c.Request().Context())
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "metadata cache stats retrieved", stats)
+}
+
+// @Summary Purge the song metadata cache
+// @Description This endpoint drops the cached lookup of the song when group and title are given, or every cached lookup when neither is, and returns how many entries were dropped from memory and from the database.
+// @Tags metadata
+// @Produce json
+// @Param group query string false "Group name (must be provided with title)"
+// @Param title query string false "Song title (must be provided with group)"
+// @Success 200 {object} SuccessResponse "Cache purged successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /metadata/cache [delete]
+func (r *metadataCacheRoutes) purge(c echo.Context) error {
+	groupName := strings.TrimSpace(c.QueryParam("group"))
+	title := strings.TrimSpace(c.QueryParam("title"))
+	if (groupName == "") != (title == "") {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both group and title should be provided, or neither of them"))
+	}
+
+	purge, err := r.metadataCacheService.PurgeMetadataCache(c.Request().Context(), groupName, title)
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "metadata cache purged", purge)
+}
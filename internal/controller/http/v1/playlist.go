@@ -0,0 +1,286 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type playlistRoutes struct {
+	playlistService service.Playlist
+}
+
+func newPlaylistRoutes(g *echo.Group, playlistService service.Playlist) {
+	r := &playlistRoutes{
+		playlistService: playlistService,
+	}
+
+	g.POST("", r.create)
+	g.GET("", r.getPlaylists)
+	g.GET("/:playlist_id", r.getByID)
+	g.PUT("/:playlist_id", r.update)
+	g.DELETE("/:playlist_id", r.delete)
+
+	g.POST("/:playlist_id/entries", r.addEntry)
+	g.PUT("/:playlist_id/entries/:entry_id/position", r.moveEntry)
+	g.DELETE("/:playlist_id/entries/:entry_id", r.removeEntry)
+}
+
+type playlistCreateInput struct {
+	Name        string  `json:"name" validate:"required"`
+	Description *string `json:"description"`
+}
+
+// @Summary Creates a new playlist
+// @Description This endpoint creates a new empty playlist.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param input body playlistCreateInput true "Playlist creation input"
+// @Success 200 {object} SuccessResponse "Playlist created successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists [post]
+func (r *playlistRoutes) create(c echo.Context) error {
+	var input playlistCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	playlist := entity.Playlist{
+		Name:        input.Name,
+		Description: input.Description,
+	}
+
+	id, err := r.playlistService.CreatePlaylist(c.Request().Context(), &playlist)
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		ID string
+	}{
+		ID: id,
+	}
+
+	return newSuccessResponse(c, "playlist created", responseContent)
+}
+
+// @Summary Get playlists
+// @Description This endpoint retrieves all playlists with the number of entries each of them has.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Success 200 {object} SuccessResponse "List of playlists retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists [get]
+func (r *playlistRoutes) getPlaylists(c echo.Context) error {
+	playlists, err := r.playlistService.GetPlaylists(c.Request().Context())
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlists retrieved", playlists)
+}
+
+// @Summary Get a playlist by ID
+// @Description This endpoint retrieves a playlist with its ordered entries and the details of their songs.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID to retrieve"
+// @Success 200 {object} SuccessResponse "Playlist retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - playlist not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id} [get]
+func (r *playlistRoutes) getByID(c echo.Context) error {
+	playlistID := c.Param("playlist_id")
+
+	playlist, err := r.playlistService.GetPlaylistByID(c.Request().Context(), playlistID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlist retrieved", playlist)
+}
+
+// @Summary Update a playlist
+// @Description This endpoint renames a playlist or changes its description.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID to update"
+// @Param input body entity.PlaylistUpdate true "Playlist update input"
+// @Success 200 {object} SuccessResponse "Playlist updated successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input or playlist not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id} [put]
+func (r *playlistRoutes) update(c echo.Context) error {
+	var input entity.PlaylistUpdate
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid request body"))
+	}
+	input.ID = c.Param("playlist_id")
+
+	if input.Name != nil && *input.Name == "" {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("name cannot be empty"))
+	}
+
+	err := r.playlistService.UpdatePlaylist(c.Request().Context(), &input)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlist updated", nil)
+}
+
+// @Summary Delete a playlist
+// @Description This endpoint deletes a playlist with all of its entries.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID to delete"
+// @Success 200 {object} SuccessResponse "Playlist deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - playlist not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id} [delete]
+func (r *playlistRoutes) delete(c echo.Context) error {
+	playlistID := c.Param("playlist_id")
+
+	err := r.playlistService.DeletePlaylist(c.Request().Context(), playlistID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlist deleted", nil)
+}
+
+type playlistEntryInput struct {
+	SongID   string  `json:"songId" validate:"required"`
+	Note     *string `json:"note"`
+	Position int     `json:"position" validate:"gte=0"`
+}
+
+// @Summary Add a song to a playlist
+// @Description This endpoint appends a song to the playlist, or inserts it at the given 1-based position shifting the following entries down.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID"
+// @Param input body playlistEntryInput true "Playlist entry input"
+// @Success 200 {object} SuccessResponse "Entry added successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input, playlist or song not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id}/entries [post]
+func (r *playlistRoutes) addEntry(c echo.Context) error {
+	var input playlistEntryInput
+
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	entry := entity.PlaylistEntry{
+		PlaylistID: c.Param("playlist_id"),
+		SongID:     input.SongID,
+		Position:   input.Position,
+		Note:       input.Note,
+	}
+
+	id, err := r.playlistService.AddPlaylistEntry(c.Request().Context(), &entry)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrInvalidPosition) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		ID string
+	}{
+		ID: id,
+	}
+
+	return newSuccessResponse(c, "playlist entry added", responseContent)
+}
+
+type playlistEntryMoveInput struct {
+	Position int `json:"position" validate:"required,gte=1"`
+}
+
+// @Summary Move a playlist entry
+// @Description This endpoint moves an entry to the given 1-based position, shifting the entries in between.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID"
+// @Param entry_id path string true "Playlist entry ID"
+// @Param input body playlistEntryMoveInput true "New position"
+// @Success 200 {object} SuccessResponse "Entry moved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid position, playlist or entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id}/entries/{entry_id}/position [put]
+func (r *playlistRoutes) moveEntry(c echo.Context) error {
+	var input playlistEntryMoveInput
+
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	err := r.playlistService.MovePlaylistEntry(c.Request().Context(), c.Param("playlist_id"), c.Param("entry_id"), input.Position)
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, service.ErrPlaylistEntryNotFound) || errors.Is(err, service.ErrInvalidPosition) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlist entry moved", nil)
+}
+
+// @Summary Remove a playlist entry
+// @Description This endpoint removes an entry from the playlist and closes the gap it leaves.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist_id path string true "Playlist ID"
+// @Param entry_id path string true "Playlist entry ID"
+// @Success 200 {object} SuccessResponse "Entry removed successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /playlists/{playlist_id}/entries/{entry_id} [delete]
+func (r *playlistRoutes) removeEntry(c echo.Context) error {
+	err := r.playlistService.RemovePlaylistEntry(c.Request().Context(), c.Param("playlist_id"), c.Param("entry_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrPlaylistEntryNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "playlist entry removed", nil)
+}
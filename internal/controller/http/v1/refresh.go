@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// @Summary Refresh a song from the metadata provider
+// @Description This endpoint looks the song up again in the metadata provider and returns a field-level diff of its release date, link and lyrics against the stored values. With mode=preview (default) nothing is stored, fill stores only the fields the song lacks, and apply stores every difference, replacing the lyrics as the song update does.
+// @Tags songs
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param mode query string false "Refresh mode (preview, fill or apply, default preview)"
+// @Success 200 {object} SuccessResponse "Song refreshed successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid mode, song not found or no metadata was found for it"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 502 {object} ErrorResponse "Metadata source returned an invalid response"
+// @Failure 503 {object} ErrorResponse "Metadata source unavailable"
+// @Failure 504 {object} ErrorResponse "Metadata source timed out"
+// @Router /songs/{song_id}/refresh [post]
+func (r *songRoutes) refresh(c echo.Context) error {
+	mode, err := parseRefreshMode(c.QueryParams().Get("mode"))
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	refresh, err := r.songService.RefreshSong(c.Request().Context(), c.Param("song_id"), mode)
+	if err != nil {
+		return newRefreshErrorResponse(c, err)
+	}
+
+	return newSuccessResponse(c, "song refreshed", refresh)
+}
+
+// @Summary Refresh songs from the metadata provider
+// @Description This endpoint refreshes every song matched by the listing filters or by the id parameters, at most 100 at once, as /songs/{song_id}/refresh does. A song that could not be looked up is reported with its error instead of failing the whole batch.
+// @Tags songs
+// @Produce json
+// @Param mode query string false "Refresh mode (preview, fill or apply, default preview)"
+// @Param id query string false "Song IDs, comma-separated or repeated"
+// @Param title query string false "Filter by title"
+// @Param group query string false "Filter by group name"
+// @Param fuzzy query bool false "Match title and group despite typos, by trigram similarity"
+// @Param link query string false "Filter by link"
+// @Param text query string false "Filter by lyrics containing all of the words, in any of their forms"
+// @Param hasLyrics query bool false "Filter by whether the song has lyrics"
+// @Param album query string false "Filter by album title"
+// @Param albumId query string false "Filter by album ID"
+// @Param tag query string false "Filter by tag name"
+// @Param anyTag query string false "Filter by any of the comma-separated tag names"
+// @Param allTags query string false "Filter by all of the comma-separated tag names"
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)"
+// @Param sort query string false "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)"
+// @Param page query int false "Page number for pagination (must be provided with limit)"
+// @Param limit query int false "Limit of items per page (the first page without page)"
+// @Success 200 {object} SuccessResponse "Songs refreshed successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters or too many songs matched"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/refresh [post]
+func (r *songRoutes) refreshBatch(c echo.Context) error {
+	mode, err := parseRefreshMode(c.QueryParams().Get("mode"))
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	filter, err := parseSongFilter(c.QueryParams())
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter.IDs = splitQueryList(c.QueryParams()["id"])
+
+	refreshes, err := r.songService.RefreshSongs(c.Request().Context(), filter, mode)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshBatchTooLarge) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "songs refreshed", refreshes)
+}
+
+func parseRefreshMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return entity.RefreshModePreview, nil
+	case entity.RefreshModePreview, entity.RefreshModeFill, entity.RefreshModeApply:
+		return mode, nil
+	}
+
+	return "", errors.New("invalid mode, must be preview, fill or apply")
+}
+
+func newRefreshErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrSongNotFound), errors.Is(err, service.ErrSongDetailNotFound), errors.Is(err, service.ErrSongAlreadyExists):
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrExternalAPIFailed), errors.Is(err, service.ErrInvalidSongDetail):
+		return newErrorResponse(c, http.StatusBadGateway, err)
+	case errors.Is(err, service.ErrExternalAPIUnavailable):
+		return newErrorResponse(c, http.StatusServiceUnavailable, err)
+	case errors.Is(err, service.ErrExternalAPITimeout):
+		return newErrorResponse(c, http.StatusGatewayTimeout, err)
+	}
+
+	return newErrorResponse(c, http.StatusInternalServerError, err)
+}
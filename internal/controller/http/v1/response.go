@@ -17,10 +17,10 @@ type ErrorResponse struct {
 
 func newErrorResponse(c echo.Context, statusCode int, err error) error {
 	var response ErrorResponse
-	if statusCode != http.StatusInternalServerError {
+	if statusCode < http.StatusInternalServerError {
 		response.Error = err.Error()
 	} else {
-		response.Error = http.StatusText(http.StatusInternalServerError)
+		response.Error = http.StatusText(statusCode)
 	}
 
 	errJSON := c.JSON(statusCode, response)
@@ -20,6 +20,15 @@ func NewRouter(handler *echo.Echo, service *service.Service) {
 	v1 := handler.Group("/api/v1")
 	{
 		newSongRoutes(v1.Group("/songs"), service)
+		newImportRoutes(v1.Group("/songs"), service)
+		newGroupRoutes(v1.Group("/groups"), service)
+		newAlbumRoutes(v1.Group("/albums"), service)
+		newTagRoutes(v1.Group("/tags"), service)
+		newPlaylistRoutes(v1.Group("/playlists"), service)
+		newLibraryRoutes(v1.Group("/library"), service)
+		newEnrichmentRoutes(v1.Group("/songs"), v1.Group("/enrichment"), service)
+		newMetadataCacheRoutes(v1.Group("/metadata"), service)
+		newSearchRoutes(v1.Group("/search"), service)
 	}
 }
 
@@ -0,0 +1,128 @@
+package v1_test
+
+import (
+	"bytes"
+	v1 "effective_mobile_tz/internal/controller/http/v1"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"effective_mobile_tz/pkg/validator"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the router appends the request log to ./logs
+	dir, err := os.MkdirTemp("", "v1-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Mkdir(dir+"/logs", 0o755); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newServer(t *testing.T, backend *repotest.Backend) *httptest.Server {
+	t.Helper()
+
+	handler := echo.New()
+	handler.Validator = validator.NewCustomValidator()
+	v1.NewRouter(handler, service.NewService(service.Dependencies{Repository: backend.Repository}))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestConcurrentRequests creates songs of a shared group and reads the
+// listing from many clients at once. Run it with -race.
+func TestConcurrentRequests(t *testing.T) {
+	const clients, songsPerClient = 8, 10
+
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		server := newServer(t, backend)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, clients*songsPerClient*3)
+		for client := 0; client < clients; client++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for song := 0; song < songsPerClient; song++ {
+					body := fmt.Sprintf(`{"group":"Shared group","title":"Song %d-%d"}`, client, song)
+					if _, err := request(server, http.MethodPost, "/api/v1/songs", body); err != nil {
+						errs <- err
+					}
+					if _, err := request(server, http.MethodGet, "/api/v1/songs?limit=5&sort=-createdAt", ""); err != nil {
+						errs <- err
+					}
+					if _, err := request(server, http.MethodGet, "/api/v1/groups", ""); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		content, err := request(server, http.MethodGet, "/api/v1/songs?limit=1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list struct {
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal(content, &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Total != clients*songsPerClient {
+			t.Errorf("got %d songs, want %d", list.Total, clients*songsPerClient)
+		}
+	})
+}
+
+// request sends the request and returns the content of the success response.
+func request(server *httptest.Server, method, path, body string) (json.RawMessage, error) {
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Content json.RawMessage `json:"content"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, response.Error)
+	}
+
+	return response.Content, nil
+}
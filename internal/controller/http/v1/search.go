@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+type searchRoutes struct {
+	searchService service.Search
+}
+
+func newSearchRoutes(g *echo.Group, searchService service.Search) {
+	r := &searchRoutes{
+		searchService: searchService,
+	}
+
+	g.GET("", r.searchSongs)
+	g.GET("/suggest", r.suggestSongs)
+}
+
+// @Summary Search songs by lyrics
+// @Description This endpoint searches the lyrics in the configured text search language, so that the words match in any of their forms. Songs are ranked by relevance and come with a snippet of the matching verses, the matching words wrapped in <b></b>, and the numbers of the matching verses. The query takes words, which must all occur in the song, "quoted phrases", prefixes ending with * (love*), words or phrases excluded with a leading - (-rain) and alternatives joined by OR (night OR day).
+// @Tags search
+// @Produce json
+// @Param q query string true "Search query"
+// @Param page query int false "Page number for pagination (must be provided with limit)"
+// @Param limit query int false "Limit of items per page (must be provided with page)"
+// @Success 200 {object} SuccessResponse "Matching songs retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid query or pagination parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /search [get]
+func (r *searchRoutes) searchSongs(c echo.Context) error {
+	query := c.QueryParam("q")
+	page := c.QueryParam("page")
+	limit := c.QueryParam("limit")
+
+	limitInt, offset := 0, 0
+	if (page == "" && limit != "") || (page != "" && limit == "") {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both page and limit should be provided, or neither of them"))
+	} else if page != "" && limit != "" {
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid page number"))
+		}
+		limitInt, err = strconv.Atoi(limit)
+		if err != nil || limitInt < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid limit number"))
+		}
+		offset = (pageInt - 1) * limitInt
+	}
+
+	results, err := r.searchService.SearchSongs(c.Request().Context(), query, limitInt, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchQuery) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "songs found", results)
+}
+
+// @Summary Suggest song titles and groups
+// @Description This endpoint autocompletes a song title or a group name that may be incomplete or misspelt. It returns the titles and the groups whose word similarity to the query reaches the configured threshold, best first, each with its similarity score from 0 to 1.
+// @Tags search
+// @Produce json
+// @Param q query string true "Title or group name, or a part of it"
+// @Param limit query int false "Maximum number of suggestions of each kind (at most 50, default configured)"
+// @Success 200 {object} SuccessResponse "Suggestions retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - empty query or invalid limit"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /search/suggest [get]
+func (r *searchRoutes) suggestSongs(c echo.Context) error {
+	limitInt := 0
+	if limit := c.QueryParam("limit"); limit != "" {
+		var err error
+		limitInt, err = strconv.Atoi(limit)
+		if err != nil || limitInt < 1 {
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid limit number"))
+		}
+	}
+
+	suggestions, err := r.searchService.SuggestSongs(c.Request().Context(), c.QueryParam("q"), limitInt)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchQuery) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "suggestions retrieved", suggestions)
+}
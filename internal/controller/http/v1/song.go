@@ -3,11 +3,14 @@ package v1
 import (
 	"effective_mobile_tz/internal/entity"
 	"effective_mobile_tz/internal/service"
+	"effective_mobile_tz/pkg/partialdate"
 	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,8 +28,20 @@ func newSongRoutes(g *echo.Group, songService service.Song) {
 	g.GET("/:song_id", r.getByID)
 	g.DELETE("/:song_id", r.delete)
 	g.PUT("", r.updateSong)
+	g.GET("/export", r.export)
+	g.POST("/export", r.export)
+	g.POST("/refresh", r.refreshBatch)
+	g.POST("/:song_id/refresh", r.refresh)
+	g.PUT("/:song_id/tags/:tag_id", r.attachTag)
+	g.DELETE("/:song_id/tags/:tag_id", r.detachTag)
 
 	g.GET("/lyrics/:song_id", r.getPaginatedLyrics)
+	g.POST("/:song_id/lyrics/verses", r.insertVerse)
+	g.GET("/:song_id/lyrics/verses/:verse_number", r.getVerse)
+	g.PUT("/:song_id/lyrics/verses/:verse_number", r.updateVerse)
+	g.PUT("/:song_id/lyrics/verses/:verse_number/position", r.moveVerse)
+	g.DELETE("/:song_id/lyrics/verses/:verse_number", r.deleteVerse)
+	g.PUT("/:song_id/lyrics/stanzas/:stanza_number", r.updateStanza)
 }
 
 type songCreateInput struct {
@@ -35,13 +50,13 @@ type songCreateInput struct {
 }
 
 // @Summary Creates a new song
-// @Description This endpoint creates a new song by specifying the group and title.
+// @Description This endpoint creates a new song by specifying the group and title. The song is stored right away with the pending enrichment status; its release date, link and lyrics are filled in from the metadata provider in the background.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param input body songCreateInput true "Song creation input"
 // @Success 200 {object} SuccessResponse "Song created successfully"
-// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 400 {object} ErrorResponse "Bad request - song already exists"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /songs [post]
 func (r *songRoutes) create(c echo.Context) error {
@@ -73,61 +88,143 @@ func (r *songRoutes) create(c echo.Context) error {
 }
 
 // @Summary Get songs by filter
-// @Description This endpoint retrieves songs from the library based on various filter criteria such as title, group, link, text, release date range, and pagination.
+// @Description This endpoint retrieves songs from the library based on various filter criteria such as title, group, link, text, release date range, and pagination, sorted by one or more keys. The content holds the songs of the page as items, the number of songs matching the filter as total, and the page and limit (0 when not paginated). Paginated listings also hold nextCursor and prevCursor, which are given to after and before for the next and the previous page; unlike pages, cursors never skip or repeat songs when songs are added or removed meanwhile. A cursor is only valid with the sort order it was returned for.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param title query string false "Filter by title"
 // @Param group query string false "Filter by group name"
+// @Param fuzzy query bool false "Match title and group despite typos, by trigram similarity"
 // @Param link query string false "Filter by link"
-// @Param text query string false "Filter by text (contains)"
-// @Param startDate query string false "Filter by start date (YYYY-MM-DD)"
-// @Param endDate query string false "Filter by end date (YYYY-MM-DD)"
+// @Param text query string false "Filter by lyrics containing all of the words, in any of their forms"
+// @Param hasLyrics query bool false "Filter by whether the song has lyrics"
+// @Param album query string false "Filter by album title"
+// @Param albumId query string false "Filter by album ID"
+// @Param tag query string false "Filter by tag name"
+// @Param anyTag query string false "Filter by any of the comma-separated tag names"
+// @Param allTags query string false "Filter by all of the comma-separated tag names"
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with endDate)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD, YYYY-MM or YYYY, must be provided with startDate)"
+// @Param sort query string false "Comma-separated sort keys among title, group, releaseDate and createdAt, a leading - sorting in descending order (default group,title)"
 // @Param page query int false "Page number for pagination (must be provided with limit)"
-// @Param limit query int false "Limit of items per page (must be provided with page)"
+// @Param limit query int false "Limit of items per page (the first page without page)"
+// @Param include query string false "Comma-separated details loaded along with the songs among lyrics and tags (all of them by default, none when empty)"
+// @Param after query string false "Cursor of the song the page follows (nextCursor of the previous page, must be provided with limit)"
+// @Param before query string false "Cursor of the song the page precedes (prevCursor of the next page, must be provided with limit)"
 // @Success 200 {object} SuccessResponse "List of songs retrieved successfully"
 // @Failure 400 {object} ErrorResponse "Bad request - invalid filter parameters"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /songs [get]
 func (r *songRoutes) getSongsByFilter(c echo.Context) error {
-	params := c.QueryParams()
+	filter, err := parseSongFilter(c.QueryParams())
+	if err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	after := c.QueryParam("after")
+	before := c.QueryParam("before")
+	if (after != "" || before != "") && (filter.Limit == 0 || filter.Offset > 0) {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("after and before should be provided with limit and without page"))
+	}
+
+	songs, err := r.songService.ListSongs(c.Request().Context(), filter, after, before)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "songs retrieved", songs)
+}
+
+// parseSongFilter builds a song filter out of the song listing query parameters.
+func parseSongFilter(params url.Values) (*entity.SongFilter, error) {
 	title := params.Get("title")
 	group := params.Get("group")
 	link := params.Get("link")
 	text := params.Get("text")
+	album := params.Get("album")
+	albumID := params.Get("albumId")
+	tag := params.Get("tag")
+	anyTags := splitQueryList(params["anyTag"])
+	allTags := splitQueryList(params["allTags"])
 	startDateStr := params.Get("startDate")
 	endDateStr := params.Get("endDate")
 	page := params.Get("page")
 	limit := params.Get("limit")
 
+	sort, err := parseSongSort(splitQueryList(params["sort"]))
+	if err != nil {
+		return nil, err
+	}
+
+	// without the parameter every detail is included
+	var include []string
+	if params.Has("include") {
+		include = []string{}
+		for _, detail := range splitQueryList(params["include"]) {
+			if detail != entity.SongIncludeLyrics && detail != entity.SongIncludeTags {
+				return nil, fmt.Errorf("invalid include value %q, expected lyrics or tags", detail)
+			}
+			include = append(include, detail)
+		}
+	}
+
+	var hasLyrics *bool
+	if hasLyricsStr := params.Get("hasLyrics"); hasLyricsStr != "" {
+		value, err := strconv.ParseBool(hasLyricsStr)
+		if err != nil {
+			return nil, errors.New("invalid hasLyrics value")
+		}
+		hasLyrics = &value
+	}
+
+	var fuzzy bool
+	if fuzzyStr := params.Get("fuzzy"); fuzzyStr != "" {
+		fuzzy, err = strconv.ParseBool(fuzzyStr)
+		if err != nil {
+			return nil, errors.New("invalid fuzzy value")
+		}
+	}
+
 	if (startDateStr == "" && endDateStr != "") || (startDateStr != "" && endDateStr == "") {
-		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both startDateStr and endDateStr should be provided, or neither of them"))
+		return nil, errors.New("either both startDateStr and endDateStr should be provided, or neither of them")
 	}
+	// partial bounds cover their whole period, so that startDate=2006&endDate=2007
+	// runs from 2006-01-01 to 2007-12-31
+	var startDate, endDate string
 	if startDateStr != "" && endDateStr != "" {
-		startDate, err := time.Parse("2006-01-02", startDateStr)
+		start, err := partialdate.Parse(startDateStr)
 		if err != nil {
-			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid startDate"))
+			return nil, errors.New("invalid startDate")
 		}
-		endDate, err := time.Parse("2006-01-02", endDateStr)
+		end, err := partialdate.Parse(endDateStr)
 		if err != nil {
-			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid endDate"))
+			return nil, errors.New("invalid endDate")
 		}
-		if startDate.After(endDate) {
-			return newErrorResponse(c, http.StatusBadRequest, errors.New("start_date cannot be after end_date"))
+		lastDay := end.End().AddDate(0, 0, -1)
+		if start.Time.After(lastDay) {
+			return nil, errors.New("start_date cannot be after end_date")
 		}
+		startDate, endDate = start.Time.Format(time.DateOnly), lastDay.Format(time.DateOnly)
 	}
 
+	// a limit alone is the first page, which cursors lead on from
 	limitInt, pageInt := 0, 0
-	if (page == "" && limit != "") || (page != "" && limit == "") {
-		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both page and limit should be provided, or neither of them"))
-	} else if page != "" && limit != "" {
-		pageInt, err := strconv.Atoi(page)
-		if err != nil || pageInt < 1 {
-			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid page number"))
-		}
-		limitInt, err := strconv.Atoi(limit)
+	if page != "" && limit == "" {
+		return nil, errors.New("page should be provided with limit")
+	} else if limit != "" {
+		limitInt, err = strconv.Atoi(limit)
 		if err != nil || limitInt < 1 {
-			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid limit number"))
+			return nil, errors.New("invalid limit number")
+		}
+		pageInt = 1
+		if page != "" {
+			pageInt, err = strconv.Atoi(page)
+			if err != nil || pageInt < 1 {
+				return nil, errors.New("invalid page number")
+			}
 		}
 	}
 
@@ -135,20 +232,25 @@ func (r *songRoutes) getSongsByFilter(c echo.Context) error {
 		Title:     title,
 		Link:      link,
 		Group:     group,
+		Fuzzy:     fuzzy,
 		Text:      text,
-		StartDate: startDateStr,
-		EndDate:   endDateStr,
-		Limit:     limitInt,
-		Offset:    pageInt,
+		HasLyrics: hasLyrics,
+		Album:     album,
+		AlbumID:   albumID,
+		Tag:       tag,
+		AnyTags:   anyTags,
+		AllTags:   allTags,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Sort:      sort,
+		Include:   include,
 	}
-
-	songs, err := r.songService.GetSongsByFilter(c.Request().Context(), &filter)
-	if err != nil {
-		return newErrorResponse(c, http.StatusInternalServerError, err)
-
+	if pageInt > 0 {
+		filter.Limit = limitInt
+		filter.Offset = (pageInt - 1) * limitInt
 	}
 
-	return newSuccessResponse(c, "songs retrieved", songs)
+	return &filter, nil
 }
 
 // @Summary Delete a song
@@ -202,7 +304,7 @@ func (r *songRoutes) getByID(c echo.Context) error {
 }
 
 // @Summary Update a song
-// @Description This endpoint updates a song's details. The song ID must be provided in the request body.
+// @Description This endpoint updates a song's details. The song ID must be provided in the request body. An empty albumId detaches the song from its album, which must otherwise belong to the group of the song. The release date may be partial (DD.MM.YYYY, YYYY-MM-DD, YYYY-MM, YYYY and common variants); how much of it is known is returned as releaseDatePrecision (day, month or year).
 // @Tags songs
 // @Accept json
 // @Produce json
@@ -223,7 +325,8 @@ func (r *songRoutes) updateSong(c echo.Context) error {
 
 	err := r.songService.UpdateSong(c.Request().Context(), &input)
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrSongAlreadyExists) {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrSongAlreadyExists) || errors.Is(err, service.ErrAlbumNotFound) ||
+			errors.Is(err, service.ErrAlbumOfOtherGroup) || errors.Is(err, service.ErrInvalidReleaseDate) {
 			return newErrorResponse(c, http.StatusBadRequest, err)
 		}
 
@@ -234,13 +337,16 @@ func (r *songRoutes) updateSong(c echo.Context) error {
 }
 
 // @Summary Get paginated lyrics
-// @Description This endpoint retrieves paginated lyrics for a specific song by its ID.
+// @Description This endpoint retrieves paginated lyrics for a specific song by its ID, line by line or, with by=stanza, stanza by stanza along with the lines and the label of each stanza. With page and limit it returns the lines or stanzas of the page. With limit alone, or with the after or before cursor, it returns the lines or stanzas following or preceding the cursor (from the first one without a cursor) along with nextCursor and prevCursor, to be given to after and before for the next and the previous page. Cursors only work with the by value they were returned with.
 // @Tags lyrics
 // @Accept json
 // @Produce json
 // @Param song_id path string true "Song ID"
-// @Param page query int true "Page number (must be provided with limit)"
-// @Param limit query int true "Limit of items per page (must be provided with page)"
+// @Param by query string false "Paginate by line (default) or by stanza" Enums(line, stanza)
+// @Param page query int false "Page number (must be provided with limit)"
+// @Param limit query int true "Limit of items per page"
+// @Param after query string false "Cursor of the line or stanza the page follows (nextCursor of the previous page)"
+// @Param before query string false "Cursor of the line or stanza the page precedes (prevCursor of the next page)"
 // @Success 200 {object} SuccessResponse "Lyrics retrieved successfully"
 // @Failure 400 {object} ErrorResponse "Bad request - invalid input parameters"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -249,6 +355,21 @@ func (r *songRoutes) getPaginatedLyrics(c echo.Context) error {
 	songID := c.Param("song_id")
 	page := c.QueryParams().Get("page")
 	limit := c.QueryParams().Get("limit")
+	after := c.QueryParams().Get("after")
+	before := c.QueryParams().Get("before")
+
+	var byStanza bool
+	switch by := c.QueryParams().Get("by"); by {
+	case "", "line":
+	case "stanza":
+		byStanza = true
+	default:
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid by value %q, expected line or stanza", by))
+	}
+
+	if after != "" || before != "" || (page == "" && limit != "") {
+		return r.getLyricsPage(c, songID, byStanza, page, limit, after, before)
+	}
 
 	if (page == "" && limit != "") || (page != "" && limit == "") {
 		return newErrorResponse(c, http.StatusBadRequest, errors.New("either both 'page' and 'limit' should be provided, or neither of them"))
@@ -264,23 +385,157 @@ func (r *songRoutes) getPaginatedLyrics(c echo.Context) error {
 
 	}
 
+	if byStanza {
+		stanzas, err := r.songService.GetPaginatedStanzas(c.Request().Context(), songID, pageInt, limitInt)
+		if err != nil {
+			return newErrorResponse(c, http.StatusInternalServerError, err)
+		}
+
+		return newSuccessResponse(c, "lyrics retrieved", newStanzaOutputs(stanzas))
+	}
+
 	lyrics, err := r.songService.GetPaginatedLyrics(c.Request().Context(), songID, pageInt, limitInt)
 	if err != nil {
 		return newErrorResponse(c, http.StatusInternalServerError, err)
 
 	}
 
-	type lyricsOutput struct {
-		VerseNumber int
-		Verse       string
+	return newSuccessResponse(c, "lyrics retrieved", newVerseOutputs(lyrics))
+}
+
+// getLyricsPage serves the lyrics paginated with cursors.
+func (r *songRoutes) getLyricsPage(c echo.Context, songID string, byStanza bool, page, limit, after, before string) error {
+	if page != "" {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("page cannot be combined with after or before"))
+	}
+	if after != "" && before != "" {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("either after or before can be provided, not both"))
+	}
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil || limitInt < 1 {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid limit number"))
+	}
+
+	var lyrics *entity.LyricsPage
+	if byStanza {
+		lyrics, err = r.songService.GetStanzaPage(c.Request().Context(), songID, after, before, limitInt)
+	} else {
+		lyrics, err = r.songService.GetLyricsPage(c.Request().Context(), songID, after, before, limitInt)
 	}
-	var response []lyricsOutput
-	for _, l := range lyrics {
-		response = append(response, lyricsOutput{
-			VerseNumber: l.VerseNumber,
-			Verse:       l.Verse,
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	if byStanza {
+		return newSuccessResponse(c, "lyrics retrieved", struct {
+			Stanzas    []stanzaOutput `json:"stanzas"`
+			NextCursor string         `json:"nextCursor,omitempty"`
+			PrevCursor string         `json:"prevCursor,omitempty"`
+		}{
+			Stanzas:    newStanzaOutputs(lyrics.Stanzas),
+			NextCursor: lyrics.NextCursor,
+			PrevCursor: lyrics.PrevCursor,
 		})
 	}
 
-	return newSuccessResponse(c, "lyrics retrieved", response)
+	return newSuccessResponse(c, "lyrics retrieved", struct {
+		Verses     []verseOutput `json:"verses"`
+		NextCursor string        `json:"nextCursor,omitempty"`
+		PrevCursor string        `json:"prevCursor,omitempty"`
+	}{
+		Verses:     newVerseOutputs(lyrics.Verses),
+		NextCursor: lyrics.NextCursor,
+		PrevCursor: lyrics.PrevCursor,
+	})
+}
+
+// @Summary Attach a tag to a song
+// @Description This endpoint attaches a tag to a song. Attaching an already attached tag is a no-op.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param tag_id path string true "Tag ID"
+// @Success 200 {object} SuccessResponse "Tag attached successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - song or tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/tags/{tag_id} [put]
+func (r *songRoutes) attachTag(c echo.Context) error {
+	songID := c.Param("song_id")
+	tagID := c.Param("tag_id")
+
+	err := r.songService.AttachTag(c.Request().Context(), songID, tagID)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) || errors.Is(err, service.ErrTagNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tag attached", nil)
+}
+
+// @Summary Detach a tag from a song
+// @Description This endpoint detaches a tag from a song.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param song_id path string true "Song ID"
+// @Param tag_id path string true "Tag ID"
+// @Success 200 {object} SuccessResponse "Tag detached successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - tag is not attached to the song"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /songs/{song_id}/tags/{tag_id} [delete]
+func (r *songRoutes) detachTag(c echo.Context) error {
+	songID := c.Param("song_id")
+	tagID := c.Param("tag_id")
+
+	err := r.songService.DetachTag(c.Request().Context(), songID, tagID)
+	if err != nil {
+		if errors.Is(err, service.ErrTagNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tag detached", nil)
+}
+
+// parseSongSort reads sort keys such as title or -releaseDate, a leading -
+// sorting in descending order.
+func parseSongSort(keys []string) ([]entity.SongSort, error) {
+	var sort []entity.SongSort
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		field, desc := strings.CutPrefix(key, "-")
+		switch field {
+		case entity.SongSortTitle, entity.SongSortGroup, entity.SongSortReleaseDate, entity.SongSortCreatedAt:
+		default:
+			return nil, fmt.Errorf("invalid sort field %q, expected title, group, releaseDate or createdAt", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("sort field %q given more than once", field)
+		}
+		seen[field] = true
+		sort = append(sort, entity.SongSort{Field: field, Desc: desc})
+	}
+
+	return sort, nil
+}
+
+// splitQueryList flattens repeated and comma-separated query values.
+func splitQueryList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
 }
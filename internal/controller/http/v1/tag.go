@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type tagRoutes struct {
+	tagService service.Tag
+}
+
+func newTagRoutes(g *echo.Group, tagService service.Tag) {
+	r := &tagRoutes{
+		tagService: tagService,
+	}
+
+	g.POST("", r.create)
+	g.GET("", r.getTags)
+	g.GET("/:tag_id", r.getByID)
+	g.PUT("/:tag_id", r.update)
+	g.DELETE("/:tag_id", r.delete)
+}
+
+type tagCreateInput struct {
+	Name     string `json:"name" validate:"required"`
+	Category string `json:"category" validate:"omitempty,oneof=genre mood tag"`
+}
+
+// @Summary Creates a new tag
+// @Description This endpoint creates a new tag. The category is one of genre, mood or tag (free-form, the default). Tag names are case-insensitive.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param input body tagCreateInput true "Tag creation input"
+// @Success 200 {object} SuccessResponse "Tag created successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags [post]
+func (r *tagRoutes) create(c echo.Context) error {
+	var input tagCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := c.Validate(input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	tag := entity.Tag{
+		Name:     input.Name,
+		Category: input.Category,
+	}
+	if tag.Category == "" {
+		tag.Category = "tag"
+	}
+
+	id, err := r.tagService.CreateTag(c.Request().Context(), &tag)
+	if err != nil {
+		if errors.Is(err, service.ErrTagAlreadyExists) || errors.Is(err, service.ErrInvalidTagName) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	responseContent := struct {
+		ID string
+	}{
+		ID: id,
+	}
+
+	return newSuccessResponse(c, "tag created", responseContent)
+}
+
+// @Summary Get tags
+// @Description This endpoint retrieves tags with their usage counts, most used first.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param category query string false "Filter by category (genre, mood or tag)"
+// @Success 200 {object} SuccessResponse "List of tags retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags [get]
+func (r *tagRoutes) getTags(c echo.Context) error {
+	tags, err := r.tagService.GetTags(c.Request().Context(), c.QueryParams().Get("category"))
+	if err != nil {
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tags retrieved", tags)
+}
+
+// @Summary Get a tag by ID
+// @Description This endpoint retrieves a tag with its usage count by its ID.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag_id path string true "Tag ID to retrieve"
+// @Success 200 {object} SuccessResponse "Tag retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{tag_id} [get]
+func (r *tagRoutes) getByID(c echo.Context) error {
+	tagID := c.Param("tag_id")
+
+	tag, err := r.tagService.GetTagByID(c.Request().Context(), tagID)
+	if err != nil {
+		if errors.Is(err, service.ErrTagNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tag retrieved", tag)
+}
+
+// @Summary Update a tag
+// @Description This endpoint renames a tag or changes its category.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag_id path string true "Tag ID to update"
+// @Param input body entity.TagUpdate true "Tag update input"
+// @Success 200 {object} SuccessResponse "Tag updated successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input, tag not found or name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{tag_id} [put]
+func (r *tagRoutes) update(c echo.Context) error {
+	var input entity.TagUpdate
+	if err := c.Bind(&input); err != nil {
+		return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid request body"))
+	}
+	input.ID = c.Param("tag_id")
+
+	if input.Category != nil {
+		switch *input.Category {
+		case "genre", "mood", "tag":
+		default:
+			return newErrorResponse(c, http.StatusBadRequest, errors.New("invalid category"))
+		}
+	}
+
+	err := r.tagService.UpdateTag(c.Request().Context(), &input)
+	if err != nil {
+		if errors.Is(err, service.ErrTagNotFound) || errors.Is(err, service.ErrTagAlreadyExists) || errors.Is(err, service.ErrInvalidTagName) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tag updated", nil)
+}
+
+// @Summary Delete a tag
+// @Description This endpoint deletes a tag by its ID and detaches it from all songs.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag_id path string true "Tag ID to delete"
+// @Success 200 {object} SuccessResponse "Tag deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request - tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags/{tag_id} [delete]
+func (r *tagRoutes) delete(c echo.Context) error {
+	tagID := c.Param("tag_id")
+
+	err := r.tagService.DeleteTag(c.Request().Context(), tagID)
+	if err != nil {
+		if errors.Is(err, service.ErrTagNotFound) {
+			return newErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return newErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	return newSuccessResponse(c, "tag deleted", nil)
+}
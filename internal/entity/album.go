@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+)
+
+type Album struct {
+	ID          string     `db:"id" json:"id"`
+	Title       string     `db:"title" json:"title"`
+	GroupID     string     `db:"group_id" json:"groupId"`
+	GroupName   string     `json:"groupName"`
+	ReleaseDate *time.Time `db:"release_date" json:"releaseDate"`
+	Type        string     `db:"album_type" json:"type"`
+	TracksCount int        `json:"tracksCount"`
+	Songs       []Song     `json:"songs,omitempty"`
+}
+
+type AlbumUpdate struct {
+	ID          string  `json:"-"`
+	Title       *string `db:"title" json:"title"`
+	ReleaseDate *string `db:"release_date" json:"releaseDate"`
+	Type        *string `db:"album_type" json:"type"`
+}
+
+type AlbumFilter struct {
+	GroupID string
+	Title   string
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+)
+
+// ArchiveVersion is the version of the library archive format written by the export.
+// Version 2 archives lines of lyrics with their stanza; the verses of version
+// 1 include the blank lines between stanzas.
+const ArchiveVersion = 2
+
+const (
+	ArchiveRecordHeader = "header"
+	ArchiveRecordGroup  = "group"
+	ArchiveRecordAlbum  = "album"
+	ArchiveRecordTag    = "tag"
+	ArchiveRecordSong   = "song"
+	ArchiveRecordVerse  = "verse"
+)
+
+const (
+	RestorePolicySkip      = "skip"
+	RestorePolicyOverwrite = "overwrite"
+	RestorePolicyFail      = "fail"
+)
+
+type ArchiveHeader struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+type ArchiveGroup struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+type ArchiveAlbum struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	GroupID     string     `json:"groupId"`
+	ReleaseDate *time.Time `json:"releaseDate,omitempty"`
+	Type        string     `json:"type"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+type ArchiveTag struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type ArchiveSong struct {
+	ID                   string     `json:"id"`
+	Title                string     `json:"title"`
+	GroupID              string     `json:"groupId"`
+	ReleaseDate          *time.Time `json:"releaseDate,omitempty"`
+	ReleaseDatePrecision string     `json:"releaseDatePrecision,omitempty"`
+	Link                 string     `json:"link"`
+	AlbumID              *string    `json:"albumId,omitempty"`
+	TrackNumber          *int       `json:"trackNumber,omitempty"`
+	DiscNumber           *int       `json:"discNumber,omitempty"`
+	TagIDs               []string   `json:"tagIds,omitempty"`
+	CreatedAt            *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt            *time.Time `json:"updatedAt,omitempty"`
+}
+
+type ArchiveVerse struct {
+	ID           string  `json:"id"`
+	SongID       string  `json:"songId"`
+	Verse        string  `json:"verse"`
+	VerseNumber  int     `json:"verseNumber"`
+	StanzaNumber int     `json:"stanzaNumber,omitempty"`
+	StanzaLabel  *string `json:"stanzaLabel,omitempty"`
+}
+
+type RestoreCounts struct {
+	Restored int `json:"restored"`
+	Skipped  int `json:"skipped"`
+}
+
+type RestoreReport struct {
+	Version int           `json:"version"`
+	Policy  string        `json:"policy"`
+	Groups  RestoreCounts `json:"groups"`
+	Albums  RestoreCounts `json:"albums"`
+	Tags    RestoreCounts `json:"tags"`
+	Songs   RestoreCounts `json:"songs"`
+	Verses  RestoreCounts `json:"verses"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	EnrichmentStatusPending = "pending"
+	EnrichmentStatusRunning = "running"
+	EnrichmentStatusDone    = "done"
+	EnrichmentStatusFailed  = "failed"
+)
+
+// EnrichmentJob fills a song in with the details of the metadata provider.
+// A song has at most one job, requeueing reuses it. Claim identifies the
+// latest claim of the job: only the worker holding it records the outcome,
+// so that a worker whose lease ran out cannot overwrite that of the worker
+// that claimed the job again.
+type EnrichmentJob struct {
+	ID          string     `json:"id"`
+	SongID      string     `json:"songId"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	RunAt       time.Time  `json:"runAt"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	LastError   *string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Claim       string     `json:"-"`
+}
+
+type EnrichmentJobFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
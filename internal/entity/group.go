@@ -1,6 +1,8 @@
 package entity
 
 type Group struct {
-	ID   string `db:"id"`
-	Name string `db:"name"`
+	ID         string `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	SongsCount int    `json:"songsCount"`
+	Songs      []Song `json:"songs,omitempty"`
 }
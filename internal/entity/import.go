@@ -0,0 +1,49 @@
+package entity
+
+const (
+	ImportStatusCreated   = "created"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusFailed    = "failed"
+)
+
+const (
+	ImportEnrichAuto   = "auto"
+	ImportEnrichAlways = "always"
+	ImportEnrichNever  = "never"
+)
+
+type SongImportRow struct {
+	Group       string `json:"group"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"releaseDate"`
+	Link        string `json:"link"`
+	Lyrics      string `json:"lyrics"`
+}
+
+// SongImportOptions controls a bulk import. Enrich is one of the ImportEnrich
+// modes: auto only asks the external API about rows that supply none of
+// releaseDate, link and lyrics.
+type SongImportOptions struct {
+	Format    string
+	Enrich    string
+	DryRun    bool
+	BatchSize int
+}
+
+type SongImportResult struct {
+	Row    int    `json:"row"`
+	Group  string `json:"group"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	SongID string `json:"songId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type SongImportReport struct {
+	DryRun     bool               `json:"dryRun"`
+	Total      int                `json:"total"`
+	Created    int                `json:"created"`
+	Duplicates int                `json:"duplicates"`
+	Failed     int                `json:"failed"`
+	Results    []SongImportResult `json:"results"`
+}
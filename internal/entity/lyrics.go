@@ -1,8 +1,40 @@
 package entity
 
+// LyricsVerse is a line of the lyrics. Lines are numbered from 1 through the
+// whole song, and StanzaNumber is the number of the stanza holding the line.
 type LyricsVerse struct {
-	ID          string `db:"id"`
-	SongID      string `db:"song_id"`
-	Verse       string `db:"verse"`
-	VerseNumber int    `db:"verse_number"`
+	ID           string `db:"id"`
+	SongID       string `db:"song_id"`
+	Verse        string `db:"verse"`
+	VerseNumber  int    `db:"verse_number"`
+	StanzaNumber int    `db:"stanza_number"`
+}
+
+// LyricsStanza is a group of lines of the lyrics, set apart by blank lines in
+// their text. Label names the part of the song, such as verse, chorus or
+// bridge.
+type LyricsStanza struct {
+	ID           string  `db:"id"`
+	SongID       string  `db:"song_id"`
+	StanzaNumber int     `db:"stanza_number"`
+	Label        *string `db:"label"`
+	Verses       []LyricsVerse
+}
+
+// VerseCursor is the position of a line in the lyrics of a song, or of a
+// stanza when StanzaNumber is set. Lines are identified by VerseID rather
+// than by their number, which changes as lines are inserted before them.
+type VerseCursor struct {
+	SongID       string `json:"s"`
+	VerseID      string `json:"v,omitempty"`
+	StanzaNumber int    `json:"t,omitempty"`
+}
+
+// LyricsPage is a page of the lyrics of a song paginated with cursors, by
+// line or by stanza.
+type LyricsPage struct {
+	Verses     []LyricsVerse  `json:"verses,omitempty"`
+	Stanzas    []LyricsStanza `json:"stanzas,omitempty"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	PrevCursor string         `json:"prevCursor,omitempty"`
 }
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+// SongDetailCacheEntry is a cached lookup of the metadata provider. Found is
+// false for songs the provider did not know.
+type SongDetailCacheEntry struct {
+	Key       string
+	GroupName string
+	Title     string
+	Found     bool
+	Detail    SongDetail
+	ExpiresAt *time.Time
+}
+
+// MetadataCacheStats counts lookups since the start. Hits include those of
+// entries read from the database, which StoredHits counts on their own.
+type MetadataCacheStats struct {
+	Enabled       bool  `json:"enabled"`
+	Persistent    bool  `json:"persistent"`
+	Capacity      int   `json:"capacity"`
+	Entries       int   `json:"entries"`
+	StoredEntries int   `json:"storedEntries"`
+	Hits          int64 `json:"hits"`
+	NegativeHits  int64 `json:"negativeHits"`
+	StoredHits    int64 `json:"storedHits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+}
+
+type MetadataCachePurge struct {
+	Entries       int `json:"entries"`
+	StoredEntries int `json:"storedEntries"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+)
+
+type Playlist struct {
+	ID           string          `db:"id" json:"id"`
+	Name         string          `db:"name" json:"name"`
+	Description  *string         `db:"description" json:"description"`
+	EntriesCount int             `json:"entriesCount"`
+	Entries      []PlaylistEntry `json:"entries,omitempty"`
+	CreatedAt    time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time       `db:"updated_at" json:"updatedAt"`
+}
+
+type PlaylistEntry struct {
+	ID         string  `db:"id" json:"id"`
+	PlaylistID string  `db:"playlist_id" json:"-"`
+	SongID     string  `db:"song_id" json:"songId"`
+	Position   int     `db:"position" json:"position"`
+	Note       *string `db:"note" json:"note"`
+	Song       *Song   `json:"song,omitempty"`
+}
+
+type PlaylistUpdate struct {
+	ID          string  `json:"-"`
+	Name        *string `db:"name" json:"name"`
+	Description *string `db:"description" json:"description"`
+}
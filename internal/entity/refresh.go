@@ -0,0 +1,36 @@
+package entity
+
+const (
+	// RefreshModePreview reports the changes without storing them.
+	RefreshModePreview = "preview"
+	// RefreshModeFill stores the details only for fields the song lacks.
+	RefreshModeFill = "fill"
+	// RefreshModeApply stores every detail that differs.
+	RefreshModeApply = "apply"
+)
+
+const (
+	SongFieldReleaseDate = "releaseDate"
+	SongFieldLink        = "link"
+	SongFieldLyrics      = "lyrics"
+)
+
+// SongFieldChange is a field whose stored value differs from the one of the
+// metadata provider. Release dates are given as YYYY-MM-DD.
+type SongFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// SongRefresh is the outcome of looking a song up again. Changes lists the
+// differences the mode selected; they were stored when Applied is set.
+type SongRefresh struct {
+	SongID    string            `json:"songId"`
+	Title     string            `json:"title"`
+	GroupName string            `json:"groupName"`
+	Mode      string            `json:"mode"`
+	Changes   []SongFieldChange `json:"changes"`
+	Applied   bool              `json:"applied"`
+	Error     string            `json:"error,omitempty"`
+}
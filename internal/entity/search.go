@@ -0,0 +1,34 @@
+package entity
+
+// SongSearchResult is a song matching a lyrics search. Snippet is the best
+// matching part of its lyrics, matches highlighted with <b></b>, and Verses
+// the numbers of the verses that match.
+type SongSearchResult struct {
+	SongID    string  `json:"songId"`
+	Title     string  `json:"title"`
+	GroupName string  `json:"groupName"`
+	Rank      float64 `json:"rank"`
+	Snippet   string  `json:"snippet"`
+	Verses    []int   `json:"verses"`
+}
+
+// SongSuggestions are the song titles and groups most alike a query that may
+// be incomplete or misspelt. Score is the word similarity of the query to the
+// suggestion, from 0 to 1.
+type SongSuggestions struct {
+	Titles []TitleSuggestion `json:"titles"`
+	Groups []GroupSuggestion `json:"groups"`
+}
+
+type TitleSuggestion struct {
+	SongID    string  `json:"songId"`
+	Title     string  `json:"title"`
+	GroupName string  `json:"groupName"`
+	Score     float64 `json:"score"`
+}
+
+type GroupSuggestion struct {
+	GroupID string  `json:"groupId"`
+	Name    string  `json:"name"`
+	Score   float64 `json:"score"`
+}
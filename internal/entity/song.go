@@ -1,36 +1,150 @@
 package entity
 
 import (
+	"slices"
+	"strings"
 	"time"
 )
 
+// Song is a song of the library. How much of its release date is known, the
+// day, the month or only the year, is told by ReleaseDatePrecision;
+// ReleaseDate is then the first day of that period.
 type Song struct {
-	ID          string    `db:"id" json:"id"`
-	Title       string    `db:"title" json:"title"`
-	GroupID     string    `db:"group_id" json:",omitempty"`
-	GroupName   string    `json:"groupName"`
-	ReleaseDate time.Time `db:"releaseDate" json:"releaseDate"`
-	LyricsText  string    `json:"lyrics"`
-	Link        string    `db:"link" json:"link"`
+	ID                   string     `db:"id" json:"id"`
+	Title                string     `db:"title" json:"title"`
+	GroupID              string     `db:"group_id" json:",omitempty"`
+	GroupName            string     `json:"groupName"`
+	ReleaseDate          *time.Time `db:"releaseDate" json:"releaseDate"`
+	ReleaseDatePrecision string     `db:"release_date_precision" json:"releaseDatePrecision,omitempty"`
+	LyricsText           string     `json:"lyrics"`
+	Link                 string     `db:"link" json:"link"`
+	AlbumID              *string    `db:"album_id" json:"albumId,omitempty"`
+	AlbumTitle           *string    `json:"albumTitle,omitempty"`
+	TrackNumber          *int       `db:"track_number" json:"trackNumber,omitempty"`
+	DiscNumber           *int       `db:"disc_number" json:"discNumber,omitempty"`
+	Tags                 []Tag      `json:"tags"`
+	EnrichmentStatus     string     `db:"enrichment_status" json:"enrichmentStatus"`
+	CreatedAt            *time.Time `db:"created_at" json:"createdAt,omitempty"`
 }
 
 type SongUpdate struct {
-	ID          string  `json:"id"`
-	Title       *string `db:"title" json:"title"`
-	ReleaseDate *string `db:"release_date" json:"releaseDate"`
-	GroupName   *string `json:"groupName"`
-	GroupID     string
-	Link        *string `json:"link"`
-	Lyrics      *string `json:"lyrics"`
+	ID                   string  `json:"id"`
+	Title                *string `db:"title" json:"title"`
+	ReleaseDate          *string `db:"release_date" json:"releaseDate"`
+	ReleaseDatePrecision string  `json:"-"`
+	GroupName            *string `json:"groupName"`
+	GroupID              string
+	Link                 *string `json:"link"`
+	Lyrics               *string `json:"lyrics"`
+	AlbumID              *string `db:"album_id" json:"albumId"`
+	TrackNumber          *int    `db:"track_number" json:"trackNumber"`
+	DiscNumber           *int    `db:"disc_number" json:"discNumber"`
 }
 
+// SongFilter selects songs. With Fuzzy, Title and Group also match despite
+// typos, when their word similarity to the filter reaches SimilarityThreshold.
+// HasLyrics, when set, selects the songs with or without lyrics. Include
+// lists the details loaded along with the songs, every one of them when nil.
+// With After or Before only the songs listed after or before the cursor are,
+// and Offset is ignored.
 type SongFilter struct {
-	Title     string
-	Link      string
-	Group     string
-	Text      string
-	StartDate string
-	EndDate   string
-	Limit     int
-	Offset    int
+	IDs                 []string
+	Title               string
+	Link                string
+	Group               string
+	Fuzzy               bool
+	SimilarityThreshold float64
+	Text                string
+	HasLyrics           *bool
+	Album               string
+	AlbumID             string
+	Tag                 string
+	AnyTags             []string
+	AllTags             []string
+	StartDate           string
+	EndDate             string
+	Sort                []SongSort
+	Include             []string
+	After               *SongCursor
+	Before              *SongCursor
+	Limit               int
+	Offset              int
+}
+
+// Song sort fields. Songs are sorted by every key in turn, and then by ID so
+// that pages never overlap.
+const (
+	SongSortTitle       = "title"
+	SongSortGroup       = "group"
+	SongSortReleaseDate = "releaseDate"
+	SongSortCreatedAt   = "createdAt"
+)
+
+// SongSort is a sort key of the song listing. Songs without a release date
+// come last in either direction.
+type SongSort struct {
+	Field string
+	Desc  bool
+}
+
+// Details of the listed songs that may be left out.
+const (
+	SongIncludeLyrics = "lyrics"
+	SongIncludeTags   = "tags"
+)
+
+// Includes tells whether the detail is loaded along with the songs.
+func (f *SongFilter) Includes(detail string) bool {
+	return f.Include == nil || slices.Contains(f.Include, detail)
+}
+
+// DefaultSongSort lists songs by group, then by title.
+var DefaultSongSort = []SongSort{{Field: SongSortGroup}, {Field: SongSortTitle}}
+
+// FormatSongSort writes the sort keys the way the sort parameter takes them,
+// such as group,-title.
+func FormatSongSort(sort []SongSort) string {
+	keys := make([]string, 0, len(sort))
+	for _, key := range sort {
+		if key.Desc {
+			keys = append(keys, "-"+key.Field)
+		} else {
+			keys = append(keys, key.Field)
+		}
+	}
+
+	return strings.Join(keys, ",")
+}
+
+// SongCursor is the position of a song in the listing: the values of the
+// keys the listing is sorted by, Sort, and the ID of the song. A nil
+// ReleaseDate or CreatedAt stands for NULL.
+type SongCursor struct {
+	Sort        string     `json:"s"`
+	Title       string     `json:"t,omitempty"`
+	GroupName   string     `json:"g,omitempty"`
+	ReleaseDate *time.Time `json:"r,omitempty"`
+	CreatedAt   *time.Time `json:"c,omitempty"`
+	ID          string     `json:"i"`
+}
+
+// SongList is a page of the song listing with the total number of songs
+// matching the filter. Page and Limit are 0 when the listing is not paginated,
+// and Page is 0 too when it is paginated with cursors. NextCursor and
+// PrevCursor are given to the after and before parameters for the next and
+// the previous page, and are empty when there is none.
+type SongList struct {
+	Items      []Song `json:"items"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// SongDetail is what the external API knows about a song.
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
 }
@@ -0,0 +1,18 @@
+package entity
+
+type Tag struct {
+	ID       string `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	Category string `db:"category" json:"category"`
+}
+
+type TagUsage struct {
+	Tag
+	UsageCount int `json:"usageCount"`
+}
+
+type TagUpdate struct {
+	ID       string  `json:"-"`
+	Name     *string `db:"name" json:"name"`
+	Category *string `db:"category" json:"category"`
+}
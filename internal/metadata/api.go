@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/webapi"
+	"errors"
+	"fmt"
+)
+
+// APIProvider asks the external song info API.
+type APIProvider struct {
+	songInfo webapi.SongInfo
+}
+
+func NewAPIProvider(songInfo webapi.SongInfo) *APIProvider {
+	return &APIProvider{songInfo: songInfo}
+}
+
+func (p *APIProvider) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	songDetail, err := p.songInfo.GetSongDetail(ctx, groupName, title)
+	if err != nil {
+		if errors.Is(err, webapi.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, err
+	}
+
+	return songDetail, nil
+}
@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type chainLink struct {
+	name     string
+	provider Provider
+}
+
+// Chain asks its providers in order and merges their results: every field is
+// taken from the first provider that knows it. Providers after the one
+// completing the detail are not asked.
+type Chain struct {
+	links []chainLink
+}
+
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Add appends the provider to the chain, name is used in logs.
+func (c *Chain) Add(name string, provider Provider) *Chain {
+	c.links = append(c.links, chainLink{name: name, provider: provider})
+	return c
+}
+
+// GetSongDetail returns the merged detail when at least one provider knew the
+// song. Otherwise it returns the first failure other than ErrNotFound, or
+// ErrNotFound when every provider simply did not know the song.
+func (c *Chain) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	var (
+		merged   entity.SongDetail
+		found    bool
+		firstErr error
+	)
+
+	for _, link := range c.links {
+		songDetail, err := link.provider.GetSongDetail(ctx, groupName, title)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !errors.Is(err, ErrNotFound) {
+				log.Warnf("metadata provider %s failed: %s", link.name, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+			continue
+		}
+
+		found = true
+		mergeSongDetail(&merged, songDetail)
+		if merged.ReleaseDate != "" && merged.Text != "" && merged.Link != "" {
+			break
+		}
+	}
+
+	if found {
+		return &merged, nil
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return nil, ErrNotFound
+}
+
+func mergeSongDetail(dst, src *entity.SongDetail) {
+	if dst.ReleaseDate == "" {
+		dst.ReleaseDate = src.ReleaseDate
+	}
+	if dst.Text == "" {
+		dst.Text = src.Text
+	}
+	if dst.Link == "" {
+		dst.Link = src.Link
+	}
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fixtureEntry is a song in a fixture file. A file holds either a single
+// entry or a list of them.
+type fixtureEntry struct {
+	Group       string `json:"group" yaml:"group"`
+	Song        string `json:"song" yaml:"song"`
+	ReleaseDate string `json:"releaseDate" yaml:"releaseDate"`
+	Text        string `json:"text" yaml:"text"`
+	Link        string `json:"link" yaml:"link"`
+}
+
+// FixtureProvider serves song details read from .json, .yaml and .yml files.
+// Songs are matched by group and title, ignoring case.
+type FixtureProvider struct {
+	songs map[string]entity.SongDetail
+}
+
+// NewFixtureProvider reads the fixture file at path, or every fixture file of
+// the directory at path. Later files override the songs of earlier ones, in
+// the lexical order of their names.
+func NewFixtureProvider(path string) (*FixtureProvider, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture directory: %w", err)
+		}
+
+		paths = paths[:0]
+		for _, file := range files {
+			if !file.IsDir() && fixtureUnmarshaler(file.Name()) != nil {
+				paths = append(paths, filepath.Join(path, file.Name()))
+			}
+		}
+	}
+
+	p := &FixtureProvider{songs: make(map[string]entity.SongDetail)}
+	for _, path := range paths {
+		entries, err := readFixtureFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.Group == "" || entry.Song == "" {
+				return nil, fmt.Errorf("fixture file %s: group and song are required", filepath.Base(path))
+			}
+			p.songs[Key(entry.Group, entry.Song)] = entity.SongDetail{
+				ReleaseDate: entry.ReleaseDate,
+				Text:        entry.Text,
+				Link:        entry.Link,
+			}
+		}
+	}
+
+	return p, nil
+}
+
+func fixtureUnmarshaler(name string) func([]byte, interface{}) error {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return json.Unmarshal
+	case ".yaml", ".yml":
+		return yaml.Unmarshal
+	}
+
+	return nil
+}
+
+func readFixtureFile(path string) ([]fixtureEntry, error) {
+	unmarshal := fixtureUnmarshaler(path)
+	if unmarshal == nil {
+		return nil, fmt.Errorf("fixture file %s: unsupported extension, expected .json, .yaml or .yml", filepath.Base(path))
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	var entries []fixtureEntry
+	if err := unmarshal(content, &entries); err == nil {
+		return entries, nil
+	}
+
+	var entry fixtureEntry
+	if err := unmarshal(content, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture file %s: %w", filepath.Base(path), err)
+	}
+
+	return []fixtureEntry{entry}, nil
+}
+
+func (p *FixtureProvider) GetSongDetail(_ context.Context, groupName, title string) (*entity.SongDetail, error) {
+	songDetail, ok := p.songs[Key(groupName, title)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &songDetail, nil
+}
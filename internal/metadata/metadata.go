@@ -0,0 +1,31 @@
+// Package metadata holds the sources songs are enriched from: the external
+// song info API, a directory of fixture files and a provider that knows
+// nothing. Several providers can be chained, their results being merged field
+// by field.
+package metadata
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"errors"
+	"strings"
+)
+
+// ErrNotFound means the provider knows nothing about the song.
+var ErrNotFound = errors.New("song metadata not found")
+
+// Provider returns what it knows about a song. Fields it does not know are
+// left empty.
+type Provider interface {
+	GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error)
+}
+
+// Key identifies a song regardless of case and spacing: the group name and
+// title are lowercased, trimmed and their runs of whitespace collapsed.
+func Key(groupName, title string) string {
+	return normalize(groupName) + "\x00" + normalize(title)
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
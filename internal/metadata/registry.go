@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ProviderAPI     = "api"
+	ProviderFixture = "fixture"
+	ProviderNone    = "none"
+)
+
+// Factory creates a provider. It is only called for providers that are
+// configured, so unused providers need no configuration.
+type Factory func() (Provider, error)
+
+// Registry creates providers by name.
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+func (r *Registry) Register(name string, factory Factory) {
+	r.factories[strings.ToLower(name)] = factory
+}
+
+// Names returns the names of the registered providers, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Build creates the named providers. A single provider is returned as is,
+// several are chained in the given order.
+func (r *Registry) Build(names []string) (Provider, error) {
+	chain := NewChain()
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		factory, ok := r.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown metadata provider %q, expected one of %s", name, strings.Join(r.Names(), ", "))
+		}
+
+		provider, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metadata provider %s: %w", name, err)
+		}
+		chain.Add(name, provider)
+	}
+
+	switch len(chain.links) {
+	case 0:
+		return nil, fmt.Errorf("no metadata provider configured")
+	case 1:
+		return chain.links[0].provider, nil
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,116 @@
+// Package mockinfo serves a stand-in for the external song info API, answering
+// GET /info?group=&song= from fixtures, so that the service can be developed
+// and demonstrated offline.
+package mockinfo
+
+import (
+	"effective_mobile_tz/internal/metadata"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	// Latency delays every response, by up to Jitter more.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate is the share of requests, between 0 and 1, answered with
+	// ErrorStatus instead of the song.
+	ErrorRate   float64
+	ErrorStatus int
+}
+
+type handler struct {
+	songs *metadata.FixtureProvider
+	cfg   Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewHandler returns the handler of the mock API. Songs missing from the
+// fixtures are answered with 404.
+func NewHandler(songs *metadata.FixtureProvider, cfg Config) http.Handler {
+	if cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusInternalServerError
+	}
+
+	h := &handler{
+		songs: songs,
+		cfg:   cfg,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", h.info)
+
+	return mux
+}
+
+func (h *handler) info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	delay, fail := h.draw()
+	select {
+	case <-r.Context().Done():
+		return
+	case <-time.After(delay):
+	}
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+	log.Debugf("mockinfo: %s - %s", group, song)
+
+	if fail {
+		writeError(w, h.cfg.ErrorStatus, "injected error")
+		return
+	}
+	if group == "" || song == "" {
+		writeError(w, http.StatusBadRequest, "group and song are required")
+		return
+	}
+
+	songDetail, err := h.songs.GetSongDetail(r.Context(), group, song)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "song not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(songDetail); err != nil {
+		log.Errorf("mockinfo: failed to write response: %s", err)
+	}
+}
+
+// draw picks the delay of a response and whether it fails.
+func (h *handler) draw() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delay := h.cfg.Latency
+	if h.cfg.Jitter > 0 {
+		delay += time.Duration(h.rand.Int63n(int64(h.cfg.Jitter) + 1))
+	}
+
+	return delay, h.cfg.ErrorRate > 0 && h.rand.Float64() < h.cfg.ErrorRate
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{Error: message})
+}
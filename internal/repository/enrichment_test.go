@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/internal/repository/repotest"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestEnrichmentClaim lets the lease of a claimed job run out: once the job
+// is claimed again, only the second claim records its outcome.
+func TestEnrichmentClaim(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Group", "Song")
+		if err := backend.CreateEnrichmentJob(ctx, songID); err != nil {
+			t.Fatal(err)
+		}
+
+		first := claimJob(t, backend, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		second := claimJob(t, backend, time.Minute)
+		if second.ID != first.ID || second.Claim == first.Claim || second.Attempts != 2 {
+			t.Fatalf("got claims %+v and %+v, want the job claimed twice", first, second)
+		}
+		if jobs, err := backend.ClaimEnrichmentJobs(ctx, 1, time.Minute); err != nil || len(jobs) != 0 {
+			t.Fatalf("got jobs %+v, %v while the job is held, want none", jobs, err)
+		}
+
+		if err := backend.FailEnrichmentJob(ctx, first.ID, first.Claim, "lease ran out"); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v recording the outcome under the lost claim, want %v", err, repoerrors.ErrNotFound)
+		}
+		if err := backend.CompleteEnrichmentJob(ctx, second.ID, second.Claim); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.CompleteEnrichmentJob(ctx, second.ID, second.Claim); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v recording the outcome twice, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		job, err := backend.GetEnrichmentJobBySongID(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != entity.EnrichmentStatusDone || job.LastError != nil {
+			t.Errorf("got job %+v, want it done by the second claim", job)
+		}
+		song, err := backend.GetSongByID(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.EnrichmentStatus != entity.EnrichmentStatusDone {
+			t.Errorf("got song enrichment %q, want %q", song.EnrichmentStatus, entity.EnrichmentStatusDone)
+		}
+	})
+}
+
+// TestEnrichmentReset queues the enrichment of a song again while it runs:
+// the claim of the running job is dropped.
+func TestEnrichmentReset(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Group", "Song")
+		if err := backend.CreateEnrichmentJob(ctx, songID); err != nil {
+			t.Fatal(err)
+		}
+
+		job := claimJob(t, backend, time.Minute)
+		if err := backend.CreateEnrichmentJob(ctx, songID); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.RetryEnrichmentJob(ctx, job.ID, job.Claim, time.Hour, "failed"); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v recording the outcome of the reset job, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		// the job queued again is due at once
+		claimJob(t, backend, time.Minute)
+	})
+}
+
+func claimJob(t *testing.T, backend *repotest.Backend, lease time.Duration) entity.EnrichmentJob {
+	t.Helper()
+
+	jobs, err := backend.ClaimEnrichmentJobs(context.Background(), 1, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("claimed %d jobs, want 1", len(jobs))
+	}
+
+	return jobs[0]
+}
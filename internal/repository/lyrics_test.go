@@ -0,0 +1,211 @@
+package repository_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/internal/repository/repotest"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLyricsStanzas(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Group", "Song", []string{"One", "Two"}, []string{"Three"})
+
+		label := "Chorus"
+		if err := backend.UpdateLyricsStanza(ctx, songID, 2, &label); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.UpdateLyricsStanza(ctx, songID, 3, &label); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v labelling a missing stanza, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		stanzas, err := backend.GetLyricsStanzas(ctx, songID, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stanzas) != 2 {
+			t.Fatalf("got %d stanzas, want 2", len(stanzas))
+		}
+		if stanzas[0].Label != nil || stanzas[1].Label == nil || *stanzas[1].Label != label {
+			t.Errorf("got labels %v and %v, want none and %q", stanzas[0].Label, stanzas[1].Label, label)
+		}
+		if got, want := lines(stanzas[0].Verses), []string{"One", "Two"}; !slices.Equal(got, want) {
+			t.Errorf("got the first stanza %q, want %q", got, want)
+		}
+
+		stanzas, err = backend.GetLyricsStanzas(ctx, songID, 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stanzas) != 1 || stanzas[0].StanzaNumber != 2 {
+			t.Errorf("got stanzas %+v, want the second only", stanzas)
+		}
+	})
+}
+
+// TestLyricsEdits inserts, moves and deletes lines, which keeps the line
+// numbers of the song contiguous.
+func TestLyricsEdits(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Group", "Song", []string{"One", "Two"}, []string{"Three"})
+
+		if err := backend.InsertLyricsVerse(ctx, newVerse(songID, 2, 1, "One and a half")); err != nil {
+			t.Fatal(err)
+		}
+		assertLines(t, backend, songID, "One", "One and a half", "Two", "Three")
+
+		if err := backend.MoveLyricsVerse(ctx, songID, 1, 4, 2); err != nil {
+			t.Fatal(err)
+		}
+		assertLines(t, backend, songID, "One and a half", "Two", "Three", "One")
+
+		if err := backend.DeleteLyricsVerse(ctx, songID, 2); err != nil {
+			t.Fatal(err)
+		}
+		assertLines(t, backend, songID, "One and a half", "Three", "One")
+		if err := backend.DeleteLyricsVerse(ctx, songID, 4); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v deleting a missing line, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		if err := backend.UpdateLyricsVerse(ctx, songID, 3, "Last"); err != nil {
+			t.Fatal(err)
+		}
+		verse, err := backend.GetLyricsVerse(ctx, songID, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verse.Verse != "Last" || verse.StanzaNumber != 2 {
+			t.Errorf("got line %q of stanza %d, want %q of stanza 2", verse.Verse, verse.StanzaNumber, "Last")
+		}
+
+		if err := backend.DeleteLyrics(ctx, songID); err != nil {
+			t.Fatal(err)
+		}
+		if count, err := backend.CountLyricsVerses(ctx, songID); err != nil || count != 0 {
+			t.Errorf("got %d lines, %v after deleting the lyrics, want none", count, err)
+		}
+	})
+}
+
+// TestLyricsPages reads the lines of a song by offset, around a line and by
+// ID.
+func TestLyricsPages(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Group", "Song", []string{"One", "Two"}, []string{"Three", "Four"})
+
+		page, err := backend.GetPaginatedLyrics(ctx, songID, 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := lines(page), []string{"Two", "Three"}; !slices.Equal(got, want) {
+			t.Errorf("got page %q, want %q", got, want)
+		}
+
+		page, err = backend.GetLyricsAfter(ctx, songID, 2, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := lines(page), []string{"Three", "Four"}; !slices.Equal(got, want) {
+			t.Errorf("got the lines after the second %q, want %q", got, want)
+		}
+
+		page, err = backend.GetLyricsBefore(ctx, songID, 4, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := lines(page), []string{"Two", "Three"}; !slices.Equal(got, want) {
+			t.Errorf("got the lines before the fourth %q, want %q", got, want)
+		}
+
+		// a line is found by its ID once lines inserted before it renumber it
+		third := page[1]
+		if err := backend.InsertLyricsVerse(ctx, newVerse(songID, 1, 1, "Zero")); err != nil {
+			t.Fatal(err)
+		}
+		verse, err := backend.GetLyricsVerseByID(ctx, songID, third.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verse.Verse != "Three" || verse.VerseNumber != 4 {
+			t.Errorf("got line %d %q, want 4 %q", verse.VerseNumber, verse.Verse, "Three")
+		}
+		if _, err := backend.GetLyricsVerseByID(ctx, addSong(t, backend, "Group", "Other"), third.ID); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v reading the line from another song, want %v", err, repoerrors.ErrNotFound)
+		}
+	})
+}
+
+// BenchmarkGetLyricsBySongIDs loads the lyrics of a page of 100 songs one
+// song at a time, as the listing did, and all at once.
+func BenchmarkGetLyricsBySongIDs(b *testing.B) {
+	const songs = 100
+
+	for _, setup := range []struct {
+		name       string
+		newBackend func(testing.TB) *repotest.Backend
+	}{
+		{name: "memory", newBackend: repotest.Memory},
+		{name: "postgres", newBackend: repotest.Postgres},
+	} {
+		b.Run(setup.name, func(b *testing.B) {
+			backend := setup.newBackend(b)
+			ctx := context.Background()
+
+			var songIDs []string
+			for ind := 0; ind < songs; ind++ {
+				songIDs = append(songIDs, addSong(b, backend, "Group", fmt.Sprintf("Song %d", ind),
+					[]string{"First line", "Second line", "Third line", "Fourth line"},
+					[]string{"Fifth line", "Sixth line", "Seventh line", "Eighth line"}))
+			}
+
+			b.Run("per song", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for _, songID := range songIDs {
+						if _, err := backend.GetLyricsBySongIDs(ctx, []string{songID}); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+			b.Run("batched", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := backend.GetLyricsBySongIDs(ctx, songIDs); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func newVerse(songID string, verseNumber, stanzaNumber int, line string) *entity.LyricsVerse {
+	return &entity.LyricsVerse{SongID: songID, Verse: line, VerseNumber: verseNumber, StanzaNumber: stanzaNumber}
+}
+
+func assertLines(t *testing.T, backend *repotest.Backend, songID string, want ...string) {
+	t.Helper()
+
+	verses, err := backend.GetPaginatedLyrics(context.Background(), songID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lines(verses); !slices.Equal(got, want) {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+}
+
+func lines(verses []entity.LyricsVerse) []string {
+	lines := []string{}
+	for _, verse := range verses {
+		lines = append(lines, verse.Verse)
+	}
+
+	return lines
+}
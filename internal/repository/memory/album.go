@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type AlbumMemory struct {
+	*Store
+}
+
+func NewAlbumMemory(store *Store) *AlbumMemory {
+	return &AlbumMemory{Store: store}
+}
+
+func (a *AlbumMemory) CreateAlbum(ctx context.Context, album *entity.Album) (string, error) {
+	albumID := newID()
+
+	err := a.update(ctx, func(d *data) error {
+		if err := d.requireGroup(album.GroupID); err != nil {
+			return err
+		}
+		if d.albumByTitle(album.Title, album.GroupID) != nil {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		now := time.Now()
+		d.albums[albumID] = albumRow{
+			ID:          albumID,
+			Title:       album.Title,
+			GroupID:     album.GroupID,
+			ReleaseDate: copyTime(album.ReleaseDate),
+			Type:        album.Type,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return albumID, nil
+}
+
+func (a *AlbumMemory) GetAlbums(ctx context.Context, filter *entity.AlbumFilter) ([]entity.Album, error) {
+	var albums []entity.Album
+
+	err := a.view(ctx, func(d *data) error {
+		for _, row := range d.albums {
+			if filter.GroupID != "" && row.GroupID != filter.GroupID {
+				continue
+			}
+			if filter.Title != "" && !containsFold(row.Title, filter.Title) {
+				continue
+			}
+			albums = append(albums, d.album(row))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(albums, func(a, b entity.Album) int {
+		return cmp.Or(cmp.Compare(a.GroupName, b.GroupName), compareDates(a.ReleaseDate, b.ReleaseDate), cmp.Compare(a.Title, b.Title))
+	})
+
+	return albums, nil
+}
+
+func (a *AlbumMemory) GetAlbumByID(ctx context.Context, albumID string) (*entity.Album, error) {
+	var album entity.Album
+
+	err := a.view(ctx, func(d *data) error {
+		row, ok := d.albums[albumID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		album = d.album(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &album, nil
+}
+
+func (a *AlbumMemory) UpdateAlbum(ctx context.Context, update *entity.AlbumUpdate) error {
+	if update.Title == nil && update.ReleaseDate == nil && update.Type == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	var releaseDate *time.Time
+	if update.ReleaseDate != nil && *update.ReleaseDate != "" {
+		date, err := parseDate(*update.ReleaseDate)
+		if err != nil {
+			return fmt.Errorf("failed to update album with ID %s: invalid date %q", update.ID, *update.ReleaseDate)
+		}
+		releaseDate = &date
+	}
+
+	return a.update(ctx, func(d *data) error {
+		row, ok := d.albums[update.ID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		if update.Title != nil {
+			row.Title = *update.Title
+		}
+		if update.ReleaseDate != nil {
+			row.ReleaseDate = releaseDate
+		}
+		if update.Type != nil {
+			row.Type = *update.Type
+		}
+
+		if other := d.albumByTitle(row.Title, row.GroupID); other != nil && other.ID != row.ID {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		row.UpdatedAt = time.Now()
+		d.albums[row.ID] = row
+		return nil
+	})
+}
+
+func (a *AlbumMemory) DeleteAlbum(ctx context.Context, albumID string) error {
+	return a.update(ctx, func(d *data) error {
+		if _, ok := d.albums[albumID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		d.deleteAlbum(albumID)
+		return nil
+	})
+}
+
+func (d *data) album(row albumRow) entity.Album {
+	album := entity.Album{
+		ID:          row.ID,
+		Title:       row.Title,
+		GroupID:     row.GroupID,
+		GroupName:   d.groups[row.GroupID].Name,
+		ReleaseDate: copyTime(row.ReleaseDate),
+		Type:        row.Type,
+	}
+	for _, song := range d.songs {
+		if song.AlbumID != nil && *song.AlbumID == row.ID {
+			album.TracksCount++
+		}
+	}
+
+	return album
+}
+
+func (d *data) albumByTitle(title, groupID string) *albumRow {
+	for _, row := range d.albums {
+		if row.Title == title && row.GroupID == groupID {
+			return &row
+		}
+	}
+
+	return nil
+}
+
+// deleteAlbum removes the album and detaches its songs.
+func (d *data) deleteAlbum(albumID string) {
+	for id, song := range d.songs {
+		if song.AlbumID != nil && *song.AlbumID == albumID {
+			song.AlbumID = nil
+			d.songs[id] = song
+		}
+	}
+	delete(d.albums, albumID)
+}
+
+func (d *data) requireAlbum(albumID string) error {
+	if _, ok := d.albums[albumID]; !ok {
+		return fmt.Errorf("album %s: %w", albumID, repoerrors.ErrNotFound)
+	}
+
+	return nil
+}
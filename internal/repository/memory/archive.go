@@ -0,0 +1,403 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/pkg/partialdate"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ArchiveMemory reads the whole library for export and writes archived
+// records back, keeping their IDs.
+type ArchiveMemory struct {
+	*Store
+}
+
+func NewArchiveMemory(store *Store) *ArchiveMemory {
+	return &ArchiveMemory{Store: store}
+}
+
+func (a *ArchiveMemory) ExportGroups(ctx context.Context, fn func(group *entity.ArchiveGroup) error) error {
+	var groups []entity.ArchiveGroup
+	_ = a.view(ctx, func(d *data) error {
+		for _, row := range d.groups {
+			groups = append(groups, entity.ArchiveGroup{ID: row.ID, Name: row.Name, CreatedAt: copyTime(&row.CreatedAt)})
+		}
+		return nil
+	})
+
+	slices.SortFunc(groups, func(a, b entity.ArchiveGroup) int {
+		return cmp.Or(a.CreatedAt.Compare(*b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return each(groups, fn)
+}
+
+func (a *ArchiveMemory) ExportAlbums(ctx context.Context, fn func(album *entity.ArchiveAlbum) error) error {
+	var albums []entity.ArchiveAlbum
+	_ = a.view(ctx, func(d *data) error {
+		for _, row := range d.albums {
+			albums = append(albums, entity.ArchiveAlbum{
+				ID:          row.ID,
+				Title:       row.Title,
+				GroupID:     row.GroupID,
+				ReleaseDate: copyTime(row.ReleaseDate),
+				Type:        row.Type,
+				CreatedAt:   copyTime(&row.CreatedAt),
+			})
+		}
+		return nil
+	})
+
+	slices.SortFunc(albums, func(a, b entity.ArchiveAlbum) int {
+		return cmp.Or(a.CreatedAt.Compare(*b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return each(albums, fn)
+}
+
+func (a *ArchiveMemory) ExportTags(ctx context.Context, fn func(tag *entity.ArchiveTag) error) error {
+	var rows []tagRow
+	_ = a.view(ctx, func(d *data) error {
+		for _, row := range d.tags {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+
+	slices.SortFunc(rows, func(a, b tagRow) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	tags := make([]entity.ArchiveTag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, entity.ArchiveTag{ID: row.ID, Name: row.Name, Category: row.Category})
+	}
+
+	return each(tags, fn)
+}
+
+func (a *ArchiveMemory) ExportSongs(ctx context.Context, fn func(song *entity.ArchiveSong) error) error {
+	var songs []entity.ArchiveSong
+	_ = a.view(ctx, func(d *data) error {
+		for _, row := range d.songs {
+			song := entity.ArchiveSong{
+				ID:                   row.ID,
+				Title:                row.Title,
+				GroupID:              row.GroupID,
+				ReleaseDate:          copyTime(row.ReleaseDate),
+				ReleaseDatePrecision: row.ReleaseDatePrecision,
+				Link:                 row.Link,
+				AlbumID:              copyString(row.AlbumID),
+				TrackNumber:          copyInt(row.TrackNumber),
+				DiscNumber:           copyInt(row.DiscNumber),
+				TagIDs:               []string{},
+				CreatedAt:            copyTime(&row.CreatedAt),
+				UpdatedAt:            copyTime(&row.UpdatedAt),
+			}
+			for key := range d.songTags {
+				if key.SongID == row.ID {
+					song.TagIDs = append(song.TagIDs, key.TagID)
+				}
+			}
+			slices.Sort(song.TagIDs)
+			songs = append(songs, song)
+		}
+		return nil
+	})
+
+	slices.SortFunc(songs, func(a, b entity.ArchiveSong) int {
+		return cmp.Or(a.CreatedAt.Compare(*b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return each(songs, fn)
+}
+
+func (a *ArchiveMemory) ExportVerses(ctx context.Context, fn func(verse *entity.ArchiveVerse) error) error {
+	var verses []entity.ArchiveVerse
+	_ = a.view(ctx, func(d *data) error {
+		for _, row := range d.verses {
+			stanza := d.stanzas[row.StanzaID]
+			verses = append(verses, entity.ArchiveVerse{
+				ID:           row.ID,
+				SongID:       row.SongID,
+				Verse:        row.Verse,
+				VerseNumber:  row.VerseNumber,
+				StanzaNumber: stanza.StanzaNumber,
+				StanzaLabel:  copyString(stanza.Label),
+			})
+		}
+		return nil
+	})
+
+	slices.SortFunc(verses, func(a, b entity.ArchiveVerse) int {
+		return cmp.Or(cmp.Compare(a.SongID, b.SongID), cmp.Compare(a.VerseNumber, b.VerseNumber))
+	})
+
+	return each(verses, fn)
+}
+
+// RestoreGroup writes the group according to the restore policy. It returns
+// the ID the group has in the store, which differs from the archived one when
+// an existing group of the same name was kept or overwritten, and whether the
+// archived group was written.
+func (a *ArchiveMemory) RestoreGroup(ctx context.Context, group *entity.ArchiveGroup, policy string) (string, bool, error) {
+	var groupID string
+	var written bool
+
+	err := a.update(ctx, func(d *data) error {
+		var clash *groupRow
+		if other := d.groupByName(group.Name); other != nil && other.ID != group.ID {
+			clash = other
+		}
+
+		var err error
+		groupID, written, err = resolveRestore(d.groups, group.ID, clash, func(row groupRow) string { return row.ID }, policy)
+		if err != nil || !written {
+			return err
+		}
+
+		createdAt := time.Now()
+		if existing, ok := d.groups[groupID]; ok {
+			createdAt = existing.CreatedAt
+		} else if group.CreatedAt != nil {
+			createdAt = *group.CreatedAt
+		}
+
+		d.groups[groupID] = groupRow{ID: groupID, Name: group.Name, CreatedAt: createdAt}
+		return nil
+	})
+
+	return groupID, written, err
+}
+
+func (a *ArchiveMemory) RestoreAlbum(ctx context.Context, album *entity.ArchiveAlbum, policy string) (string, bool, error) {
+	var albumID string
+	var written bool
+
+	err := a.update(ctx, func(d *data) error {
+		var clash *albumRow
+		if other := d.albumByTitle(album.Title, album.GroupID); other != nil && other.ID != album.ID {
+			clash = other
+		}
+
+		var err error
+		albumID, written, err = resolveRestore(d.albums, album.ID, clash, func(row albumRow) string { return row.ID }, policy)
+		if err != nil || !written {
+			return err
+		}
+		if err := d.requireGroup(album.GroupID); err != nil {
+			return repoerrors.ErrNotFound
+		}
+
+		now := time.Now()
+		createdAt := now
+		if existing, ok := d.albums[albumID]; ok {
+			createdAt = existing.CreatedAt
+		} else if album.CreatedAt != nil {
+			createdAt = *album.CreatedAt
+		}
+
+		d.albums[albumID] = albumRow{
+			ID:          albumID,
+			Title:       album.Title,
+			GroupID:     album.GroupID,
+			ReleaseDate: copyTime(album.ReleaseDate),
+			Type:        album.Type,
+			CreatedAt:   createdAt,
+			UpdatedAt:   now,
+		}
+		return nil
+	})
+
+	return albumID, written, err
+}
+
+func (a *ArchiveMemory) RestoreTag(ctx context.Context, tag *entity.ArchiveTag, policy string) (string, bool, error) {
+	var tagID string
+	var written bool
+
+	err := a.update(ctx, func(d *data) error {
+		var clash *tagRow
+		if other := d.tagByName(tag.Name); other != nil && other.ID != tag.ID {
+			clash = other
+		}
+
+		var err error
+		tagID, written, err = resolveRestore(d.tags, tag.ID, clash, func(row tagRow) string { return row.ID }, policy)
+		if err != nil || !written {
+			return err
+		}
+
+		createdAt := time.Now()
+		if existing, ok := d.tags[tagID]; ok {
+			createdAt = existing.CreatedAt
+		}
+
+		d.tags[tagID] = tagRow{ID: tagID, Name: tag.Name, Category: tag.Category, CreatedAt: createdAt}
+		return nil
+	})
+
+	return tagID, written, err
+}
+
+// RestoreSong writes the song according to the restore policy. A written song
+// gets its tags replaced by song.TagIDs and loses its lyrics, which are
+// restored verse by verse afterwards.
+func (a *ArchiveMemory) RestoreSong(ctx context.Context, song *entity.ArchiveSong, policy string) (string, bool, error) {
+	var songID string
+	var written bool
+
+	err := a.update(ctx, func(d *data) error {
+		var clash *songRow
+		if other := d.songByTitle(song.Title, song.GroupID); other != nil && other.ID != song.ID {
+			clash = other
+		}
+
+		var err error
+		songID, written, err = resolveRestore(d.songs, song.ID, clash, func(row songRow) string { return row.ID }, policy)
+		if err != nil || !written {
+			return err
+		}
+		if _, ok := d.groups[song.GroupID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+		if song.AlbumID != nil {
+			if _, ok := d.albums[*song.AlbumID]; !ok {
+				return repoerrors.ErrNotFound
+			}
+		}
+		for _, tagID := range song.TagIDs {
+			if _, ok := d.tags[tagID]; !ok {
+				return fmt.Errorf("failed to restore tags of song %s: tag %s: %w", song.ID, tagID, repoerrors.ErrNotFound)
+			}
+		}
+
+		now := time.Now()
+		row := songRow{
+			ID:                   songID,
+			Title:                song.Title,
+			GroupID:              song.GroupID,
+			ReleaseDate:          copyTime(song.ReleaseDate),
+			ReleaseDatePrecision: song.ReleaseDatePrecision,
+			Link:                 song.Link,
+			AlbumID:              copyString(song.AlbumID),
+			TrackNumber:          copyInt(song.TrackNumber),
+			DiscNumber:           copyInt(song.DiscNumber),
+			EnrichmentStatus:     entity.EnrichmentStatusDone,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}
+		if row.ReleaseDatePrecision == "" {
+			row.ReleaseDatePrecision = string(partialdate.Day)
+		}
+		if existing, ok := d.songs[songID]; ok {
+			row.CreatedAt = existing.CreatedAt
+			row.EnrichmentStatus = existing.EnrichmentStatus
+		} else if song.CreatedAt != nil {
+			row.CreatedAt = *song.CreatedAt
+		}
+		if song.UpdatedAt != nil {
+			row.UpdatedAt = *song.UpdatedAt
+		}
+		d.songs[songID] = row
+
+		d.deleteLyrics(songID)
+		for key := range d.songTags {
+			if key.SongID == songID {
+				delete(d.songTags, key)
+			}
+		}
+		for _, tagID := range song.TagIDs {
+			d.songTags[songTagKey{SongID: songID, TagID: tagID}] = now
+		}
+		return nil
+	})
+
+	return songID, written, err
+}
+
+// RestoreVerse writes the line into its stanza, which the first line of the
+// stanza creates.
+func (a *ArchiveMemory) RestoreVerse(ctx context.Context, verse *entity.ArchiveVerse) error {
+	return a.update(ctx, func(d *data) error {
+		if _, ok := d.songs[verse.SongID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+		for _, row := range d.verses {
+			if row.ID != verse.ID && row.SongID == verse.SongID && row.VerseNumber == verse.VerseNumber {
+				return repoerrors.ErrAlreadyExists
+			}
+		}
+
+		createdAt := time.Now()
+		if existing, ok := d.verses[verse.ID]; ok {
+			createdAt = existing.CreatedAt
+		}
+
+		stanza := d.stanzaByNumber(verse.SongID, verse.StanzaNumber)
+		if stanza == nil {
+			stanza = &stanzaRow{
+				ID:           newID(),
+				SongID:       verse.SongID,
+				StanzaNumber: verse.StanzaNumber,
+				Label:        copyString(verse.StanzaLabel),
+				CreatedAt:    createdAt,
+			}
+			d.stanzas[stanza.ID] = *stanza
+		}
+
+		d.verses[verse.ID] = verseRow{
+			ID:          verse.ID,
+			SongID:      verse.SongID,
+			StanzaID:    stanza.ID,
+			Verse:       verse.Verse,
+			VerseNumber: verse.VerseNumber,
+			CreatedAt:   createdAt,
+		}
+		return nil
+	})
+}
+
+// resolveRestore decides like ArchivePostgres.restore whether an archived
+// record with the given ID is written. clash is an existing record with
+// another ID and the same unique key. Under the skip policy the ID of the
+// existing record is returned, preferring the one with the same ID; under the
+// overwrite policy the clashing record is the one overwritten.
+func resolveRestore[T any](rows map[string]T, id string, clash *T, rowID func(row T) string, policy string) (string, bool, error) {
+	_, exists := rows[id]
+
+	switch policy {
+	case entity.RestorePolicySkip:
+		if exists {
+			return id, false, nil
+		}
+		if clash != nil {
+			return rowID(*clash), false, nil
+		}
+	case entity.RestorePolicyOverwrite:
+		if clash != nil {
+			return rowID(*clash), true, nil
+		}
+	default:
+		if exists || clash != nil {
+			return "", false, repoerrors.ErrAlreadyExists
+		}
+	}
+
+	return id, true, nil
+}
+
+func each[T any](items []T, fn func(item *T) error) error {
+	for ind := range items {
+		if err := fn(&items[ind]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"slices"
+	"time"
+)
+
+type EnrichmentMemory struct {
+	*Store
+}
+
+func NewEnrichmentMemory(store *Store) *EnrichmentMemory {
+	return &EnrichmentMemory{Store: store}
+}
+
+func (e *EnrichmentMemory) CreateEnrichmentJob(ctx context.Context, songID string) error {
+	return e.update(ctx, func(d *data) error {
+		if err := d.requireSong(songID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job := enrichmentJobRow{
+			ID:        newID(),
+			SongID:    songID,
+			Status:    entity.EnrichmentStatusPending,
+			RunAt:     now,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if existing := d.enrichmentJobBySongID(songID); existing != nil {
+			job.ID = existing.ID
+			job.CreatedAt = existing.CreatedAt
+		}
+		d.enrichmentJobs[job.ID] = job
+		d.setEnrichmentStatus(songID, entity.EnrichmentStatusPending)
+
+		return nil
+	})
+}
+
+// ClaimEnrichmentJobs matches EnrichmentPostgres.ClaimEnrichmentJobs, the
+// store lock standing in for SKIP LOCKED.
+func (e *EnrichmentMemory) ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) ([]entity.EnrichmentJob, error) {
+	var jobs []entity.EnrichmentJob
+	err := e.update(ctx, func(d *data) error {
+		now := time.Now()
+
+		var due []enrichmentJobRow
+		for _, job := range d.enrichmentJobs {
+			pending := job.Status == entity.EnrichmentStatusPending && !job.RunAt.After(now)
+			expired := job.Status == entity.EnrichmentStatusRunning && job.LockedUntil != nil && job.LockedUntil.Before(now)
+			if pending || expired {
+				due = append(due, job)
+			}
+		}
+		slices.SortFunc(due, func(a, b enrichmentJobRow) int {
+			return a.RunAt.Compare(b.RunAt)
+		})
+		if len(due) > limit {
+			due = due[:limit]
+		}
+
+		lockedUntil := now.Add(lease)
+		for _, job := range due {
+			job.Status = entity.EnrichmentStatusRunning
+			job.Attempts++
+			job.LockedUntil = &lockedUntil
+			job.Claim = newID()
+			job.UpdatedAt = now
+			d.enrichmentJobs[job.ID] = job
+			jobs = append(jobs, enrichmentJob(job))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (e *EnrichmentMemory) CompleteEnrichmentJob(ctx context.Context, jobID, claim string) error {
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusDone, entity.EnrichmentStatusDone, nil, nil)
+}
+
+func (e *EnrichmentMemory) RetryEnrichmentJob(ctx context.Context, jobID, claim string, delay time.Duration, lastError string) error {
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusPending, entity.EnrichmentStatusPending, &delay, &lastError)
+}
+
+func (e *EnrichmentMemory) FailEnrichmentJob(ctx context.Context, jobID, claim string, lastError string) error {
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusFailed, entity.EnrichmentStatusFailed, nil, &lastError)
+}
+
+func (e *EnrichmentMemory) finish(ctx context.Context, jobID, claim, jobStatus, songStatus string, delay *time.Duration, lastError *string) error {
+	return e.update(ctx, func(d *data) error {
+		job, ok := d.enrichmentJobs[jobID]
+		if !ok || job.Claim == "" || job.Claim != claim {
+			return repoerrors.ErrNotFound
+		}
+
+		now := time.Now()
+		job.Status = jobStatus
+		if delay != nil {
+			job.RunAt = now.Add(*delay)
+		}
+		job.LockedUntil = nil
+		job.Claim = ""
+		job.LastError = copyString(lastError)
+		job.UpdatedAt = now
+		d.enrichmentJobs[jobID] = job
+		d.setEnrichmentStatus(job.SongID, songStatus)
+
+		return nil
+	})
+}
+
+func (e *EnrichmentMemory) GetEnrichmentJobBySongID(ctx context.Context, songID string) (*entity.EnrichmentJob, error) {
+	var job *entity.EnrichmentJob
+	err := e.view(ctx, func(d *data) error {
+		row := d.enrichmentJobBySongID(songID)
+		if row == nil {
+			return repoerrors.ErrNotFound
+		}
+		found := enrichmentJob(*row)
+		job = &found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (e *EnrichmentMemory) GetEnrichmentJobs(ctx context.Context, filter *entity.EnrichmentJobFilter) ([]entity.EnrichmentJob, error) {
+	var jobs []entity.EnrichmentJob
+	err := e.view(ctx, func(d *data) error {
+		for _, job := range d.enrichmentJobs {
+			if filter.Status == "" || job.Status == filter.Status {
+				jobs = append(jobs, enrichmentJob(job))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(jobs, func(a, b entity.EnrichmentJob) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return paginate(jobs, filter.Limit, filter.Offset), nil
+}
+
+func (e *EnrichmentMemory) RequeueFailedEnrichmentJobs(ctx context.Context, songID string) (int, error) {
+	var requeued int
+	err := e.update(ctx, func(d *data) error {
+		now := time.Now()
+		for id, job := range d.enrichmentJobs {
+			if job.Status != entity.EnrichmentStatusFailed || (songID != "" && job.SongID != songID) {
+				continue
+			}
+
+			job.Status = entity.EnrichmentStatusPending
+			job.Attempts = 0
+			job.RunAt = now
+			job.LockedUntil = nil
+			job.LastError = nil
+			job.UpdatedAt = now
+			d.enrichmentJobs[id] = job
+			d.setEnrichmentStatus(job.SongID, entity.EnrichmentStatusPending)
+			requeued++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return requeued, nil
+}
+
+func (d *data) enrichmentJobBySongID(songID string) *enrichmentJobRow {
+	for _, job := range d.enrichmentJobs {
+		if job.SongID == songID {
+			return &job
+		}
+	}
+
+	return nil
+}
+
+func (d *data) setEnrichmentStatus(songID, status string) {
+	if song, ok := d.songs[songID]; ok {
+		song.EnrichmentStatus = status
+		d.songs[songID] = song
+	}
+}
+
+func enrichmentJob(row enrichmentJobRow) entity.EnrichmentJob {
+	return entity.EnrichmentJob{
+		ID:          row.ID,
+		SongID:      row.SongID,
+		Status:      row.Status,
+		Attempts:    row.Attempts,
+		RunAt:       row.RunAt,
+		LockedUntil: copyTime(row.LockedUntil),
+		LastError:   copyString(row.LastError),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		Claim:       row.Claim,
+	}
+}
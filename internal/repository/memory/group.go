@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type GroupMemory struct {
+	*Store
+}
+
+func NewGroupMemory(store *Store) *GroupMemory {
+	return &GroupMemory{Store: store}
+}
+
+func (g *GroupMemory) CreateGroup(ctx context.Context, name string) (string, error) {
+	groupID := newID()
+
+	err := g.update(ctx, func(d *data) error {
+		if d.groupByName(name) != nil {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		d.groups[groupID] = groupRow{ID: groupID, Name: name, CreatedAt: time.Now()}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return groupID, nil
+}
+
+func (g *GroupMemory) GetGroupIDByName(ctx context.Context, name string) (string, error) {
+	var groupID string
+
+	err := g.view(ctx, func(d *data) error {
+		group := d.groupByName(name)
+		if group == nil {
+			return repoerrors.ErrNotFound
+		}
+
+		groupID = group.ID
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return groupID, nil
+}
+
+func (g *GroupMemory) GetGroups(ctx context.Context) ([]entity.Group, error) {
+	var groups []entity.Group
+
+	err := g.view(ctx, func(d *data) error {
+		for _, row := range d.groups {
+			groups = append(groups, d.group(row))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(groups, func(a, b entity.Group) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return groups, nil
+}
+
+func (g *GroupMemory) GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error) {
+	var group entity.Group
+
+	err := g.view(ctx, func(d *data) error {
+		row, ok := d.groups[groupID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		group = d.group(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (g *GroupMemory) RenameGroup(ctx context.Context, groupID, name string) error {
+	return g.update(ctx, func(d *data) error {
+		row, ok := d.groups[groupID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+		if other := d.groupByName(name); other != nil && other.ID != groupID {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		row.Name = name
+		d.groups[groupID] = row
+		return nil
+	})
+}
+
+// LockGroup only checks that the group exists, as a transaction holds the
+// whole store.
+func (g *GroupMemory) LockGroup(ctx context.Context, groupID string) error {
+	return g.view(ctx, func(d *data) error {
+		if _, ok := d.groups[groupID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (g *GroupMemory) DeleteGroup(ctx context.Context, groupID string) error {
+	return g.update(ctx, func(d *data) error {
+		if _, ok := d.groups[groupID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		d.deleteGroup(groupID)
+		return nil
+	})
+}
+
+func (d *data) group(row groupRow) entity.Group {
+	group := entity.Group{ID: row.ID, Name: row.Name}
+	for _, song := range d.songs {
+		if song.GroupID == row.ID {
+			group.SongsCount++
+		}
+	}
+
+	return group
+}
+
+func (d *data) groupByName(name string) *groupRow {
+	for _, row := range d.groups {
+		if row.Name == name {
+			return &row
+		}
+	}
+
+	return nil
+}
+
+// deleteGroup removes the group together with its albums and songs.
+func (d *data) deleteGroup(groupID string) {
+	for id, song := range d.songs {
+		if song.GroupID == groupID {
+			d.deleteSong(id)
+		}
+	}
+	for id, album := range d.albums {
+		if album.GroupID == groupID {
+			d.deleteAlbum(id)
+		}
+	}
+	delete(d.groups, groupID)
+}
+
+func (d *data) requireGroup(groupID string) error {
+	if _, ok := d.groups[groupID]; !ok {
+		return fmt.Errorf("group %s: %w", groupID, repoerrors.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+type LyricsMemory struct {
+	*Store
+}
+
+func NewLyricsMemory(store *Store) *LyricsMemory {
+	return &LyricsMemory{Store: store}
+}
+
+// AddLyricsStanza stores the stanza together with its lines.
+func (l *LyricsMemory) AddLyricsStanza(ctx context.Context, stanza *entity.LyricsStanza) error {
+	stanzaID := newID()
+
+	err := l.update(ctx, func(d *data) error {
+		if err := d.requireSong(stanza.SongID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		d.stanzas[stanzaID] = stanzaRow{
+			ID:           stanzaID,
+			SongID:       stanza.SongID,
+			StanzaNumber: stanza.StanzaNumber,
+			Label:        copyString(stanza.Label),
+			CreatedAt:    now,
+		}
+		for _, verse := range stanza.Verses {
+			verseID := newID()
+			d.verses[verseID] = verseRow{
+				ID:          verseID,
+				SongID:      stanza.SongID,
+				StanzaID:    stanzaID,
+				Verse:       verse.Verse,
+				VerseNumber: verse.VerseNumber,
+				CreatedAt:   now,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	stanza.ID = stanzaID
+	return nil
+}
+
+// GetLyricsBySongIDs returns the stanzas of every given song keyed by song ID.
+func (l *LyricsMemory) GetLyricsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.LyricsStanza, error) {
+	lyrics := make(map[string][]entity.LyricsStanza)
+
+	_ = l.view(ctx, func(d *data) error {
+		for _, songID := range songIDs {
+			if stanzas := d.songStanzas(songID, 1, math.MaxInt); len(stanzas) > 0 {
+				lyrics[songID] = stanzas
+			}
+		}
+		return nil
+	})
+
+	return lyrics, nil
+}
+
+func (l *LyricsMemory) GetLyricsStanzas(ctx context.Context, songID string, first, last int) ([]entity.LyricsStanza, error) {
+	var stanzas []entity.LyricsStanza
+
+	_ = l.view(ctx, func(d *data) error {
+		stanzas = d.songStanzas(songID, first, last)
+		return nil
+	})
+
+	return stanzas, nil
+}
+
+func (l *LyricsMemory) UpdateLyricsStanza(ctx context.Context, songID string, stanzaNumber int, label *string) error {
+	return l.update(ctx, func(d *data) error {
+		for _, row := range d.stanzas {
+			if row.SongID == songID && row.StanzaNumber == stanzaNumber {
+				row.Label = copyString(label)
+				d.stanzas[row.ID] = row
+				return nil
+			}
+		}
+		return repoerrors.ErrNotFound
+	})
+}
+
+// DeleteEmptyLyricsStanzas deletes the stanzas of the song left without
+// lines and renumbers the others.
+func (l *LyricsMemory) DeleteEmptyLyricsStanzas(ctx context.Context, songID string) error {
+	return l.update(ctx, func(d *data) error {
+		used := make(map[string]bool)
+		for _, row := range d.verses {
+			if row.SongID == songID {
+				used[row.StanzaID] = true
+			}
+		}
+
+		var stanzas []stanzaRow
+		for _, row := range d.stanzas {
+			if row.SongID != songID {
+				continue
+			}
+			if !used[row.ID] {
+				delete(d.stanzas, row.ID)
+				continue
+			}
+			stanzas = append(stanzas, row)
+		}
+
+		slices.SortFunc(stanzas, func(a, b stanzaRow) int {
+			return cmp.Compare(a.StanzaNumber, b.StanzaNumber)
+		})
+		for ind, row := range stanzas {
+			row.StanzaNumber = ind + 1
+			d.stanzas[row.ID] = row
+		}
+		return nil
+	})
+}
+
+func (l *LyricsMemory) GetPaginatedLyrics(ctx context.Context, songID string, limit, offset int) ([]entity.LyricsVerse, error) {
+	verses := l.songVerses(ctx, songID)
+
+	// unlike the song filter, LIMIT is always applied here
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	return paginate(verses, limit, offset), nil
+}
+
+func (l *LyricsMemory) GetLyricsAfter(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error) {
+	verses := l.songVerses(ctx, songID)
+	start, _ := slices.BinarySearchFunc(verses, verseNumber+1, func(verse entity.LyricsVerse, number int) int {
+		return cmp.Compare(verse.VerseNumber, number)
+	})
+
+	return paginate(verses[start:], limit, 0), nil
+}
+
+func (l *LyricsMemory) GetLyricsBefore(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error) {
+	verses := l.songVerses(ctx, songID)
+	end, _ := slices.BinarySearchFunc(verses, verseNumber, func(verse entity.LyricsVerse, number int) int {
+		return cmp.Compare(verse.VerseNumber, number)
+	})
+
+	return paginate(verses[max(end-limit, 0):end], 0, 0), nil
+}
+
+func (l *LyricsMemory) GetLyricsVerse(ctx context.Context, songID string, verseNumber int) (*entity.LyricsVerse, error) {
+	var verse *entity.LyricsVerse
+
+	err := l.view(ctx, func(d *data) error {
+		row := d.verseByNumber(songID, verseNumber)
+		if row == nil {
+			return repoerrors.ErrNotFound
+		}
+
+		verse = &entity.LyricsVerse{
+			ID:           row.ID,
+			SongID:       row.SongID,
+			Verse:        row.Verse,
+			VerseNumber:  row.VerseNumber,
+			StanzaNumber: d.stanzas[row.StanzaID].StanzaNumber,
+		}
+		return nil
+	})
+
+	return verse, err
+}
+
+func (l *LyricsMemory) GetLyricsVerseByID(ctx context.Context, songID, verseID string) (*entity.LyricsVerse, error) {
+	var verse *entity.LyricsVerse
+
+	err := l.view(ctx, func(d *data) error {
+		row, ok := d.verses[verseID]
+		if !ok || row.SongID != songID {
+			return repoerrors.ErrNotFound
+		}
+
+		verse = &entity.LyricsVerse{
+			ID:           row.ID,
+			SongID:       row.SongID,
+			Verse:        row.Verse,
+			VerseNumber:  row.VerseNumber,
+			StanzaNumber: d.stanzas[row.StanzaID].StanzaNumber,
+		}
+		return nil
+	})
+
+	return verse, err
+}
+
+func (l *LyricsMemory) CountLyricsVerses(ctx context.Context, songID string) (int, error) {
+	return len(l.songVerses(ctx, songID)), nil
+}
+
+func (l *LyricsMemory) UpdateLyricsVerse(ctx context.Context, songID string, verseNumber int, verse string) error {
+	return l.update(ctx, func(d *data) error {
+		row := d.verseByNumber(songID, verseNumber)
+		if row == nil {
+			return repoerrors.ErrNotFound
+		}
+
+		row.Verse = verse
+		d.verses[row.ID] = *row
+		return nil
+	})
+}
+
+// InsertLyricsVerse puts the verse at verse.VerseNumber into the stanza
+// numbered verse.StanzaNumber, shifting the verses at and after that number
+// one place down.
+func (l *LyricsMemory) InsertLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) error {
+	if verse.VerseNumber < 1 {
+		return fmt.Errorf("failed to insert verse: invalid verse number %d", verse.VerseNumber)
+	}
+
+	verseID := newID()
+
+	return l.update(ctx, func(d *data) error {
+		stanza := d.stanzaByNumber(verse.SongID, verse.StanzaNumber)
+		if stanza == nil {
+			return repoerrors.ErrNotFound
+		}
+
+		for _, row := range d.songVerseRows(verse.SongID) {
+			if row.VerseNumber >= verse.VerseNumber {
+				row.VerseNumber++
+				d.verses[row.ID] = row
+			}
+		}
+
+		d.verses[verseID] = verseRow{
+			ID:          verseID,
+			SongID:      verse.SongID,
+			StanzaID:    stanza.ID,
+			Verse:       verse.Verse,
+			VerseNumber: verse.VerseNumber,
+			CreatedAt:   time.Now(),
+		}
+		return nil
+	})
+}
+
+// MoveLyricsVerse moves the verse to position, into the stanza numbered
+// stanzaNumber, shifting the verses in between so that verse numbers stay
+// contiguous.
+func (l *LyricsMemory) MoveLyricsVerse(ctx context.Context, songID string, verseNumber, position, stanzaNumber int) error {
+	return l.update(ctx, func(d *data) error {
+		if d.verseByNumber(songID, verseNumber) == nil {
+			return repoerrors.ErrNotFound
+		}
+		stanza := d.stanzaByNumber(songID, stanzaNumber)
+		if stanza == nil {
+			return fmt.Errorf("failed to move verse %d: song has no stanza %d", verseNumber, stanzaNumber)
+		}
+
+		for _, row := range d.songVerseRows(songID) {
+			switch {
+			case row.VerseNumber == verseNumber:
+				row.VerseNumber = position
+				row.StanzaID = stanza.ID
+			case verseNumber < position && row.VerseNumber > verseNumber && row.VerseNumber <= position:
+				row.VerseNumber--
+			case verseNumber > position && row.VerseNumber >= position && row.VerseNumber < verseNumber:
+				row.VerseNumber++
+			}
+			d.verses[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// DeleteLyricsVerse deletes the verse and closes the gap it leaves.
+func (l *LyricsMemory) DeleteLyricsVerse(ctx context.Context, songID string, verseNumber int) error {
+	return l.update(ctx, func(d *data) error {
+		removed := d.verseByNumber(songID, verseNumber)
+		if removed == nil {
+			return repoerrors.ErrNotFound
+		}
+
+		delete(d.verses, removed.ID)
+		for _, row := range d.songVerseRows(songID) {
+			if row.VerseNumber > verseNumber {
+				row.VerseNumber--
+				d.verses[row.ID] = row
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteLyrics deletes the stanzas of the song, and their lines with them.
+func (l *LyricsMemory) DeleteLyrics(ctx context.Context, songID string) error {
+	return l.update(ctx, func(d *data) error {
+		d.deleteLyrics(songID)
+		return nil
+	})
+}
+
+// songVerses returns the verses of the song ordered by number, without IDs
+// like the postgres queries.
+func (l *LyricsMemory) songVerses(ctx context.Context, songID string) []entity.LyricsVerse {
+	var verses []entity.LyricsVerse
+
+	_ = l.view(ctx, func(d *data) error {
+		for _, row := range d.songVerseRows(songID) {
+			verses = append(verses, entity.LyricsVerse{
+				ID:           row.ID,
+				SongID:       row.SongID,
+				Verse:        row.Verse,
+				VerseNumber:  row.VerseNumber,
+				StanzaNumber: d.stanzas[row.StanzaID].StanzaNumber,
+			})
+		}
+		return nil
+	})
+
+	return verses
+}
+
+// songStanzas returns the stanzas of the song numbered from first to last,
+// with their lines.
+func (d *data) songStanzas(songID string, first, last int) []entity.LyricsStanza {
+	var stanzas []entity.LyricsStanza
+	index := make(map[string]int)
+	for _, row := range d.stanzas {
+		if row.SongID == songID && row.StanzaNumber >= first && row.StanzaNumber <= last {
+			stanzas = append(stanzas, entity.LyricsStanza{
+				ID:           row.ID,
+				SongID:       row.SongID,
+				StanzaNumber: row.StanzaNumber,
+				Label:        copyString(row.Label),
+			})
+		}
+	}
+
+	slices.SortFunc(stanzas, func(a, b entity.LyricsStanza) int {
+		return cmp.Compare(a.StanzaNumber, b.StanzaNumber)
+	})
+	for ind, stanza := range stanzas {
+		index[stanza.ID] = ind
+	}
+
+	for _, row := range d.songVerseRows(songID) {
+		if ind, ok := index[row.StanzaID]; ok {
+			stanzas[ind].Verses = append(stanzas[ind].Verses, entity.LyricsVerse{
+				Verse:        row.Verse,
+				VerseNumber:  row.VerseNumber,
+				StanzaNumber: stanzas[ind].StanzaNumber,
+			})
+		}
+	}
+
+	return stanzas
+}
+
+func (d *data) songVerseRows(songID string) []verseRow {
+	var verses []verseRow
+	for _, row := range d.verses {
+		if row.SongID == songID {
+			verses = append(verses, row)
+		}
+	}
+
+	slices.SortFunc(verses, func(a, b verseRow) int {
+		return cmp.Compare(a.VerseNumber, b.VerseNumber)
+	})
+
+	return verses
+}
+
+func (d *data) verseByNumber(songID string, verseNumber int) *verseRow {
+	for _, row := range d.verses {
+		if row.SongID == songID && row.VerseNumber == verseNumber {
+			return &row
+		}
+	}
+
+	return nil
+}
+
+func (d *data) stanzaByNumber(songID string, stanzaNumber int) *stanzaRow {
+	for _, row := range d.stanzas {
+		if row.SongID == songID && row.StanzaNumber == stanzaNumber {
+			return &row
+		}
+	}
+
+	return nil
+}
+
+// deleteLyrics removes the stanzas of the song and their lines.
+func (d *data) deleteLyrics(songID string) {
+	for id, verse := range d.verses {
+		if verse.SongID == songID {
+			delete(d.verses, id)
+		}
+	}
+	for id, stanza := range d.stanzas {
+		if stanza.SongID == songID {
+			delete(d.stanzas, id)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"time"
+)
+
+type SongDetailCacheMemory struct {
+	*Store
+}
+
+func NewSongDetailCacheMemory(store *Store) *SongDetailCacheMemory {
+	return &SongDetailCacheMemory{Store: store}
+}
+
+func (c *SongDetailCacheMemory) GetSongDetailCache(ctx context.Context, key string) (*entity.SongDetailCacheEntry, error) {
+	var entry *entity.SongDetailCacheEntry
+	err := c.view(ctx, func(d *data) error {
+		row, ok := d.songDetailCache[key]
+		if !ok || row.expired(time.Now()) {
+			return repoerrors.ErrNotFound
+		}
+
+		entry = &entity.SongDetailCacheEntry{
+			Key:       row.Key,
+			GroupName: row.GroupName,
+			Title:     row.Title,
+			Found:     row.Found,
+			Detail:    row.Detail,
+			ExpiresAt: copyTime(row.ExpiresAt),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (c *SongDetailCacheMemory) SetSongDetailCache(ctx context.Context, entry *entity.SongDetailCacheEntry, ttl time.Duration) error {
+	return c.update(ctx, func(d *data) error {
+		now := time.Now()
+		row := songDetailCacheRow{
+			Key:       entry.Key,
+			GroupName: entry.GroupName,
+			Title:     entry.Title,
+			Found:     entry.Found,
+			Detail:    entry.Detail,
+			CreatedAt: now,
+		}
+		if ttl > 0 {
+			expiresAt := now.Add(ttl)
+			row.ExpiresAt = &expiresAt
+		}
+		d.songDetailCache[row.Key] = row
+
+		return nil
+	})
+}
+
+func (c *SongDetailCacheMemory) DeleteSongDetailCache(ctx context.Context, key string) (int, error) {
+	var deleted int
+	err := c.update(ctx, func(d *data) error {
+		now := time.Now()
+		for k, row := range d.songDetailCache {
+			if key != "" && k != key {
+				continue
+			}
+
+			if !row.expired(now) {
+				deleted++
+			}
+			delete(d.songDetailCache, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (c *SongDetailCacheMemory) CountSongDetailCache(ctx context.Context) (int, error) {
+	var count int
+	err := c.view(ctx, func(d *data) error {
+		now := time.Now()
+		for _, row := range d.songDetailCache {
+			if !row.expired(now) {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r songDetailCacheRow) expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
+}
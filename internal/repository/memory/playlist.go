@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type PlaylistMemory struct {
+	*Store
+}
+
+func NewPlaylistMemory(store *Store) *PlaylistMemory {
+	return &PlaylistMemory{Store: store}
+}
+
+func (p *PlaylistMemory) CreatePlaylist(ctx context.Context, playlist *entity.Playlist) (string, error) {
+	playlistID := newID()
+
+	err := p.update(ctx, func(d *data) error {
+		now := time.Now()
+		d.playlists[playlistID] = playlistRow{
+			ID:          playlistID,
+			Name:        playlist.Name,
+			Description: copyString(playlist.Description),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return playlistID, nil
+}
+
+func (p *PlaylistMemory) GetPlaylists(ctx context.Context) ([]entity.Playlist, error) {
+	var playlists []entity.Playlist
+
+	err := p.view(ctx, func(d *data) error {
+		for _, row := range d.playlists {
+			playlists = append(playlists, d.playlist(row))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(playlists, func(a, b entity.Playlist) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.CreatedAt.Compare(b.CreatedAt))
+	})
+
+	return playlists, nil
+}
+
+func (p *PlaylistMemory) GetPlaylistByID(ctx context.Context, playlistID string) (*entity.Playlist, error) {
+	var playlist entity.Playlist
+
+	err := p.view(ctx, func(d *data) error {
+		row, ok := d.playlists[playlistID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		playlist = d.playlist(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+func (p *PlaylistMemory) UpdatePlaylist(ctx context.Context, update *entity.PlaylistUpdate) error {
+	if update.Name == nil && update.Description == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	return p.update(ctx, func(d *data) error {
+		row, ok := d.playlists[update.ID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		if update.Name != nil {
+			row.Name = *update.Name
+		}
+		if update.Description != nil {
+			row.Description = copyString(update.Description)
+		}
+
+		row.UpdatedAt = time.Now()
+		d.playlists[row.ID] = row
+		return nil
+	})
+}
+
+func (p *PlaylistMemory) DeletePlaylist(ctx context.Context, playlistID string) error {
+	return p.update(ctx, func(d *data) error {
+		if _, ok := d.playlists[playlistID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		for id, entry := range d.entries {
+			if entry.PlaylistID == playlistID {
+				delete(d.entries, id)
+			}
+		}
+		delete(d.playlists, playlistID)
+		return nil
+	})
+}
+
+func (p *PlaylistMemory) GetPlaylistEntries(ctx context.Context, playlistID string) ([]entity.PlaylistEntry, error) {
+	var entries []entity.PlaylistEntry
+
+	err := p.view(ctx, func(d *data) error {
+		for _, row := range d.playlistEntries(playlistID) {
+			entries = append(entries, entity.PlaylistEntry{
+				ID:         row.ID,
+				PlaylistID: row.PlaylistID,
+				SongID:     row.SongID,
+				Position:   row.Position,
+				Note:       copyString(row.Note),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// InsertPlaylistEntry puts the entry at entry.Position, shifting the entries
+// at and after that position one place down.
+func (p *PlaylistMemory) InsertPlaylistEntry(ctx context.Context, entry *entity.PlaylistEntry) (string, error) {
+	if entry.Position < 1 {
+		return "", fmt.Errorf("failed to insert playlist entry: invalid position %d", entry.Position)
+	}
+
+	entryID := newID()
+
+	err := p.update(ctx, func(d *data) error {
+		if _, ok := d.playlists[entry.PlaylistID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+		if _, ok := d.songs[entry.SongID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		for _, row := range d.playlistEntries(entry.PlaylistID) {
+			if row.Position >= entry.Position {
+				row.Position++
+				d.entries[row.ID] = row
+			}
+		}
+
+		d.entries[entryID] = entryRow{
+			ID:         entryID,
+			PlaylistID: entry.PlaylistID,
+			SongID:     entry.SongID,
+			Position:   entry.Position,
+			Note:       copyString(entry.Note),
+			CreatedAt:  time.Now(),
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return entryID, nil
+}
+
+// MovePlaylistEntry moves the entry to position, shifting the entries in
+// between so that positions stay contiguous.
+func (p *PlaylistMemory) MovePlaylistEntry(ctx context.Context, playlistID, entryID string, position int) error {
+	return p.update(ctx, func(d *data) error {
+		moved, ok := d.entries[entryID]
+		if !ok || moved.PlaylistID != playlistID {
+			return repoerrors.ErrNotFound
+		}
+
+		for _, row := range d.playlistEntries(playlistID) {
+			switch {
+			case row.ID == entryID:
+				row.Position = position
+			case moved.Position < position && row.Position > moved.Position && row.Position <= position:
+				row.Position--
+			case moved.Position > position && row.Position >= position && row.Position < moved.Position:
+				row.Position++
+			}
+			d.entries[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// RemovePlaylistEntry deletes the entry and closes the gap it leaves.
+func (p *PlaylistMemory) RemovePlaylistEntry(ctx context.Context, playlistID, entryID string) error {
+	return p.update(ctx, func(d *data) error {
+		removed, ok := d.entries[entryID]
+		if !ok || removed.PlaylistID != playlistID {
+			return repoerrors.ErrNotFound
+		}
+
+		delete(d.entries, entryID)
+		for _, row := range d.playlistEntries(playlistID) {
+			if row.Position > removed.Position {
+				row.Position--
+				d.entries[row.ID] = row
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveSongFromPlaylists deletes every entry of the song and renumbers the
+// affected playlists.
+func (p *PlaylistMemory) RemoveSongFromPlaylists(ctx context.Context, songID string) error {
+	return p.update(ctx, func(d *data) error {
+		affected := make(map[string]bool)
+		for id, entry := range d.entries {
+			if entry.SongID == songID {
+				affected[entry.PlaylistID] = true
+				delete(d.entries, id)
+			}
+		}
+
+		for playlistID := range affected {
+			for ind, row := range d.playlistEntries(playlistID) {
+				row.Position = ind + 1
+				d.entries[row.ID] = row
+			}
+		}
+		return nil
+	})
+}
+
+func (d *data) playlist(row playlistRow) entity.Playlist {
+	playlist := entity.Playlist{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: copyString(row.Description),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	for _, entry := range d.entries {
+		if entry.PlaylistID == row.ID {
+			playlist.EntriesCount++
+		}
+	}
+
+	return playlist
+}
+
+// playlistEntries returns the entries of the playlist ordered by position.
+func (d *data) playlistEntries(playlistID string) []entryRow {
+	var entries []entryRow
+	for _, row := range d.entries {
+		if row.PlaylistID == playlistID {
+			entries = append(entries, row)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b entryRow) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+
+	return entries
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/pkg/textquery"
+	"effective_mobile_tz/pkg/trgm"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxSnippetVerses bounds the matching verses quoted in a snippet, as
+// ts_headline does with MaxFragments.
+const maxSnippetVerses = 3
+
+type SearchMemory struct {
+	*Store
+}
+
+func NewSearchMemory(store *Store) *SearchMemory {
+	return &SearchMemory{Store: store}
+}
+
+// SearchSongs matches SearchPostgres.SearchSongs on the lexemes of the search
+// language, which only english reduces. The rank of a verse is the share of
+// its words that match.
+func (s *SearchMemory) SearchSongs(ctx context.Context, query *textquery.Query, limit, offset int) ([]entity.SongSearchResult, error) {
+	var results []entity.SongSearchResult
+
+	_ = s.view(ctx, func(d *data) error {
+		query, ok := d.searchQuery(query)
+		if !ok {
+			return nil
+		}
+
+		verses := make(map[string][]verseRow)
+		for _, verse := range d.verses {
+			verses[verse.SongID] = append(verses[verse.SongID], verse)
+		}
+
+		for songID, songVerses := range verses {
+			song, ok := d.songs[songID]
+			if !ok {
+				continue
+			}
+
+			slices.SortFunc(songVerses, func(a, b verseRow) int {
+				return cmp.Compare(a.VerseNumber, b.VerseNumber)
+			})
+			if result, ok := d.searchSong(query, songVerses); ok {
+				result.SongID = song.ID
+				result.Title = song.Title
+				result.GroupName = d.groups[song.GroupID].Name
+				results = append(results, *result)
+			}
+		}
+		return nil
+	})
+
+	slices.SortFunc(results, func(a, b entity.SongSearchResult) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.SongID, b.SongID))
+	})
+
+	return paginate(results, limit, offset), nil
+}
+
+func (d *data) searchSong(query *textquery.Query, verses []verseRow) (*entity.SongSearchResult, bool) {
+	result := &entity.SongSearchResult{}
+	matched := make([]bool, len(query.Clauses))
+	var snippets []string
+
+	for _, verse := range verses {
+		words := d.lexemes(verse.Verse)
+		for _, term := range query.Excluded {
+			if term.Match(words) {
+				return nil, false
+			}
+		}
+
+		var matchingWords int
+		for ind, clause := range query.Clauses {
+			for _, term := range clause {
+				if term.Match(words) {
+					matched[ind] = true
+					matchingWords += len(term.Words)
+				}
+			}
+		}
+		if matchingWords == 0 {
+			continue
+		}
+
+		result.Rank += float64(matchingWords) / float64(len(words))
+		result.Verses = append(result.Verses, verse.VerseNumber)
+		if len(snippets) < maxSnippetVerses {
+			snippets = append(snippets, d.highlight(verse.Verse, query))
+		}
+	}
+
+	if len(result.Verses) == 0 || slices.Contains(matched, false) {
+		return nil, false
+	}
+	result.Snippet = strings.Join(snippets, " ... ")
+
+	return result, true
+}
+
+// highlight wraps the words of text whose lexeme matches a word of the query
+// in <b></b>.
+func (d *data) highlight(text string, query *textquery.Query) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := string(runes[i:end])
+		if matchesQueryWord(d.lexeme(strings.ToLower(word)), query) {
+			b.WriteString("<b>" + word + "</b>")
+		} else {
+			b.WriteString(word)
+		}
+		i = end
+	}
+
+	return b.String()
+}
+
+func matchesQueryWord(word string, query *textquery.Query) bool {
+	if word == "" {
+		return false
+	}
+
+	for _, clause := range query.Clauses {
+		for _, term := range clause {
+			for ind, termWord := range term.Words {
+				if word == termWord || (term.Prefix && ind == len(term.Words)-1 && strings.HasPrefix(word, termWord)) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// SetSearchLanguage only reports whether the language changed, as the memory
+// store has no text search configurations.
+func (s *SearchMemory) SetSearchLanguage(ctx context.Context, language string) (bool, error) {
+	var changed bool
+	err := s.update(ctx, func(d *data) error {
+		changed = d.searchLanguage != language
+		d.searchLanguage = language
+		return nil
+	})
+
+	return changed, err
+}
+
+// SuggestTitles matches SearchPostgres.SuggestTitles.
+func (s *SearchMemory) SuggestTitles(ctx context.Context, query string, threshold float64, limit int) ([]entity.TitleSuggestion, error) {
+	var suggestions []entity.TitleSuggestion
+	similarity := make(map[string]float64)
+
+	_ = s.view(ctx, func(d *data) error {
+		for _, song := range d.songs {
+			score := trgm.WordSimilarity(query, song.Title)
+			if score < threshold {
+				continue
+			}
+
+			suggestions = append(suggestions, entity.TitleSuggestion{
+				SongID:    song.ID,
+				Title:     song.Title,
+				GroupName: d.groups[song.GroupID].Name,
+				Score:     score,
+			})
+			similarity[song.ID] = trgm.Similarity(query, song.Title)
+		}
+		return nil
+	})
+
+	slices.SortFunc(suggestions, func(a, b entity.TitleSuggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(similarity[b.SongID], similarity[a.SongID]),
+			cmp.Compare(a.Title, b.Title), cmp.Compare(a.GroupName, b.GroupName))
+	})
+
+	return paginate(suggestions, limit, 0), nil
+}
+
+// SuggestGroups matches SearchPostgres.SuggestGroups.
+func (s *SearchMemory) SuggestGroups(ctx context.Context, query string, threshold float64, limit int) ([]entity.GroupSuggestion, error) {
+	var suggestions []entity.GroupSuggestion
+	similarity := make(map[string]float64)
+
+	_ = s.view(ctx, func(d *data) error {
+		for _, group := range d.groups {
+			score := trgm.WordSimilarity(query, group.Name)
+			if score < threshold {
+				continue
+			}
+
+			suggestions = append(suggestions, entity.GroupSuggestion{
+				GroupID: group.ID,
+				Name:    group.Name,
+				Score:   score,
+			})
+			similarity[group.ID] = trgm.Similarity(query, group.Name)
+		}
+		return nil
+	})
+
+	slices.SortFunc(suggestions, func(a, b entity.GroupSuggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(similarity[b.GroupID], similarity[a.GroupID]),
+			cmp.Compare(a.Name, b.Name))
+	})
+
+	return paginate(suggestions, limit, 0), nil
+}
@@ -0,0 +1,534 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/pkg/partialdate"
+	"effective_mobile_tz/pkg/trgm"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type SongMemory struct {
+	*Store
+}
+
+func NewSongMemory(store *Store) *SongMemory {
+	return &SongMemory{Store: store}
+}
+
+func (s *SongMemory) CreateSong(ctx context.Context, song *entity.Song) (string, error) {
+	songID := newID()
+
+	err := s.update(ctx, func(d *data) error {
+		if err := d.requireGroup(song.GroupID); err != nil {
+			return err
+		}
+		if song.AlbumID != nil {
+			if err := d.requireAlbum(*song.AlbumID); err != nil {
+				return err
+			}
+		}
+		if d.songByTitle(song.Title, song.GroupID) != nil {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		enrichmentStatus := song.EnrichmentStatus
+		if enrichmentStatus == "" {
+			enrichmentStatus = entity.EnrichmentStatusDone
+		}
+		precision := song.ReleaseDatePrecision
+		if precision == "" {
+			precision = string(partialdate.Day)
+		}
+
+		now := time.Now()
+		d.songs[songID] = songRow{
+			ID:                   songID,
+			Title:                song.Title,
+			GroupID:              song.GroupID,
+			ReleaseDate:          copyTime(song.ReleaseDate),
+			ReleaseDatePrecision: precision,
+			Link:                 song.Link,
+			AlbumID:              copyString(song.AlbumID),
+			TrackNumber:          copyInt(song.TrackNumber),
+			DiscNumber:           copyInt(song.DiscNumber),
+			EnrichmentStatus:     enrichmentStatus,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return songID, nil
+}
+
+// GetSongsByFilter matches SongPostgres.GetSongsByFilter: songs are sorted
+// by the keys of the filter and then by ID.
+func (s *SongMemory) GetSongsByFilter(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error) {
+	songs, err := s.filterSongs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range filter.Sort {
+		if _, ok := songSortKeys[key.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort field %q", key.Field)
+		}
+	}
+	compare := func(a, b entity.Song) int {
+		for _, key := range filter.Sort {
+			if c := songSortKeys[key.Field](a, b, key.Desc); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.ID, b.ID)
+	}
+	slices.SortFunc(songs, compare)
+
+	switch {
+	case filter.After != nil:
+		position := cursorSong(filter.After)
+		start, _ := slices.BinarySearchFunc(songs, position, compare)
+		if start < len(songs) && compare(songs[start], position) == 0 {
+			start++
+		}
+		return paginate(songs[start:], filter.Limit, 0), nil
+	case filter.Before != nil:
+		end, _ := slices.BinarySearchFunc(songs, cursorSong(filter.Before), compare)
+		songs = songs[:end]
+		if filter.Limit > 0 && filter.Limit < len(songs) {
+			songs = songs[len(songs)-filter.Limit:]
+		}
+		return paginate(songs, 0, 0), nil
+	}
+
+	return paginate(songs, filter.Limit, filter.Offset), nil
+}
+
+// CountSongsByFilter matches SongPostgres.CountSongsByFilter.
+func (s *SongMemory) CountSongsByFilter(ctx context.Context, filter *entity.SongFilter) (int, error) {
+	songs, err := s.filterSongs(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(songs), nil
+}
+
+// cursorSong returns a song at the position of the cursor, to be compared
+// with the listed songs.
+func cursorSong(cursor *entity.SongCursor) entity.Song {
+	return entity.Song{
+		ID:          cursor.ID,
+		Title:       cursor.Title,
+		GroupName:   cursor.GroupName,
+		ReleaseDate: cursor.ReleaseDate,
+		CreatedAt:   cursor.CreatedAt,
+	}
+}
+
+// songSortKeys compare songs by every sort field, NULL dates last in either
+// direction as in SongPostgres.
+var songSortKeys = map[string]func(a, b entity.Song, desc bool) int{
+	entity.SongSortTitle: func(a, b entity.Song, desc bool) int {
+		return directed(cmp.Compare(a.Title, b.Title), desc)
+	},
+	entity.SongSortGroup: func(a, b entity.Song, desc bool) int {
+		return directed(cmp.Compare(a.GroupName, b.GroupName), desc)
+	},
+	entity.SongSortReleaseDate: func(a, b entity.Song, desc bool) int {
+		return compareDirectedDates(a.ReleaseDate, b.ReleaseDate, desc)
+	},
+	entity.SongSortCreatedAt: func(a, b entity.Song, desc bool) int {
+		return compareDirectedDates(a.CreatedAt, b.CreatedAt, desc)
+	},
+}
+
+func compareDirectedDates(a, b *time.Time, desc bool) int {
+	if a == nil || b == nil {
+		return compareDates(a, b)
+	}
+
+	return directed(a.Compare(*b), desc)
+}
+
+func directed(c int, desc bool) int {
+	if desc {
+		return -c
+	}
+
+	return c
+}
+
+func (s *SongMemory) filterSongs(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error) {
+	var startDate, endDate *time.Time
+	for _, bound := range []struct {
+		value string
+		date  **time.Time
+	}{{filter.StartDate, &startDate}, {filter.EndDate, &endDate}} {
+		if bound.value == "" {
+			continue
+		}
+		date, err := parseDate(bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query song: invalid date %q", bound.value)
+		}
+		*bound.date = &date
+	}
+
+	var songs []entity.Song
+
+	err := s.view(ctx, func(d *data) error {
+		for _, row := range d.songs {
+			song := d.song(row)
+			if d.matchSong(row, &song, filter, startDate, endDate) {
+				songs = append(songs, song)
+			}
+		}
+		return nil
+	})
+
+	return songs, err
+}
+
+// matchesName tells whether name holds value or, when the filter is fuzzy,
+// whether it is alike value as pg_trgm's <% operator sees it.
+func matchesName(name, value string, filter *entity.SongFilter) bool {
+	if filter.Fuzzy {
+		return trgm.WordSimilarity(value, name) >= filter.SimilarityThreshold
+	}
+
+	return containsFold(name, value)
+}
+
+func (d *data) matchSong(row songRow, song *entity.Song, filter *entity.SongFilter, startDate, endDate *time.Time) bool {
+	if len(filter.IDs) > 0 && !slices.Contains(filter.IDs, row.ID) {
+		return false
+	}
+	// a partial release date is a period, which matches when it overlaps the range
+	if (startDate != nil || endDate != nil) && song.ReleaseDate == nil {
+		return false
+	}
+	if startDate != nil && !releaseEnd(song).After(*startDate) {
+		return false
+	}
+	if endDate != nil && song.ReleaseDate.After(*endDate) {
+		return false
+	}
+	if filter.Title != "" && !matchesName(row.Title, filter.Title, filter) {
+		return false
+	}
+	if filter.Link != "" && row.Link != filter.Link {
+		return false
+	}
+	if filter.Group != "" && !matchesName(song.GroupName, filter.Group, filter) {
+		return false
+	}
+	if filter.Album != "" && (song.AlbumTitle == nil || !containsFold(*song.AlbumTitle, filter.Album)) {
+		return false
+	}
+	if filter.AlbumID != "" && (row.AlbumID == nil || *row.AlbumID != filter.AlbumID) {
+		return false
+	}
+
+	if filter.Tag != "" || len(filter.AnyTags) > 0 || len(filter.AllTags) > 0 {
+		names := d.songTagNames(row.ID)
+		if filter.Tag != "" && !slices.Contains(names, filter.Tag) {
+			return false
+		}
+		if len(filter.AnyTags) > 0 && !slices.ContainsFunc(filter.AnyTags, func(name string) bool {
+			return slices.Contains(names, name)
+		}) {
+			return false
+		}
+		for _, name := range filter.AllTags {
+			if !slices.Contains(names, name) {
+				return false
+			}
+		}
+	}
+
+	if filter.HasLyrics != nil && d.hasVerse(row.ID, nil) != *filter.HasLyrics {
+		return false
+	}
+	// as in the postgres query a verse must hold every lexeme of the text, and
+	// a text of stop words only matches nothing
+	if filter.Text != "" {
+		lexemes := d.plainLexemes(filter.Text)
+		if len(lexemes) == 0 || !d.hasVerse(row.ID, lexemes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasVerse tells whether the song has a verse holding every one of lexemes.
+func (d *data) hasVerse(songID string, lexemes []string) bool {
+	for _, verse := range d.verses {
+		if verse.SongID == songID && containsWords(d.lexemes(verse.Verse), lexemes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *SongMemory) UpdateSong(ctx context.Context, update *entity.SongUpdate) error {
+	var releaseDate *time.Time
+	if update.ReleaseDate != nil {
+		date, err := parseDate(*update.ReleaseDate)
+		if err != nil {
+			return fmt.Errorf("failed to update song with ID %s: invalid date %q", update.ID, *update.ReleaseDate)
+		}
+		releaseDate = &date
+	}
+
+	if update.Title == nil && update.ReleaseDate == nil && update.GroupName == nil && update.Link == nil &&
+		update.AlbumID == nil && update.TrackNumber == nil && update.DiscNumber == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	return s.update(ctx, func(d *data) error {
+		row, ok := d.songs[update.ID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		if update.Title != nil {
+			row.Title = *update.Title
+		}
+		if update.ReleaseDate != nil {
+			row.ReleaseDate = releaseDate
+			row.ReleaseDatePrecision = update.ReleaseDatePrecision
+			if row.ReleaseDatePrecision == "" {
+				row.ReleaseDatePrecision = string(partialdate.Day)
+			}
+		}
+		if update.GroupName != nil {
+			if err := d.requireGroup(update.GroupID); err != nil {
+				return err
+			}
+			row.GroupID = update.GroupID
+		}
+		if update.Link != nil {
+			row.Link = *update.Link
+		}
+		if update.AlbumID != nil {
+			row.AlbumID = nil
+			if *update.AlbumID != "" {
+				if err := d.requireAlbum(*update.AlbumID); err != nil {
+					return err
+				}
+				row.AlbumID = copyString(update.AlbumID)
+			}
+		}
+		if update.TrackNumber != nil {
+			row.TrackNumber = copyInt(update.TrackNumber)
+		}
+		if update.DiscNumber != nil {
+			row.DiscNumber = copyInt(update.DiscNumber)
+		}
+
+		if other := d.songByTitle(row.Title, row.GroupID); other != nil && other.ID != row.ID {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		d.songs[row.ID] = row
+		return nil
+	})
+}
+
+func (s *SongMemory) DeleteSong(ctx context.Context, songID string) error {
+	return s.update(ctx, func(d *data) error {
+		if _, ok := d.songs[songID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		d.deleteSong(songID)
+		return nil
+	})
+}
+
+func (s *SongMemory) GetSongByID(ctx context.Context, songID string) (*entity.Song, error) {
+	var song entity.Song
+
+	err := s.view(ctx, func(d *data) error {
+		row, ok := d.songs[songID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		song = d.song(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+func (s *SongMemory) GetSongsByGroupID(ctx context.Context, groupID string) ([]entity.Song, error) {
+	songs := s.songsWhere(ctx, func(row songRow) bool {
+		return row.GroupID == groupID
+	})
+
+	slices.SortFunc(songs, func(a, b entity.Song) int {
+		return cmp.Or(compareDates(a.ReleaseDate, b.ReleaseDate), cmp.Compare(a.Title, b.Title))
+	})
+
+	return songs, nil
+}
+
+func (s *SongMemory) GetSongsByAlbumID(ctx context.Context, albumID string) ([]entity.Song, error) {
+	songs := s.songsWhere(ctx, func(row songRow) bool {
+		return row.AlbumID != nil && *row.AlbumID == albumID
+	})
+
+	slices.SortFunc(songs, func(a, b entity.Song) int {
+		return cmp.Or(compareInts(a.DiscNumber, b.DiscNumber), compareInts(a.TrackNumber, b.TrackNumber), cmp.Compare(a.Title, b.Title))
+	})
+
+	return songs, nil
+}
+
+func (s *SongMemory) songsWhere(ctx context.Context, match func(row songRow) bool) []entity.Song {
+	var songs []entity.Song
+
+	_ = s.view(ctx, func(d *data) error {
+		for _, row := range d.songs {
+			if match(row) {
+				songs = append(songs, d.song(row))
+			}
+		}
+		return nil
+	})
+
+	return songs
+}
+
+// song builds the song the way the postgres queries return it, falling back
+// to the album release date.
+func (d *data) song(row songRow) entity.Song {
+	song := entity.Song{
+		ID:               row.ID,
+		Title:            row.Title,
+		GroupName:        d.groups[row.GroupID].Name,
+		ReleaseDate:      copyTime(row.ReleaseDate),
+		Link:             row.Link,
+		AlbumID:          copyString(row.AlbumID),
+		TrackNumber:      copyInt(row.TrackNumber),
+		DiscNumber:       copyInt(row.DiscNumber),
+		EnrichmentStatus: row.EnrichmentStatus,
+		CreatedAt:        copyTime(&row.CreatedAt),
+	}
+
+	if row.ReleaseDate != nil {
+		song.ReleaseDatePrecision = row.ReleaseDatePrecision
+	}
+
+	if row.AlbumID != nil {
+		if album, ok := d.albums[*row.AlbumID]; ok {
+			song.AlbumTitle = copyString(&album.Title)
+			if song.ReleaseDate == nil && album.ReleaseDate != nil {
+				song.ReleaseDate = copyTime(album.ReleaseDate)
+				song.ReleaseDatePrecision = string(partialdate.Day)
+			}
+		}
+	}
+
+	return song
+}
+
+func containsWords(words, wanted []string) bool {
+	for _, word := range wanted {
+		if !slices.Contains(words, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// releaseEnd returns the first day after the period of the release date.
+func releaseEnd(song *entity.Song) time.Time {
+	date := partialdate.Date{Time: *song.ReleaseDate, Precision: partialdate.Precision(song.ReleaseDatePrecision)}
+	return date.End()
+}
+
+func (d *data) songByTitle(title, groupID string) *songRow {
+	for _, row := range d.songs {
+		if row.Title == title && row.GroupID == groupID {
+			return &row
+		}
+	}
+
+	return nil
+}
+
+func (d *data) songTagNames(songID string) []string {
+	var names []string
+	for key := range d.songTags {
+		if key.SongID == songID {
+			names = append(names, d.tags[key.TagID].Name)
+		}
+	}
+
+	return names
+}
+
+// deleteSong removes the song together with its lyrics, tag assignments,
+// enrichment job and playlist entries, leaving gaps in the playlists as the cascade does.
+func (d *data) deleteSong(songID string) {
+	d.deleteLyrics(songID)
+	for key := range d.songTags {
+		if key.SongID == songID {
+			delete(d.songTags, key)
+		}
+	}
+	for id, entry := range d.entries {
+		if entry.SongID == songID {
+			delete(d.entries, id)
+		}
+	}
+	for id, job := range d.enrichmentJobs {
+		if job.SongID == songID {
+			delete(d.enrichmentJobs, id)
+		}
+	}
+	delete(d.songs, songID)
+}
+
+func (d *data) requireSong(songID string) error {
+	if _, ok := d.songs[songID]; !ok {
+		return fmt.Errorf("song %s: %w", songID, repoerrors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// paginate applies LIMIT and OFFSET, where a zero limit means no limit.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset > 0 {
+		if offset >= len(items) {
+			return nil
+		}
+		items = items[offset:]
+	}
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	return items
+}
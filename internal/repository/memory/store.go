@@ -0,0 +1,297 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"effective_mobile_tz/internal/entity"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+type groupRow struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+type albumRow struct {
+	ID          string
+	Title       string
+	GroupID     string
+	ReleaseDate *time.Time
+	Type        string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type songRow struct {
+	ID                   string
+	Title                string
+	GroupID              string
+	ReleaseDate          *time.Time
+	ReleaseDatePrecision string
+	Link                 string
+	AlbumID              *string
+	TrackNumber          *int
+	DiscNumber           *int
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+
+	EnrichmentStatus string
+}
+
+type stanzaRow struct {
+	ID           string
+	SongID       string
+	StanzaNumber int
+	Label        *string
+	CreatedAt    time.Time
+}
+
+type verseRow struct {
+	ID          string
+	SongID      string
+	StanzaID    string
+	Verse       string
+	VerseNumber int
+	CreatedAt   time.Time
+}
+
+type tagRow struct {
+	ID        string
+	Name      string
+	Category  string
+	CreatedAt time.Time
+}
+
+type songTagKey struct {
+	SongID string
+	TagID  string
+}
+
+type playlistRow struct {
+	ID          string
+	Name        string
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type enrichmentJobRow struct {
+	ID          string
+	SongID      string
+	Status      string
+	Attempts    int
+	RunAt       time.Time
+	LockedUntil *time.Time
+	Claim       string
+	LastError   *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type songDetailCacheRow struct {
+	Key       string
+	GroupName string
+	Title     string
+	Found     bool
+	Detail    entity.SongDetail
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+type entryRow struct {
+	ID         string
+	PlaylistID string
+	SongID     string
+	Position   int
+	Note       *string
+	CreatedAt  time.Time
+}
+
+// data holds the tables. Rows are stored by value and never modified in
+// place, so that copying the maps is enough to snapshot the whole store.
+type data struct {
+	groups    map[string]groupRow
+	albums    map[string]albumRow
+	songs     map[string]songRow
+	stanzas   map[string]stanzaRow
+	verses    map[string]verseRow
+	tags      map[string]tagRow
+	songTags  map[songTagKey]time.Time
+	playlists map[string]playlistRow
+	entries   map[string]entryRow
+
+	enrichmentJobs  map[string]enrichmentJobRow
+	songDetailCache map[string]songDetailCacheRow
+
+	searchLanguage string
+}
+
+func newData() *data {
+	return &data{
+		groups:    make(map[string]groupRow),
+		albums:    make(map[string]albumRow),
+		songs:     make(map[string]songRow),
+		stanzas:   make(map[string]stanzaRow),
+		verses:    make(map[string]verseRow),
+		tags:      make(map[string]tagRow),
+		songTags:  make(map[songTagKey]time.Time),
+		playlists: make(map[string]playlistRow),
+		entries:   make(map[string]entryRow),
+
+		enrichmentJobs:  make(map[string]enrichmentJobRow),
+		songDetailCache: make(map[string]songDetailCacheRow),
+	}
+}
+
+func (d *data) clone() *data {
+	return &data{
+		groups:    maps.Clone(d.groups),
+		albums:    maps.Clone(d.albums),
+		songs:     maps.Clone(d.songs),
+		stanzas:   maps.Clone(d.stanzas),
+		verses:    maps.Clone(d.verses),
+		tags:      maps.Clone(d.tags),
+		songTags:  maps.Clone(d.songTags),
+		playlists: maps.Clone(d.playlists),
+		entries:   maps.Clone(d.entries),
+
+		enrichmentJobs:  maps.Clone(d.enrichmentJobs),
+		songDetailCache: maps.Clone(d.songDetailCache),
+
+		searchLanguage: d.searchLanguage,
+	}
+}
+
+// Store is an in-memory database shared by the memory repositories. It keeps
+// the constraints of the postgres schema: unique keys, foreign keys and their
+// cascades.
+//
+// Writers are serialized by txMu: a transaction holds it until it ends, and a
+// write outside of a transaction for its single statement. A transaction
+// works on a copy of the tables that replaces them when it commits, so reads
+// outside of it never see its uncommitted changes.
+type Store struct {
+	txMu sync.Mutex
+	mu   sync.RWMutex
+	data *data
+}
+
+func NewStore() *Store {
+	return &Store{data: newData()}
+}
+
+// view runs fn on the tables of the transaction of ctx, or on the committed
+// tables locked for reading.
+func (s *Store) view(ctx context.Context, fn func(d *data) error) error {
+	if tx := s.transaction(ctx); tx != nil {
+		return fn(tx.data)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(s.data)
+}
+
+// update runs fn on the tables of the transaction of ctx, or on the committed
+// tables locked for writing. fn must check every constraint before changing
+// anything, as a failing statement has no effect.
+func (s *Store) update(ctx context.Context, fn func(d *data) error) error {
+	if tx := s.transaction(ctx); tx != nil {
+		if tx.readOnly {
+			return errReadOnly
+		}
+		return fn(tx.data)
+	}
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fn(s.data)
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Errorf("failed to generate id: %w", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// containsFold mirrors ILIKE '%substr%'.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// compareDates orders dates ascending with NULLs last, as postgres does.
+func compareDates(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	return a.Compare(*b)
+}
+
+// compareInts orders numbers ascending with NULLs last, as postgres does.
+func compareInts(a, b *int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	return *a - *b
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// parseDate accepts the date formats the API passes on to postgres.
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Parse("02.01.2006", value)
+	}
+
+	return date, nil
+}
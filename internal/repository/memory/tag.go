@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type TagMemory struct {
+	*Store
+}
+
+func NewTagMemory(store *Store) *TagMemory {
+	return &TagMemory{Store: store}
+}
+
+func (t *TagMemory) CreateTag(ctx context.Context, tag *entity.Tag) (string, error) {
+	tagID := newID()
+
+	err := t.update(ctx, func(d *data) error {
+		if d.tagByName(tag.Name) != nil {
+			return repoerrors.ErrAlreadyExists
+		}
+
+		d.tags[tagID] = tagRow{ID: tagID, Name: tag.Name, Category: tag.Category, CreatedAt: time.Now()}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return tagID, nil
+}
+
+func (t *TagMemory) GetTags(ctx context.Context, category string) ([]entity.TagUsage, error) {
+	var tags []entity.TagUsage
+
+	err := t.view(ctx, func(d *data) error {
+		for _, row := range d.tags {
+			if category == "" || row.Category == category {
+				tags = append(tags, d.tagUsage(row))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(tags, func(a, b entity.TagUsage) int {
+		return cmp.Or(cmp.Compare(b.UsageCount, a.UsageCount), cmp.Compare(a.Name, b.Name))
+	})
+
+	return tags, nil
+}
+
+func (t *TagMemory) GetTagByID(ctx context.Context, tagID string) (*entity.TagUsage, error) {
+	var tag entity.TagUsage
+
+	err := t.view(ctx, func(d *data) error {
+		row, ok := d.tags[tagID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		tag = d.tagUsage(row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (t *TagMemory) UpdateTag(ctx context.Context, update *entity.TagUpdate) error {
+	if update.Name == nil && update.Category == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	return t.update(ctx, func(d *data) error {
+		row, ok := d.tags[update.ID]
+		if !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		if update.Name != nil {
+			if other := d.tagByName(*update.Name); other != nil && other.ID != row.ID {
+				return repoerrors.ErrAlreadyExists
+			}
+			row.Name = *update.Name
+		}
+		if update.Category != nil {
+			row.Category = *update.Category
+		}
+
+		d.tags[row.ID] = row
+		return nil
+	})
+}
+
+func (t *TagMemory) DeleteTag(ctx context.Context, tagID string) error {
+	return t.update(ctx, func(d *data) error {
+		if _, ok := d.tags[tagID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		for key := range d.songTags {
+			if key.TagID == tagID {
+				delete(d.songTags, key)
+			}
+		}
+		delete(d.tags, tagID)
+		return nil
+	})
+}
+
+func (t *TagMemory) AttachTag(ctx context.Context, songID, tagID string) error {
+	return t.update(ctx, func(d *data) error {
+		if _, ok := d.songs[songID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+		if _, ok := d.tags[tagID]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		key := songTagKey{SongID: songID, TagID: tagID}
+		if _, ok := d.songTags[key]; !ok {
+			d.songTags[key] = time.Now()
+		}
+		return nil
+	})
+}
+
+func (t *TagMemory) DetachTag(ctx context.Context, songID, tagID string) error {
+	return t.update(ctx, func(d *data) error {
+		key := songTagKey{SongID: songID, TagID: tagID}
+		if _, ok := d.songTags[key]; !ok {
+			return repoerrors.ErrNotFound
+		}
+
+		delete(d.songTags, key)
+		return nil
+	})
+}
+
+// GetTagsBySongIDs returns the tags of every given song keyed by song ID.
+func (t *TagMemory) GetTagsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.Tag, error) {
+	tags := make(map[string][]entity.Tag)
+
+	err := t.view(ctx, func(d *data) error {
+		for key := range d.songTags {
+			if slices.Contains(songIDs, key.SongID) {
+				row := d.tags[key.TagID]
+				tags[key.SongID] = append(tags[key.SongID], entity.Tag{ID: row.ID, Name: row.Name, Category: row.Category})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, songTags := range tags {
+		slices.SortFunc(songTags, func(a, b entity.Tag) int {
+			return cmp.Or(cmp.Compare(a.Category, b.Category), cmp.Compare(a.Name, b.Name))
+		})
+	}
+
+	return tags, nil
+}
+
+func (d *data) tagUsage(row tagRow) entity.TagUsage {
+	tag := entity.TagUsage{Tag: entity.Tag{ID: row.ID, Name: row.Name, Category: row.Category}}
+	for key := range d.songTags {
+		if key.TagID == row.ID {
+			tag.UsageCount++
+		}
+	}
+
+	return tag
+}
+
+func (d *data) tagByName(name string) *tagRow {
+	for _, row := range d.tags {
+		if row.Name == name {
+			return &row
+		}
+	}
+
+	return nil
+}
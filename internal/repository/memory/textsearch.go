@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"effective_mobile_tz/pkg/snowball"
+	"effective_mobile_tz/pkg/textquery"
+	"slices"
+)
+
+// lexeme returns the form of the lowercase word the text search language of
+// the store indexes, as to_tsvector does, or "" for a stop word. The english
+// configuration stems words and drops stop words; any other language keeps
+// words as they are written.
+func (d *data) lexeme(word string) string {
+	if d.searchLanguage != "english" {
+		return word
+	}
+	if snowball.IsEnglishStopWord(word) {
+		return ""
+	}
+
+	return snowball.English(word)
+}
+
+// lexemes returns the lexemes of the words of text. Stop words leave an empty
+// lexeme in their place, so that phrases keep their distances.
+func (d *data) lexemes(text string) []string {
+	words := textquery.Tokenize(text)
+	for i, word := range words {
+		words[i] = d.lexeme(word)
+	}
+
+	return words
+}
+
+// plainLexemes returns the lexemes of text a verse must hold to match
+// plainto_tsquery(text): every one but stop words.
+func (d *data) plainLexemes(text string) []string {
+	return slices.DeleteFunc(d.lexemes(text), func(lexeme string) bool {
+		return lexeme == ""
+	})
+}
+
+// searchTerm turns the words of term into lexemes as to_tsquery does: stop
+// words are dropped from the ends of a phrase and match any word within it.
+// It reports false when only stop words are left.
+func (d *data) searchTerm(term textquery.Term) (textquery.Term, bool) {
+	words := make([]string, len(term.Words))
+	for i, word := range term.Words {
+		words[i] = d.lexeme(word)
+	}
+
+	first := slices.IndexFunc(words, func(lexeme string) bool { return lexeme != "" })
+	if first < 0 {
+		return textquery.Term{}, false
+	}
+	last := len(words) - 1
+	for words[last] == "" {
+		last--
+	}
+
+	return textquery.Term{
+		Words:  words[first : last+1],
+		Prefix: term.Prefix && last == len(words)-1,
+	}, true
+}
+
+// searchQuery turns the terms of query into lexemes and drops those of stop
+// words only. It reports false when a clause is left without terms, which no
+// text matches, as postgres ignores an empty tsquery.
+func (d *data) searchQuery(query *textquery.Query) (*textquery.Query, bool) {
+	lexemes := &textquery.Query{}
+	for _, clause := range query.Clauses {
+		var terms []textquery.Term
+		for _, term := range clause {
+			if term, ok := d.searchTerm(term); ok {
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) == 0 {
+			return nil, false
+		}
+		lexemes.Clauses = append(lexemes.Clauses, terms)
+	}
+
+	for _, term := range query.Excluded {
+		if term, ok := d.searchTerm(term); ok {
+			lexemes.Excluded = append(lexemes.Excluded, term)
+		}
+	}
+
+	return lexemes, true
+}
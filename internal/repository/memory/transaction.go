@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+)
+
+// errReadOnly mirrors the error postgres reports for a write in a read-only
+// transaction.
+var errReadOnly = errors.New("cannot write in a read-only transaction")
+
+type txKey struct{}
+
+// tx is a transaction on a store: the copy of the tables it works on.
+type tx struct {
+	store    *Store
+	data     *data
+	readOnly bool
+}
+
+// transaction returns the transaction of ctx on the store, nil if there is
+// none.
+func (s *Store) transaction(ctx context.Context) *tx {
+	if tx, ok := ctx.Value(txKey{}).(*tx); ok && tx.store == s {
+		return tx
+	}
+
+	return nil
+}
+
+type DBTransaction struct {
+	store *Store
+}
+
+func NewDBTransaction(store *Store) *DBTransaction {
+	return &DBTransaction{store: store}
+}
+
+// WithinTransaction runs fn with exclusive write access to the store on a copy
+// of the tables, which replaces them if fn succeeds. Called within another
+// transaction it acts as a savepoint, whose changes go to the outer
+// transaction.
+func (t *DBTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer := t.store.transaction(ctx); outer != nil {
+		inner := &tx{store: t.store, data: outer.data.clone(), readOnly: outer.readOnly}
+		if err := fn(context.WithValue(ctx, txKey{}, inner)); err != nil {
+			return err
+		}
+
+		outer.data = inner.data
+		return nil
+	}
+
+	t.store.txMu.Lock()
+	defer t.store.txMu.Unlock()
+
+	t.store.mu.RLock()
+	inner := &tx{store: t.store, data: t.store.data.clone()}
+	t.store.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, inner)); err != nil {
+		return err
+	}
+
+	t.store.mu.Lock()
+	t.store.data = inner.data
+	t.store.mu.Unlock()
+
+	return nil
+}
+
+// WithinSnapshot runs fn as a read-only transaction on a copy of the tables,
+// so that fn sees a consistent state of the store without holding writers
+// back. Called within another transaction it acts as a savepoint, as the
+// postgres one does.
+func (t *DBTransaction) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.store.transaction(ctx) != nil {
+		return t.WithinTransaction(ctx, fn)
+	}
+
+	t.store.mu.RLock()
+	snapshot := &tx{store: t.store, data: t.store.data.clone(), readOnly: true}
+	t.store.mu.RUnlock()
+
+	return fn(context.WithValue(ctx, txKey{}, snapshot))
+}
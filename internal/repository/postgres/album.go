@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type AlbumPostgres struct {
+	*DB
+}
+
+func NewAlbumPostgres(db *DB) *AlbumPostgres {
+	return &AlbumPostgres{DB: db}
+}
+
+func (a *AlbumPostgres) CreateAlbum(ctx context.Context, album *entity.Album) (string, error) {
+	query := `
+		INSERT INTO albums (title, group_id, release_date, album_type)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var albumID string
+	err := a.QueryRow(ctx, query, album.Title, album.GroupID, album.ReleaseDate, album.Type).Scan(&albumID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23505" {
+				return "", repoerrors.ErrAlreadyExists
+			}
+		}
+		return "", err
+	}
+
+	return albumID, nil
+}
+
+func (a *AlbumPostgres) GetAlbums(ctx context.Context, filter *entity.AlbumFilter) ([]entity.Album, error) {
+	baseQuery := `
+		SELECT al.id, al.title, al.group_id, g.name, al.release_date, al.album_type, COUNT(s.id)
+		FROM albums al
+		JOIN groups g ON al.group_id = g.id
+		LEFT JOIN songs s ON s.album_id = al.id
+	`
+
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.GroupID != "" {
+		conditions = append(conditions, fmt.Sprintf("al.group_id = $%d", argIndex))
+		args = append(args, filter.GroupID)
+		argIndex++
+	}
+
+	if filter.Title != "" {
+		conditions = append(conditions, fmt.Sprintf("al.title ILIKE $%d", argIndex))
+		args = append(args, "%"+filter.Title+"%")
+		argIndex++
+	}
+
+	if len(conditions) > 0 {
+		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	baseQuery += " GROUP BY al.id, g.name ORDER BY g.name, al.release_date NULLS LAST, al.title"
+
+	rows, err := a.Query(ctx, baseQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch albums: %w", err)
+	}
+	defer rows.Close()
+
+	var albums []entity.Album
+	for rows.Next() {
+		var album entity.Album
+		if err := rows.Scan(&album.ID, &album.Title, &album.GroupID, &album.GroupName, &album.ReleaseDate, &album.Type, &album.TracksCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		albums = append(albums, album)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return albums, nil
+}
+
+func (a *AlbumPostgres) GetAlbumByID(ctx context.Context, albumID string) (*entity.Album, error) {
+	query := `
+		SELECT al.id, al.title, al.group_id, g.name, al.release_date, al.album_type, COUNT(s.id)
+		FROM albums al
+		JOIN groups g ON al.group_id = g.id
+		LEFT JOIN songs s ON s.album_id = al.id
+		WHERE al.id = $1
+		GROUP BY al.id, g.name
+	`
+
+	var album entity.Album
+	err := a.QueryRow(ctx, query, albumID).Scan(
+		&album.ID,
+		&album.Title,
+		&album.GroupID,
+		&album.GroupName,
+		&album.ReleaseDate,
+		&album.Type,
+		&album.TracksCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch the album: %w", err)
+	}
+
+	return &album, nil
+}
+
+func (a *AlbumPostgres) UpdateAlbum(ctx context.Context, update *entity.AlbumUpdate) error {
+	baseQuery := `UPDATE albums SET `
+	var updates []string
+	var args []interface{}
+	argIndex := 1
+
+	if update.Title != nil {
+		updates = append(updates, fmt.Sprintf("title = $%d", argIndex))
+		args = append(args, update.Title)
+		argIndex++
+	}
+	if update.ReleaseDate != nil {
+		updates = append(updates, fmt.Sprintf("release_date = NULLIF($%d, '')::date", argIndex))
+		args = append(args, update.ReleaseDate)
+		argIndex++
+	}
+	if update.Type != nil {
+		updates = append(updates, fmt.Sprintf("album_type = $%d", argIndex))
+		args = append(args, update.Type)
+		argIndex++
+	}
+
+	if len(updates) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+	query := baseQuery + strings.Join(updates, ", ") + fmt.Sprintf(" WHERE id = $%d", argIndex)
+	args = append(args, update.ID)
+
+	result, err := a.Exec(ctx, query, args...)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23505" {
+				return repoerrors.ErrAlreadyExists
+			}
+		}
+		return fmt.Errorf("failed to update album with ID %s: %w", update.ID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (a *AlbumPostgres) DeleteAlbum(ctx context.Context, albumID string) error {
+	query := `DELETE FROM albums WHERE id = $1`
+
+	result, err := a.Exec(ctx, query, albumID)
+	if err != nil {
+		return fmt.Errorf("failed to delete album with ID %s: %w", albumID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ArchivePostgres reads the whole library for export and writes archived
+// records back, keeping their IDs.
+type ArchivePostgres struct {
+	*DB
+}
+
+func NewArchivePostgres(db *DB) *ArchivePostgres {
+	return &ArchivePostgres{DB: db}
+}
+
+func (a *ArchivePostgres) ExportGroups(ctx context.Context, fn func(group *entity.ArchiveGroup) error) error {
+	query := `SELECT id, name, created_at FROM groups ORDER BY created_at, id`
+
+	rows, err := a.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group entity.ArchiveGroup
+		if err := rows.Scan(&group.ID, &group.Name, &group.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(&group); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ArchivePostgres) ExportAlbums(ctx context.Context, fn func(album *entity.ArchiveAlbum) error) error {
+	query := `SELECT id, title, group_id, release_date, album_type, created_at FROM albums ORDER BY created_at, id`
+
+	rows, err := a.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch albums: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var album entity.ArchiveAlbum
+		if err := rows.Scan(&album.ID, &album.Title, &album.GroupID, &album.ReleaseDate, &album.Type, &album.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(&album); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ArchivePostgres) ExportTags(ctx context.Context, fn func(tag *entity.ArchiveTag) error) error {
+	query := `SELECT id, name, category FROM tags ORDER BY created_at, id`
+
+	rows, err := a.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag entity.ArchiveTag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Category); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(&tag); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ArchivePostgres) ExportSongs(ctx context.Context, fn func(song *entity.ArchiveSong) error) error {
+	query := `
+		SELECT s.id, s.title, s.group_id, s.release_date, s.release_date_precision, s.link, s.album_id, s.track_number, s.disc_number, s.created_at, s.updated_at,
+			COALESCE(array_agg(st.tag_id::text ORDER BY st.tag_id) FILTER (WHERE st.tag_id IS NOT NULL), '{}')
+		FROM songs s
+		LEFT JOIN song_tags st ON st.song_id = s.id
+		GROUP BY s.id
+		ORDER BY s.created_at, s.id
+	`
+
+	rows, err := a.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch songs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song entity.ArchiveSong
+		var link *string
+		err := rows.Scan(
+			&song.ID,
+			&song.Title,
+			&song.GroupID,
+			&song.ReleaseDate,
+			&song.ReleaseDatePrecision,
+			&link,
+			&song.AlbumID,
+			&song.TrackNumber,
+			&song.DiscNumber,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.TagIDs,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if link != nil {
+			song.Link = *link
+		}
+		if err := fn(&song); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ArchivePostgres) ExportVerses(ctx context.Context, fn func(verse *entity.ArchiveVerse) error) error {
+	query := `
+		SELECT v.id, v.song_id, v.verse, v.verse_number, s.stanza_number, s.label
+		FROM lyrics_verses v
+		JOIN lyrics_stanzas s ON s.id = v.stanza_id
+		ORDER BY v.song_id, v.verse_number
+	`
+
+	rows, err := a.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch lyrics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var verse entity.ArchiveVerse
+		if err := rows.Scan(&verse.ID, &verse.SongID, &verse.Verse, &verse.VerseNumber, &verse.StanzaNumber, &verse.StanzaLabel); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(&verse); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreGroup writes the group according to the restore policy. It returns
+// the ID the group has in the database, which differs from the archived one
+// when an existing group of the same name was kept or overwritten, and
+// whether the archived group was written.
+func (a *ArchivePostgres) RestoreGroup(ctx context.Context, group *entity.ArchiveGroup, policy string) (string, bool, error) {
+	return a.restore(ctx, policy,
+		`INSERT INTO groups (id, name, created_at) VALUES ($1, $2, COALESCE($3, CURRENT_TIMESTAMP))`,
+		`name = EXCLUDED.name`,
+		[]interface{}{group.ID, group.Name, group.CreatedAt},
+		`SELECT id FROM groups WHERE id = $1 OR name = $2 ORDER BY id = $1 DESC LIMIT 1`,
+		`SELECT id FROM groups WHERE id <> $1 AND name = $2`,
+		[]interface{}{group.ID, group.Name},
+	)
+}
+
+func (a *ArchivePostgres) RestoreAlbum(ctx context.Context, album *entity.ArchiveAlbum, policy string) (string, bool, error) {
+	return a.restore(ctx, policy,
+		`INSERT INTO albums (id, title, group_id, release_date, album_type, created_at) VALUES ($1, $2, $3, $4, $5, COALESCE($6, CURRENT_TIMESTAMP))`,
+		`title = EXCLUDED.title, group_id = EXCLUDED.group_id, release_date = EXCLUDED.release_date, album_type = EXCLUDED.album_type, updated_at = CURRENT_TIMESTAMP`,
+		[]interface{}{album.ID, album.Title, album.GroupID, album.ReleaseDate, album.Type, album.CreatedAt},
+		`SELECT id FROM albums WHERE id = $1 OR (title = $2 AND group_id = $3) ORDER BY id = $1 DESC LIMIT 1`,
+		`SELECT id FROM albums WHERE id <> $1 AND title = $2 AND group_id = $3`,
+		[]interface{}{album.ID, album.Title, album.GroupID},
+	)
+}
+
+func (a *ArchivePostgres) RestoreTag(ctx context.Context, tag *entity.ArchiveTag, policy string) (string, bool, error) {
+	return a.restore(ctx, policy,
+		`INSERT INTO tags (id, name, category) VALUES ($1, $2, $3)`,
+		`name = EXCLUDED.name, category = EXCLUDED.category`,
+		[]interface{}{tag.ID, tag.Name, tag.Category},
+		`SELECT id FROM tags WHERE id = $1 OR name = $2 ORDER BY id = $1 DESC LIMIT 1`,
+		`SELECT id FROM tags WHERE id <> $1 AND name = $2`,
+		[]interface{}{tag.ID, tag.Name},
+	)
+}
+
+// RestoreSong writes the song according to the restore policy. A written song
+// gets its tags replaced by song.TagIDs and loses its lyrics, which are
+// restored verse by verse afterwards.
+func (a *ArchivePostgres) RestoreSong(ctx context.Context, song *entity.ArchiveSong, policy string) (string, bool, error) {
+	songID, written, err := a.restore(ctx, policy,
+		`INSERT INTO songs (id, title, group_id, release_date, release_date_precision, link, album_id, track_number, disc_number, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'day'), $6, $7, $8, $9, COALESCE($10, CURRENT_TIMESTAMP), COALESCE($11, CURRENT_TIMESTAMP))`,
+		`title = EXCLUDED.title, group_id = EXCLUDED.group_id, release_date = EXCLUDED.release_date,
+		release_date_precision = EXCLUDED.release_date_precision, link = EXCLUDED.link,
+		album_id = EXCLUDED.album_id, track_number = EXCLUDED.track_number, disc_number = EXCLUDED.disc_number, updated_at = EXCLUDED.updated_at`,
+		[]interface{}{song.ID, song.Title, song.GroupID, song.ReleaseDate, song.ReleaseDatePrecision, song.Link, song.AlbumID, song.TrackNumber, song.DiscNumber, song.CreatedAt, song.UpdatedAt},
+		`SELECT id FROM songs WHERE id = $1 OR (title = $2 AND group_id = $3) ORDER BY id = $1 DESC LIMIT 1`,
+		`SELECT id FROM songs WHERE id <> $1 AND title = $2 AND group_id = $3`,
+		[]interface{}{song.ID, song.Title, song.GroupID},
+	)
+	if err != nil || !written {
+		return songID, written, err
+	}
+
+	if _, err := a.Exec(ctx, `DELETE FROM lyrics_stanzas WHERE song_id = $1`, songID); err != nil {
+		return "", false, fmt.Errorf("failed to delete lyrics of song %s: %w", songID, err)
+	}
+
+	if _, err := a.Exec(ctx, `DELETE FROM song_tags WHERE song_id = $1`, songID); err != nil {
+		return "", false, fmt.Errorf("failed to delete tags of song %s: %w", songID, err)
+	}
+
+	if len(song.TagIDs) > 0 {
+		query := `INSERT INTO song_tags (song_id, tag_id) SELECT $1, unnest($2::uuid[]) ON CONFLICT DO NOTHING`
+		if _, err := a.Exec(ctx, query, songID, song.TagIDs); err != nil {
+			return "", false, fmt.Errorf("failed to restore tags of song %s: %w", songID, err)
+		}
+	}
+
+	return songID, true, nil
+}
+
+// RestoreVerse writes the line into its stanza, which the first line of the
+// stanza creates.
+func (a *ArchivePostgres) RestoreVerse(ctx context.Context, verse *entity.ArchiveVerse) error {
+	query := `
+		WITH existing AS (
+			SELECT id FROM lyrics_stanzas WHERE song_id = $2 AND stanza_number = $5
+		), created AS (
+			INSERT INTO lyrics_stanzas (song_id, stanza_number, label)
+			SELECT $2, $5, $6 WHERE NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING id
+		)
+		INSERT INTO lyrics_verses (id, song_id, stanza_id, verse, verse_number)
+		SELECT $1, $2, id, $3, $4 FROM (SELECT id FROM existing UNION ALL SELECT id FROM created) stanza
+		ON CONFLICT (id) DO UPDATE SET song_id = EXCLUDED.song_id, stanza_id = EXCLUDED.stanza_id,
+			verse = EXCLUDED.verse, verse_number = EXCLUDED.verse_number
+	`
+
+	_, err := a.Exec(ctx, query, verse.ID, verse.SongID, verse.Verse, verse.VerseNumber, verse.StanzaNumber, verse.StanzaLabel)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			switch pgErr.Code {
+			case "23505":
+				// another verse of the song has the same number
+				return repoerrors.ErrAlreadyExists
+			case "23503":
+				return repoerrors.ErrNotFound
+			}
+		}
+		return fmt.Errorf("failed to restore verse %s: %w", verse.ID, err)
+	}
+
+	return nil
+}
+
+// restore inserts the record with the given policy. lookup finds the record
+// holding the ID or the unique key of the archived one, preferring the ID,
+// and clash the one holding the unique key under another ID. Under the
+// overwrite policy that record is overwritten in place of the archived ID,
+// so that the insert does not violate the unique key.
+func (a *ArchivePostgres) restore(ctx context.Context, policy, insert, overwrite string, args []interface{}, lookup, clash string, lookupArgs []interface{}) (string, bool, error) {
+	if policy == entity.RestorePolicyOverwrite {
+		var clashID string
+		err := a.QueryRow(ctx, clash, lookupArgs...).Scan(&clashID)
+		if err == nil {
+			args = append([]interface{}{clashID}, args[1:]...)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return "", false, fmt.Errorf("failed to look up the existing record: %w", err)
+		}
+	}
+
+	query := insert
+	switch policy {
+	case entity.RestorePolicySkip:
+		query += " ON CONFLICT DO NOTHING"
+	case entity.RestorePolicyOverwrite:
+		query += " ON CONFLICT (id) DO UPDATE SET " + overwrite
+	}
+	query += " RETURNING id"
+
+	var id string
+	err := a.QueryRow(ctx, query, args...).Scan(&id)
+	if err == nil {
+		return id, true, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) && policy == entity.RestorePolicySkip {
+		err = a.QueryRow(ctx, lookup, lookupArgs...).Scan(&id)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to look up the existing record: %w", err)
+		}
+		return id, false, nil
+	}
+
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		switch pgErr.Code {
+		case "23505":
+			return "", false, repoerrors.ErrAlreadyExists
+		case "23503":
+			return "", false, repoerrors.ErrNotFound
+		}
+	}
+
+	return "", false, err
+}
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+// EnrichmentPostgres is the queue of enrichment jobs. Workers claim due jobs
+// with FOR UPDATE SKIP LOCKED and hold them for a lease, after which a job
+// left running by a crashed worker is claimed again.
+type EnrichmentPostgres struct {
+	*DB
+}
+
+func NewEnrichmentPostgres(db *DB) *EnrichmentPostgres {
+	return &EnrichmentPostgres{DB: db}
+}
+
+const enrichmentJobColumns = `id, song_id, status, attempts, run_at, locked_until, last_error, created_at, updated_at, COALESCE(claim_token::text, '')`
+
+// CreateEnrichmentJob queues the enrichment of the song and marks the song
+// pending. A job the song already has is reset, and its claim dropped.
+func (e *EnrichmentPostgres) CreateEnrichmentJob(ctx context.Context, songID string) error {
+	query := `
+		WITH job AS (
+			INSERT INTO enrichment_jobs (song_id) VALUES ($1)
+			ON CONFLICT (song_id) DO UPDATE SET status = 'pending', attempts = 0, run_at = CURRENT_TIMESTAMP,
+				locked_until = NULL, claim_token = NULL, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = 'pending' WHERE id IN (SELECT song_id FROM job)
+	`
+
+	_, err := e.Exec(ctx, query, songID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return repoerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to create enrichment job: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimEnrichmentJobs marks up to limit due jobs running until lease has
+// passed, under a new claim token, and counts an attempt for each of them.
+func (e *EnrichmentPostgres) ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) ([]entity.EnrichmentJob, error) {
+	query := `
+		UPDATE enrichment_jobs SET status = 'running', attempts = attempts + 1,
+			locked_until = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond', claim_token = gen_random_uuid(),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM enrichment_jobs
+			WHERE (status = 'pending' AND run_at <= CURRENT_TIMESTAMP)
+				OR (status = 'running' AND locked_until < CURRENT_TIMESTAMP)
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + enrichmentJobColumns
+
+	rows, err := e.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+
+	return scanEnrichmentJobs(rows)
+}
+
+// CompleteEnrichmentJob marks the job and its song done.
+func (e *EnrichmentPostgres) CompleteEnrichmentJob(ctx context.Context, jobID, claim string) error {
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusDone, entity.EnrichmentStatusDone, nil, nil)
+}
+
+// RetryEnrichmentJob puts the job back in the queue, due after delay.
+func (e *EnrichmentPostgres) RetryEnrichmentJob(ctx context.Context, jobID, claim string, delay time.Duration, lastError string) error {
+	delayMillis := delay.Milliseconds()
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusPending, entity.EnrichmentStatusPending, &delayMillis, &lastError)
+}
+
+// FailEnrichmentJob marks the job and its song failed for good.
+func (e *EnrichmentPostgres) FailEnrichmentJob(ctx context.Context, jobID, claim string, lastError string) error {
+	return e.finish(ctx, jobID, claim, entity.EnrichmentStatusFailed, entity.EnrichmentStatusFailed, nil, &lastError)
+}
+
+// finish records the outcome of the job, provided it is still held under the
+// claim.
+func (e *EnrichmentPostgres) finish(ctx context.Context, jobID, claim, jobStatus, songStatus string, delayMillis *int64, lastError *string) error {
+	query := `
+		WITH job AS (
+			UPDATE enrichment_jobs SET status = $3, run_at = COALESCE(CURRENT_TIMESTAMP + $4 * INTERVAL '1 millisecond', run_at), locked_until = NULL,
+				claim_token = NULL, last_error = $5, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND claim_token::text = $2
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = $6 WHERE id IN (SELECT song_id FROM job)
+		RETURNING id
+	`
+
+	var songID string
+	err := e.QueryRow(ctx, query, jobID, claim, jobStatus, delayMillis, lastError, songStatus).Scan(&songID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to update enrichment job %s: %w", jobID, err)
+	}
+
+	return nil
+}
+
+func (e *EnrichmentPostgres) GetEnrichmentJobBySongID(ctx context.Context, songID string) (*entity.EnrichmentJob, error) {
+	query := `SELECT ` + enrichmentJobColumns + ` FROM enrichment_jobs WHERE song_id = $1`
+
+	rows, err := e.Query(ctx, query, songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the enrichment job: %w", err)
+	}
+
+	jobs, err := scanEnrichmentJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, repoerrors.ErrNotFound
+	}
+
+	return &jobs[0], nil
+}
+
+func (e *EnrichmentPostgres) GetEnrichmentJobs(ctx context.Context, filter *entity.EnrichmentJobFilter) ([]entity.EnrichmentJob, error) {
+	query := `SELECT ` + enrichmentJobColumns + ` FROM enrichment_jobs`
+	var args []interface{}
+	argIndex := 1
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" WHERE status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	query += " ORDER BY updated_at DESC, id"
+	if filter.Limit != 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+	if filter.Offset != 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+		argIndex++
+	}
+
+	rows, err := e.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch enrichment jobs: %w", err)
+	}
+
+	return scanEnrichmentJobs(rows)
+}
+
+// RequeueFailedEnrichmentJobs puts failed jobs back in the queue with their
+// attempts reset, only the job of songID when it is not empty. It returns
+// the number of requeued jobs.
+func (e *EnrichmentPostgres) RequeueFailedEnrichmentJobs(ctx context.Context, songID string) (int, error) {
+	query := `
+		WITH job AS (
+			UPDATE enrichment_jobs SET status = 'pending', attempts = 0, run_at = CURRENT_TIMESTAMP,
+				locked_until = NULL, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'failed' AND ($1 = '' OR song_id = NULLIF($1, '')::uuid)
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = 'pending' WHERE id IN (SELECT song_id FROM job)
+	`
+
+	result, err := e.Exec(ctx, query, songID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue enrichment jobs: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func scanEnrichmentJobs(rows pgx.Rows) ([]entity.EnrichmentJob, error) {
+	defer rows.Close()
+
+	var jobs []entity.EnrichmentJob
+	for rows.Next() {
+		var job entity.EnrichmentJob
+		err := rows.Scan(
+			&job.ID,
+			&job.SongID,
+			&job.Status,
+			&job.Attempts,
+			&job.RunAt,
+			&job.LockedUntil,
+			&job.LastError,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+			&job.Claim,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return jobs, nil
+}
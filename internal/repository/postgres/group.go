@@ -5,16 +5,17 @@ import (
 	"effective_mobile_tz/internal/entity"
 	"effective_mobile_tz/internal/repository/repoerrors"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
 type GroupPostgres struct {
-	*pgx.Conn
+	*DB
 }
 
-func NewGroupPostgres(conn *pgx.Conn) *GroupPostgres {
-	return &GroupPostgres{Conn: conn}
+func NewGroupPostgres(db *DB) *GroupPostgres {
+	return &GroupPostgres{DB: db}
 }
 
 func (g *GroupPostgres) CreateGroup(ctx context.Context, name string) (string, error) {
@@ -48,3 +49,105 @@ func (g *GroupPostgres) GetGroupIDByName(ctx context.Context, name string) (stri
 
 	return group.ID, nil
 }
+
+func (g *GroupPostgres) GetGroups(ctx context.Context) ([]entity.Group, error) {
+	query := `
+		SELECT g.id, g.name, COUNT(s.id)
+		FROM groups g
+		LEFT JOIN songs s ON s.group_id = g.id
+		GROUP BY g.id, g.name
+		ORDER BY g.name
+	`
+
+	rows, err := g.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []entity.Group
+	for rows.Next() {
+		var group entity.Group
+		if err := rows.Scan(&group.ID, &group.Name, &group.SongsCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (g *GroupPostgres) GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error) {
+	query := `
+		SELECT g.id, g.name, COUNT(s.id)
+		FROM groups g
+		LEFT JOIN songs s ON s.group_id = g.id
+		WHERE g.id = $1
+		GROUP BY g.id, g.name
+	`
+
+	var group entity.Group
+	err := g.QueryRow(ctx, query, groupID).Scan(&group.ID, &group.Name, &group.SongsCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch the group: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (g *GroupPostgres) RenameGroup(ctx context.Context, groupID, name string) error {
+	query := `UPDATE groups SET name = $1 WHERE id = $2`
+
+	result, err := g.Exec(ctx, query, name, groupID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23505" {
+				return repoerrors.ErrAlreadyExists
+			}
+		}
+		return fmt.Errorf("failed to rename group with ID %s: %w", groupID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// LockGroup locks the row of the group, which the foreign key check of a
+// song inserted into or moved to the group waits for.
+func (g *GroupPostgres) LockGroup(ctx context.Context, groupID string) error {
+	var id string
+	err := g.QueryRow(ctx, `SELECT id FROM groups WHERE id = $1 FOR UPDATE`, groupID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to lock group with ID %s: %w", groupID, err)
+	}
+
+	return nil
+}
+
+func (g *GroupPostgres) DeleteGroup(ctx context.Context, groupID string) error {
+	query := `DELETE FROM groups WHERE id = $1`
+
+	result, err := g.Exec(ctx, query, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to delete group with ID %s: %w", groupID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -3,65 +3,221 @@ package postgres
 import (
 	"context"
 	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
 type LyricsPostgres struct {
-	*pgx.Conn
+	*DB
 }
 
-func NewLyricsPostgres(conn *pgx.Conn) *LyricsPostgres {
-	return &LyricsPostgres{Conn: conn}
+func NewLyricsPostgres(db *DB) *LyricsPostgres {
+	return &LyricsPostgres{DB: db}
 }
 
-func (l *LyricsPostgres) AddLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) error {
-	query := `INSERT INTO lyrics_verses (song_id, verse, verse_number) VALUES ($1, $2, $3)`
+// AddLyricsStanza stores the stanza together with its lines.
+func (l *LyricsPostgres) AddLyricsStanza(ctx context.Context, stanza *entity.LyricsStanza) error {
+	query := `INSERT INTO lyrics_stanzas (song_id, stanza_number, label) VALUES ($1, $2, $3) RETURNING id`
 
-	_, err := l.Exec(ctx, query, verse.SongID, verse.Verse, verse.VerseNumber)
+	err := l.QueryRow(ctx, query, stanza.SongID, stanza.StanzaNumber, stanza.Label).Scan(&stanza.ID)
 	if err != nil {
-		return err
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return repoerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to insert stanza %d of song %s: %w", stanza.StanzaNumber, stanza.SongID, err)
+	}
+
+	verses := make([]string, 0, len(stanza.Verses))
+	verseNumbers := make([]int, 0, len(stanza.Verses))
+	for _, verse := range stanza.Verses {
+		verses = append(verses, verse.Verse)
+		verseNumbers = append(verseNumbers, verse.VerseNumber)
+	}
+
+	query = `
+		INSERT INTO lyrics_verses (song_id, stanza_id, verse, verse_number)
+		SELECT $1, $2, verse, verse_number FROM unnest($3::text[], $4::int[]) AS v(verse, verse_number)
+	`
+
+	if _, err := l.Exec(ctx, query, stanza.SongID, stanza.ID, verses, verseNumbers); err != nil {
+		return fmt.Errorf("failed to insert lines of stanza %d of song %s: %w", stanza.StanzaNumber, stanza.SongID, err)
 	}
 
 	return nil
 }
 
-func (l *LyricsPostgres) GetAllLyrics(ctx context.Context, songID string) ([]entity.LyricsVerse, error) {
-	query := `SELECT verse_number, verse FROM lyrics_verses WHERE song_id = $1 ORDER BY verse_number;`
+// GetLyricsBySongIDs returns the stanzas of every given song keyed by song ID,
+// ordered by number, in a single query.
+func (l *LyricsPostgres) GetLyricsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.LyricsStanza, error) {
+	query := `
+	SELECT s.id, s.song_id, s.stanza_number, s.label, v.verse_number, v.verse
+	FROM lyrics_stanzas s
+	JOIN lyrics_verses v ON v.stanza_id = s.id
+	WHERE s.song_id = ANY($1)
+	ORDER BY s.song_id, s.stanza_number, v.verse_number;
+`
 
-	rows, err := l.Query(ctx, query, songID)
+	stanzas, err := l.queryStanzas(ctx, query, songIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch lyrics: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	var verses []entity.LyricsVerse
+	lyrics := make(map[string][]entity.LyricsStanza)
+	for _, stanza := range stanzas {
+		lyrics[stanza.SongID] = append(lyrics[stanza.SongID], stanza)
+	}
+
+	return lyrics, nil
+}
+
+// GetLyricsStanzas returns the stanzas of the song numbered from first to
+// last, with their lines.
+func (l *LyricsPostgres) GetLyricsStanzas(ctx context.Context, songID string, first, last int) ([]entity.LyricsStanza, error) {
+	query := `
+	SELECT s.id, s.song_id, s.stanza_number, s.label, v.verse_number, v.verse
+	FROM lyrics_stanzas s
+	JOIN lyrics_verses v ON v.stanza_id = s.id
+	WHERE s.song_id = $1 AND s.stanza_number BETWEEN $2 AND $3
+	ORDER BY s.stanza_number, v.verse_number;
+`
+
+	return l.queryStanzas(ctx, query, songID, first, last)
+}
+
+// queryStanzas reads rows of stanzas joined with their lines, ordered by
+// stanza, into stanzas.
+func (l *LyricsPostgres) queryStanzas(ctx context.Context, query string, args ...interface{}) ([]entity.LyricsStanza, error) {
+	rows, err := l.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stanzas: %w", err)
+	}
+	defer rows.Close()
 
+	var stanzas []entity.LyricsStanza
 	for rows.Next() {
+		var stanza entity.LyricsStanza
 		var verse entity.LyricsVerse
-		if err := rows.Scan(&verse.VerseNumber, &verse.Verse); err != nil {
+		if err := rows.Scan(&stanza.ID, &stanza.SongID, &stanza.StanzaNumber, &stanza.Label, &verse.VerseNumber, &verse.Verse); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
-		verses = append(verses, verse)
+		verse.StanzaNumber = stanza.StanzaNumber
+
+		if last := len(stanzas) - 1; last < 0 || stanzas[last].ID != stanza.ID {
+			stanzas = append(stanzas, stanza)
+		}
+		last := &stanzas[len(stanzas)-1]
+		last.Verses = append(last.Verses, verse)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return verses, nil
+	return stanzas, nil
+}
+
+func (l *LyricsPostgres) UpdateLyricsStanza(ctx context.Context, songID string, stanzaNumber int, label *string) error {
+	query := `UPDATE lyrics_stanzas SET label = $3 WHERE song_id = $1 AND stanza_number = $2`
+
+	result, err := l.Exec(ctx, query, songID, stanzaNumber, label)
+	if err != nil {
+		return fmt.Errorf("failed to update stanza %d of song %s: %w", stanzaNumber, songID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteEmptyLyricsStanzas deletes the stanzas of the song left without
+// lines and renumbers the others.
+func (l *LyricsPostgres) DeleteEmptyLyricsStanzas(ctx context.Context, songID string) error {
+	query := `
+		DELETE FROM lyrics_stanzas s
+		WHERE s.song_id = $1 AND NOT EXISTS (SELECT 1 FROM lyrics_verses v WHERE v.stanza_id = s.id)
+	`
+
+	result, err := l.Exec(ctx, query, songID)
+	if err != nil {
+		return fmt.Errorf("failed to delete empty stanzas of song %s: %w", songID, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+
+	query = `
+		UPDATE lyrics_stanzas s SET stanza_number = ranked.new_number
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY stanza_number) AS new_number
+			FROM lyrics_stanzas
+			WHERE song_id = $1
+		) ranked
+		WHERE s.id = ranked.id AND s.stanza_number <> ranked.new_number
+	`
+
+	_, err = l.Exec(ctx, query, songID)
+	if err != nil {
+		return fmt.Errorf("failed to renumber stanzas of song %s: %w", songID, err)
+	}
+
+	return nil
 }
 
 func (l *LyricsPostgres) GetPaginatedLyrics(ctx context.Context, songID string, limit, offset int) ([]entity.LyricsVerse, error) {
 	query := `
-	SELECT verse_number, verse
-	FROM lyrics_verses
-	WHERE song_id = $1
-	ORDER BY verse_number
+	SELECT v.id, v.verse_number, v.verse, s.stanza_number
+	FROM lyrics_verses v
+	JOIN lyrics_stanzas s ON s.id = v.stanza_id
+	WHERE v.song_id = $1
+	ORDER BY v.verse_number
 	LIMIT $2 OFFSET $3;
 `
 
-	rows, err := l.Query(ctx, query, songID, limit, offset)
+	return l.queryVerses(ctx, query, songID, limit, offset)
+}
+
+// GetLyricsAfter returns up to limit verses of the song numbered above
+// verseNumber, in order.
+func (l *LyricsPostgres) GetLyricsAfter(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error) {
+	query := `
+	SELECT v.id, v.verse_number, v.verse, s.stanza_number
+	FROM lyrics_verses v
+	JOIN lyrics_stanzas s ON s.id = v.stanza_id
+	WHERE v.song_id = $1 AND v.verse_number > $2
+	ORDER BY v.verse_number
+	LIMIT $3;
+`
+
+	return l.queryVerses(ctx, query, songID, verseNumber, limit)
+}
+
+// GetLyricsBefore returns up to limit verses of the song numbered below
+// verseNumber, the closest to it, in order.
+func (l *LyricsPostgres) GetLyricsBefore(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error) {
+	query := `
+	SELECT id, verse_number, verse, stanza_number
+	FROM (
+		SELECT v.id, v.verse_number, v.verse, s.stanza_number
+		FROM lyrics_verses v
+		JOIN lyrics_stanzas s ON s.id = v.stanza_id
+		WHERE v.song_id = $1 AND v.verse_number < $2
+		ORDER BY v.verse_number DESC
+		LIMIT $3
+	) v
+	ORDER BY verse_number;
+`
+
+	return l.queryVerses(ctx, query, songID, verseNumber, limit)
+}
+
+func (l *LyricsPostgres) queryVerses(ctx context.Context, query string, args ...interface{}) ([]entity.LyricsVerse, error) {
+	rows, err := l.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch lyrics: %w", err)
 	}
@@ -70,7 +226,7 @@ func (l *LyricsPostgres) GetPaginatedLyrics(ctx context.Context, songID string,
 	var lyrics []entity.LyricsVerse
 	for rows.Next() {
 		var verse entity.LyricsVerse
-		if err := rows.Scan(&verse.VerseNumber, &verse.Verse); err != nil {
+		if err := rows.Scan(&verse.ID, &verse.VerseNumber, &verse.Verse, &verse.StanzaNumber); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
@@ -84,8 +240,154 @@ func (l *LyricsPostgres) GetPaginatedLyrics(ctx context.Context, songID string,
 	return lyrics, nil
 }
 
+func (l *LyricsPostgres) GetLyricsVerse(ctx context.Context, songID string, verseNumber int) (*entity.LyricsVerse, error) {
+	query := `
+	SELECT v.id, v.song_id, v.verse, v.verse_number, s.stanza_number
+	FROM lyrics_verses v
+	JOIN lyrics_stanzas s ON s.id = v.stanza_id
+	WHERE v.song_id = $1 AND v.verse_number = $2
+`
+
+	var verse entity.LyricsVerse
+	err := l.QueryRow(ctx, query, songID, verseNumber).Scan(&verse.ID, &verse.SongID, &verse.Verse, &verse.VerseNumber, &verse.StanzaNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch verse %d of song %s: %w", verseNumber, songID, err)
+	}
+
+	return &verse, nil
+}
+
+// GetLyricsVerseByID returns the verse of the song by its ID, whatever its
+// number is now.
+func (l *LyricsPostgres) GetLyricsVerseByID(ctx context.Context, songID, verseID string) (*entity.LyricsVerse, error) {
+	query := `
+	SELECT v.id, v.song_id, v.verse, v.verse_number, s.stanza_number
+	FROM lyrics_verses v
+	JOIN lyrics_stanzas s ON s.id = v.stanza_id
+	WHERE v.song_id = $1 AND v.id = $2
+`
+
+	var verse entity.LyricsVerse
+	err := l.QueryRow(ctx, query, songID, verseID).Scan(&verse.ID, &verse.SongID, &verse.Verse, &verse.VerseNumber, &verse.StanzaNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch verse %s of song %s: %w", verseID, songID, err)
+	}
+
+	return &verse, nil
+}
+
+func (l *LyricsPostgres) CountLyricsVerses(ctx context.Context, songID string) (int, error) {
+	query := `SELECT COUNT(*) FROM lyrics_verses WHERE song_id = $1`
+
+	var count int
+	if err := l.QueryRow(ctx, query, songID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count verses of song %s: %w", songID, err)
+	}
+
+	return count, nil
+}
+
+func (l *LyricsPostgres) UpdateLyricsVerse(ctx context.Context, songID string, verseNumber int, verse string) error {
+	query := `UPDATE lyrics_verses SET verse = $3 WHERE song_id = $1 AND verse_number = $2`
+
+	result, err := l.Exec(ctx, query, songID, verseNumber, verse)
+	if err != nil {
+		return fmt.Errorf("failed to update verse %d of song %s: %w", verseNumber, songID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// InsertLyricsVerse puts the verse at verse.VerseNumber into the stanza
+// numbered verse.StanzaNumber, shifting the verses at and after that number
+// one place down.
+func (l *LyricsPostgres) InsertLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) error {
+	query := `
+		WITH shifted AS (
+			UPDATE lyrics_verses SET verse_number = verse_number + 1
+			WHERE song_id = $1 AND verse_number >= $3
+		)
+		INSERT INTO lyrics_verses (song_id, stanza_id, verse, verse_number)
+		SELECT $1, id, $2, $3 FROM lyrics_stanzas WHERE song_id = $1 AND stanza_number = $4
+	`
+
+	result, err := l.Exec(ctx, query, verse.SongID, verse.Verse, verse.VerseNumber, verse.StanzaNumber)
+	if err != nil {
+		return fmt.Errorf("failed to insert verse %d of song %s: %w", verse.VerseNumber, verse.SongID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// MoveLyricsVerse moves the verse to position, into the stanza numbered
+// stanzaNumber, shifting the verses in between so that verse numbers stay
+// contiguous.
+func (l *LyricsPostgres) MoveLyricsVerse(ctx context.Context, songID string, verseNumber, position, stanzaNumber int) error {
+	query := `
+		UPDATE lyrics_verses SET verse_number = CASE
+			WHEN verse_number = $2 THEN $3
+			WHEN $2 < $3 THEN verse_number - 1
+			ELSE verse_number + 1
+		END, stanza_id = CASE
+			WHEN verse_number = $2 THEN (SELECT id FROM lyrics_stanzas WHERE song_id = $1 AND stanza_number = $4)
+			ELSE stanza_id
+		END
+		WHERE song_id = $1 AND verse_number BETWEEN LEAST($2::int, $3::int) AND GREATEST($2::int, $3::int)
+			AND EXISTS (SELECT 1 FROM lyrics_verses WHERE song_id = $1 AND verse_number = $2)
+	`
+
+	result, err := l.Exec(ctx, query, songID, verseNumber, position, stanzaNumber)
+	if err != nil {
+		return fmt.Errorf("failed to move verse %d of song %s: %w", verseNumber, songID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteLyricsVerse deletes the verse and closes the gap it leaves.
+func (l *LyricsPostgres) DeleteLyricsVerse(ctx context.Context, songID string, verseNumber int) error {
+	query := `DELETE FROM lyrics_verses WHERE song_id = $1 AND verse_number = $2`
+
+	result, err := l.Exec(ctx, query, songID, verseNumber)
+	if err != nil {
+		return fmt.Errorf("failed to delete verse %d of song %s: %w", verseNumber, songID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	query = `UPDATE lyrics_verses SET verse_number = verse_number - 1 WHERE song_id = $1 AND verse_number > $2`
+
+	_, err = l.Exec(ctx, query, songID, verseNumber)
+	if err != nil {
+		return fmt.Errorf("failed to shift verses of song %s: %w", songID, err)
+	}
+
+	return nil
+}
+
+// DeleteLyrics deletes the stanzas of the song, and their lines with them.
 func (l *LyricsPostgres) DeleteLyrics(ctx context.Context, songID string) error {
-	query := `DELETE FROM lyrics_verses WHERE song_id = $1`
+	query := `DELETE FROM lyrics_stanzas WHERE song_id = $1`
 
 	_, err := l.Exec(ctx, query, songID)
 	if err != nil {
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+// SongDetailCachePostgres keeps lookups of the metadata provider across
+// restarts and instances.
+type SongDetailCachePostgres struct {
+	*DB
+}
+
+func NewSongDetailCachePostgres(db *DB) *SongDetailCachePostgres {
+	return &SongDetailCachePostgres{DB: db}
+}
+
+func (c *SongDetailCachePostgres) GetSongDetailCache(ctx context.Context, key string) (*entity.SongDetailCacheEntry, error) {
+	query := `
+		SELECT cache_key, group_name, title, found, COALESCE(release_date, ''), COALESCE(text, ''), COALESCE(link, ''), expires_at
+		FROM song_detail_cache
+		WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`
+
+	var entry entity.SongDetailCacheEntry
+	err := c.QueryRow(ctx, query, key).Scan(
+		&entry.Key,
+		&entry.GroupName,
+		&entry.Title,
+		&entry.Found,
+		&entry.Detail.ReleaseDate,
+		&entry.Detail.Text,
+		&entry.Detail.Link,
+		&entry.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch the cached song detail: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// SetSongDetailCache stores the entry for ttl, or until purged when ttl is
+// not positive.
+func (c *SongDetailCachePostgres) SetSongDetailCache(ctx context.Context, entry *entity.SongDetailCacheEntry, ttl time.Duration) error {
+	query := `
+		INSERT INTO song_detail_cache (cache_key, group_name, title, found, release_date, text, link, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP + $8 * INTERVAL '1 millisecond')
+		ON CONFLICT (cache_key) DO UPDATE SET group_name = EXCLUDED.group_name, title = EXCLUDED.title, found = EXCLUDED.found,
+			release_date = EXCLUDED.release_date, text = EXCLUDED.text, link = EXCLUDED.link,
+			expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP
+	`
+
+	var ttlMillis *int64
+	if ttl > 0 {
+		millis := ttl.Milliseconds()
+		ttlMillis = &millis
+	}
+
+	_, err := c.Exec(ctx, query, entry.Key, entry.GroupName, entry.Title, entry.Found,
+		entry.Detail.ReleaseDate, entry.Detail.Text, entry.Detail.Link, ttlMillis)
+	if err != nil {
+		return fmt.Errorf("failed to cache the song detail: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSongDetailCache removes the entry of key, or every entry when key is
+// empty, and returns the number of live entries removed.
+func (c *SongDetailCachePostgres) DeleteSongDetailCache(ctx context.Context, key string) (int, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM song_detail_cache WHERE $1 = '' OR cache_key = $1
+			RETURNING expires_at
+		)
+		SELECT COUNT(*) FROM deleted WHERE expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP
+	`
+
+	var deleted int
+	if err := c.QueryRow(ctx, query, key).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("failed to purge the song detail cache: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CountSongDetailCache returns the number of live entries.
+func (c *SongDetailCachePostgres) CountSongDetailCache(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM song_detail_cache WHERE expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP`
+
+	var count int
+	if err := c.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cached song details: %w", err)
+	}
+
+	return count, nil
+}
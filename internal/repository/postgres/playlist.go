@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type PlaylistPostgres struct {
+	*DB
+}
+
+func NewPlaylistPostgres(db *DB) *PlaylistPostgres {
+	return &PlaylistPostgres{DB: db}
+}
+
+func (p *PlaylistPostgres) CreatePlaylist(ctx context.Context, playlist *entity.Playlist) (string, error) {
+	query := `INSERT INTO playlists (name, description) VALUES ($1, $2) RETURNING id`
+	var playlistID string
+
+	err := p.QueryRow(ctx, query, playlist.Name, playlist.Description).Scan(&playlistID)
+	if err != nil {
+		return "", err
+	}
+
+	return playlistID, nil
+}
+
+func (p *PlaylistPostgres) GetPlaylists(ctx context.Context) ([]entity.Playlist, error) {
+	query := `
+		SELECT pl.id, pl.name, pl.description, pl.created_at, pl.updated_at, COUNT(pe.id)
+		FROM playlists pl
+		LEFT JOIN playlist_entries pe ON pe.playlist_id = pl.id
+		GROUP BY pl.id
+		ORDER BY pl.name, pl.created_at
+	`
+
+	rows, err := p.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	defer rows.Close()
+
+	var playlists []entity.Playlist
+	for rows.Next() {
+		var playlist entity.Playlist
+		if err := rows.Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.EntriesCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return playlists, nil
+}
+
+func (p *PlaylistPostgres) GetPlaylistByID(ctx context.Context, playlistID string) (*entity.Playlist, error) {
+	query := `
+		SELECT pl.id, pl.name, pl.description, pl.created_at, pl.updated_at, COUNT(pe.id)
+		FROM playlists pl
+		LEFT JOIN playlist_entries pe ON pe.playlist_id = pl.id
+		WHERE pl.id = $1
+		GROUP BY pl.id
+	`
+
+	var playlist entity.Playlist
+	err := p.QueryRow(ctx, query, playlistID).Scan(
+		&playlist.ID,
+		&playlist.Name,
+		&playlist.Description,
+		&playlist.CreatedAt,
+		&playlist.UpdatedAt,
+		&playlist.EntriesCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch the playlist: %w", err)
+	}
+
+	return &playlist, nil
+}
+
+func (p *PlaylistPostgres) UpdatePlaylist(ctx context.Context, update *entity.PlaylistUpdate) error {
+	baseQuery := `UPDATE playlists SET `
+	var updates []string
+	var args []interface{}
+	argIndex := 1
+
+	if update.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, update.Name)
+		argIndex++
+	}
+	if update.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, update.Description)
+		argIndex++
+	}
+
+	if len(updates) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+	query := baseQuery + strings.Join(updates, ", ") + fmt.Sprintf(" WHERE id = $%d", argIndex)
+	args = append(args, update.ID)
+
+	result, err := p.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update playlist with ID %s: %w", update.ID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *PlaylistPostgres) DeletePlaylist(ctx context.Context, playlistID string) error {
+	query := `DELETE FROM playlists WHERE id = $1`
+
+	result, err := p.Exec(ctx, query, playlistID)
+	if err != nil {
+		return fmt.Errorf("failed to delete playlist with ID %s: %w", playlistID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *PlaylistPostgres) GetPlaylistEntries(ctx context.Context, playlistID string) ([]entity.PlaylistEntry, error) {
+	query := `
+		SELECT id, playlist_id, song_id, position, note
+		FROM playlist_entries
+		WHERE playlist_id = $1
+		ORDER BY position
+	`
+
+	rows, err := p.Query(ctx, query, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch playlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entity.PlaylistEntry
+	for rows.Next() {
+		var entry entity.PlaylistEntry
+		if err := rows.Scan(&entry.ID, &entry.PlaylistID, &entry.SongID, &entry.Position, &entry.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// InsertPlaylistEntry puts the entry at entry.Position, shifting the entries
+// at and after that position one place down.
+func (p *PlaylistPostgres) InsertPlaylistEntry(ctx context.Context, entry *entity.PlaylistEntry) (string, error) {
+	query := `
+		WITH shifted AS (
+			UPDATE playlist_entries SET position = position + 1
+			WHERE playlist_id = $1 AND position >= $3
+		)
+		INSERT INTO playlist_entries (playlist_id, song_id, position, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var entryID string
+	err := p.QueryRow(ctx, query, entry.PlaylistID, entry.SongID, entry.Position, entry.Note).Scan(&entryID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23503" {
+				return "", repoerrors.ErrNotFound
+			}
+		}
+		return "", fmt.Errorf("failed to insert playlist entry: %w", err)
+	}
+
+	return entryID, nil
+}
+
+// MovePlaylistEntry moves the entry to position, shifting the entries in
+// between so that positions stay contiguous.
+func (p *PlaylistPostgres) MovePlaylistEntry(ctx context.Context, playlistID, entryID string, position int) error {
+	query := `
+		WITH moved AS (
+			SELECT position AS old_position FROM playlist_entries WHERE id = $2 AND playlist_id = $1
+		)
+		UPDATE playlist_entries pe SET position = CASE
+			WHEN pe.id = $2 THEN $3
+			WHEN moved.old_position < $3 AND pe.position > moved.old_position AND pe.position <= $3 THEN pe.position - 1
+			WHEN moved.old_position > $3 AND pe.position >= $3 AND pe.position < moved.old_position THEN pe.position + 1
+			ELSE pe.position
+		END
+		FROM moved
+		WHERE pe.playlist_id = $1
+	`
+
+	result, err := p.Exec(ctx, query, playlistID, entryID, position)
+	if err != nil {
+		return fmt.Errorf("failed to move playlist entry %s: %w", entryID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// RemovePlaylistEntry deletes the entry and closes the gap it leaves.
+func (p *PlaylistPostgres) RemovePlaylistEntry(ctx context.Context, playlistID, entryID string) error {
+	query := `DELETE FROM playlist_entries WHERE id = $1 AND playlist_id = $2 RETURNING position`
+
+	var position int
+	err := p.QueryRow(ctx, query, entryID, playlistID).Scan(&position)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to delete playlist entry %s: %w", entryID, err)
+	}
+
+	query = `UPDATE playlist_entries SET position = position - 1 WHERE playlist_id = $1 AND position > $2`
+
+	_, err = p.Exec(ctx, query, playlistID, position)
+	if err != nil {
+		return fmt.Errorf("failed to shift playlist entries: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSongFromPlaylists deletes every entry of the song and renumbers the
+// affected playlists.
+func (p *PlaylistPostgres) RemoveSongFromPlaylists(ctx context.Context, songID string) error {
+	query := `DELETE FROM playlist_entries WHERE song_id = $1 RETURNING playlist_id`
+
+	rows, err := p.Query(ctx, query, songID)
+	if err != nil {
+		return fmt.Errorf("failed to delete playlist entries of song %s: %w", songID, err)
+	}
+
+	var playlistIDs []string
+	for rows.Next() {
+		var playlistID string
+		if err := rows.Scan(&playlistID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		playlistIDs = append(playlistIDs, playlistID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(playlistIDs) == 0 {
+		return nil
+	}
+
+	query = `
+		UPDATE playlist_entries pe SET position = ranked.new_position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY playlist_id ORDER BY position) AS new_position
+			FROM playlist_entries
+			WHERE playlist_id = ANY($1)
+		) ranked
+		WHERE pe.id = ranked.id AND pe.position <> ranked.new_position
+	`
+
+	_, err = p.Exec(ctx, query, playlistIDs)
+	if err != nil {
+		return fmt.Errorf("failed to renumber playlist entries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/pkg/textquery"
+	"fmt"
+	"strings"
+)
+
+// SearchPostgres searches the lyrics through the search_vector of every
+// verse, which a trigger keeps up to date in the language of search_settings.
+type SearchPostgres struct {
+	*DB
+}
+
+func NewSearchPostgres(db *DB) *SearchPostgres {
+	return &SearchPostgres{DB: db}
+}
+
+// SearchSongs returns the songs whose lyrics match every clause of the query
+// and none of its excluded terms, best ranked first. A verse matches when it
+// holds any term of the query, and the rank of a song is the sum of the ranks
+// of its matching verses.
+func (s *SearchPostgres) SearchSongs(ctx context.Context, query *textquery.Query, limit, offset int) ([]entity.SongSearchResult, error) {
+	args := []interface{}{query.AnyTSQuery()}
+	argIndex := 2
+
+	// with a single clause every matching verse matches the clause
+	var having []string
+	if len(query.Clauses) > 1 {
+		for _, clause := range query.Clauses {
+			having = append(having, fmt.Sprintf("bool_or(m.search_vector @@ to_tsquery((SELECT language FROM q), $%d))", argIndex))
+			args = append(args, textquery.ClauseTSQuery(clause))
+			argIndex++
+		}
+	}
+
+	var conditions []string
+	if excluded := query.ExcludedTSQuery(); excluded != "" {
+		conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM lyrics_verses x WHERE x.song_id = f.song_id AND x.search_vector @@ to_tsquery(q.language, $%d))", argIndex))
+		args = append(args, excluded)
+		argIndex++
+	}
+
+	sqlQuery := `
+		WITH q AS (
+			SELECT language, to_tsquery(language, $1) AS any_query FROM search_settings
+		), matches AS (
+			SELECT l.song_id, l.verse_number, l.verse, l.search_vector, ts_rank(l.search_vector, q.any_query) AS rank
+			FROM lyrics_verses l, q
+			WHERE l.search_vector @@ q.any_query
+		), found AS (
+			SELECT m.song_id, SUM(m.rank)::float8 AS rank, array_agg(m.verse_number ORDER BY m.verse_number) AS verse_numbers,
+				string_agg(m.verse, E'\n' ORDER BY m.verse_number) AS verses
+			FROM matches m
+			GROUP BY m.song_id`
+	if len(having) > 0 {
+		sqlQuery += `
+			HAVING ` + strings.Join(having, " AND ")
+	}
+	sqlQuery += `
+		)
+		SELECT s.id, s.title, g.name, f.rank, f.verse_numbers,
+			ts_headline(q.language, f.verses, q.any_query, 'MaxFragments=3, MinWords=3, MaxWords=15, FragmentDelimiter=" ... "')
+		FROM found f
+		JOIN songs s ON s.id = f.song_id
+		JOIN groups g ON s.group_id = g.id
+		CROSS JOIN q`
+	if len(conditions) > 0 {
+		sqlQuery += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += `
+		ORDER BY f.rank DESC, s.id`
+
+	if limit != 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, limit)
+		argIndex++
+	}
+	if offset != 0 {
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, offset)
+		argIndex++
+	}
+
+	rows, err := s.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search songs: %w", err)
+	}
+	defer rows.Close()
+
+	var results []entity.SongSearchResult
+	for rows.Next() {
+		var result entity.SongSearchResult
+		if err := rows.Scan(&result.SongID, &result.Title, &result.GroupName, &result.Rank, &result.Verses, &result.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return results, nil
+}
+
+// SetSearchLanguage switches the search to the text search configuration
+// named language and reindexes the lyrics, unless it is already in use. It
+// reports whether the lyrics were reindexed.
+func (s *SearchPostgres) SetSearchLanguage(ctx context.Context, language string) (bool, error) {
+	result, err := s.Exec(ctx, `UPDATE search_settings SET language = $1::regconfig WHERE language <> $1::regconfig`, language)
+	if err != nil {
+		return false, fmt.Errorf("failed to set the search language %q: %w", language, err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = s.Exec(ctx, `UPDATE lyrics_verses SET search_vector = to_tsvector($1::regconfig, verse)`, language)
+	if err != nil {
+		return false, fmt.Errorf("failed to reindex lyrics: %w", err)
+	}
+
+	return true, nil
+}
+
+// SuggestTitles returns the song titles most alike the query, best first.
+// Titles equally alike are ordered by their similarity as a whole, so that a
+// title the query spells out comes before longer titles holding it.
+func (s *SearchPostgres) SuggestTitles(ctx context.Context, query string, threshold float64, limit int) ([]entity.TitleSuggestion, error) {
+	sqlQuery := `
+		SELECT s.id, s.title, g.name, word_similarity($1, s.title)::float8 AS score
+		FROM songs s
+		JOIN groups g ON s.group_id = g.id
+		WHERE $1 <% s.title
+		ORDER BY score DESC, similarity($1, s.title) DESC, s.title, g.name
+		LIMIT $2`
+
+	var suggestions []entity.TitleSuggestion
+	err := s.withinWordSimilarity(ctx, threshold, func(ctx context.Context) error {
+		rows, err := s.Query(ctx, sqlQuery, query, limit)
+		if err != nil {
+			return fmt.Errorf("failed to suggest song titles: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var suggestion entity.TitleSuggestion
+			if err := rows.Scan(&suggestion.SongID, &suggestion.Title, &suggestion.GroupName, &suggestion.Score); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("row iteration error: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// SuggestGroups returns the groups whose name is most alike the query, best
+// first, in the order of SuggestTitles.
+func (s *SearchPostgres) SuggestGroups(ctx context.Context, query string, threshold float64, limit int) ([]entity.GroupSuggestion, error) {
+	sqlQuery := `
+		SELECT id, name, word_similarity($1, name)::float8 AS score
+		FROM groups
+		WHERE $1 <% name
+		ORDER BY score DESC, similarity($1, name) DESC, name
+		LIMIT $2`
+
+	var suggestions []entity.GroupSuggestion
+	err := s.withinWordSimilarity(ctx, threshold, func(ctx context.Context) error {
+		rows, err := s.Query(ctx, sqlQuery, query, limit)
+		if err != nil {
+			return fmt.Errorf("failed to suggest groups: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var suggestion entity.GroupSuggestion
+			if err := rows.Scan(&suggestion.GroupID, &suggestion.Name, &suggestion.Score); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("row iteration error: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
@@ -8,26 +8,34 @@ import (
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
+	"slices"
 	"strings"
 )
 
 type SongPostgres struct {
-	*pgx.Conn
+	*DB
 }
 
-func NewSongPostgres(conn *pgx.Conn) *SongPostgres {
-	return &SongPostgres{Conn: conn}
+func NewSongPostgres(db *DB) *SongPostgres {
+	return &SongPostgres{DB: db}
 }
 
+// songReleasePrecision is the precision of COALESCE(s.release_date, a.release_date),
+// album release dates being known to the day.
+const songReleasePrecision = `CASE WHEN s.release_date IS NOT NULL THEN s.release_date_precision WHEN a.release_date IS NOT NULL THEN 'day' ELSE '' END`
+
+// songReleaseEnd is the first day after the period of the release date.
+const songReleaseEnd = `COALESCE(s.release_date + CASE s.release_date_precision WHEN 'year' THEN INTERVAL '1 year' WHEN 'month' THEN INTERVAL '1 month' ELSE INTERVAL '1 day' END, a.release_date + INTERVAL '1 day')`
+
 func (s *SongPostgres) CreateSong(ctx context.Context, song *entity.Song) (string, error) {
 	query := `
-		INSERT INTO songs (title, group_id, release_date, link)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO songs (title, group_id, release_date, release_date_precision, link, album_id, track_number, disc_number, enrichment_status)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'day'), $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'done'))
 		RETURNING id
 	`
 
 	var songID string
-	err := s.QueryRow(ctx, query, song.Title, song.GroupID, song.ReleaseDate, song.Link).Scan(&songID)
+	err := s.QueryRow(ctx, query, song.Title, song.GroupID, song.ReleaseDate, song.ReleaseDatePrecision, song.Link, song.AlbumID, song.TrackNumber, song.DiscNumber, song.EnrichmentStatus).Scan(&songID)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			if pgErr.Code == "23505" {
@@ -40,87 +48,305 @@ func (s *SongPostgres) CreateSong(ctx context.Context, song *entity.Song) (strin
 	return songID, nil
 }
 
+// songListFrom is the FROM clause of the song listing queries, which
+// songFilterWhere filters.
+const songListFrom = ` FROM songs s JOIN groups g ON s.group_id = g.id LEFT JOIN albums a ON s.album_id = a.id`
+
+// songSortColumns are the expressions the listing is sorted by for every
+// sort field.
+var songSortColumns = map[string]string{
+	entity.SongSortTitle:       "s.title",
+	entity.SongSortGroup:       "g.name",
+	entity.SongSortReleaseDate: "COALESCE(s.release_date, a.release_date)",
+	entity.SongSortCreatedAt:   "s.created_at",
+}
+
 func (s *SongPostgres) GetSongsByFilter(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error) {
-	baseQuery := `SELECT DISTINCT ON (s.id) s.id, s.release_date, g.name, s.title, s.link FROM songs s JOIN groups g ON s.group_id = g.id JOIN lyrics_verses l ON s.id = l.song_id`
+	where, args := songFilterWhere(filter)
+	argIndex := len(args) + 1
+
+	// songs before a cursor are read backwards from it, and put back in order
+	cursor, backward := filter.After, false
+	if filter.Before != nil {
+		cursor, backward = filter.Before, true
+	}
+
+	orderBy := make([]string, 0, len(filter.Sort)+1)
+	for _, key := range filter.Sort {
+		column, ok := songSortColumns[key.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", key.Field)
+		}
+		direction, nulls := "ASC", "LAST"
+		if key.Desc != backward {
+			direction = "DESC"
+		}
+		if backward {
+			nulls = "FIRST"
+		}
+		orderBy = append(orderBy, fmt.Sprintf("%s %s NULLS %s", column, direction, nulls))
+	}
+	if backward {
+		orderBy = append(orderBy, "s.id DESC")
+	} else {
+		orderBy = append(orderBy, "s.id")
+	}
+
+	if cursor != nil {
+		condition, cursorArgs := songKeyset(filter.Sort, cursor, backward, argIndex)
+		if where == "" {
+			where = " WHERE " + condition
+		} else {
+			where += " AND " + condition
+		}
+		args = append(args, cursorArgs...)
+		argIndex += len(cursorArgs)
+	}
+
+	baseQuery := `SELECT s.id, COALESCE(s.release_date, a.release_date), ` + songReleasePrecision + `, g.name, s.title, s.link, s.album_id, a.title, s.track_number, s.disc_number, s.enrichment_status, s.created_at` +
+		songListFrom + where + " ORDER BY " + strings.Join(orderBy, ", ")
+	if filter.Limit != 0 {
+		baseQuery += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+	if filter.Offset != 0 && cursor == nil {
+		baseQuery += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+		argIndex++
+	}
+
+	var songs []entity.Song
+	err := s.withinSongFilter(ctx, filter, func(ctx context.Context) error {
+		rows, err := s.Query(ctx, baseQuery, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query song: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var song entity.Song
+			if err := rows.Scan(&song.ID, &song.ReleaseDate, &song.ReleaseDatePrecision, &song.GroupName, &song.Title, &song.Link, &song.AlbumID, &song.AlbumTitle, &song.TrackNumber, &song.DiscNumber, &song.EnrichmentStatus, &song.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			songs = append(songs, song)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("row iteration error: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if backward {
+		slices.Reverse(songs)
+	}
+
+	return songs, nil
+}
 
+// songKeyset builds the condition selecting the songs listed after the
+// cursor in the order of sort, or before it when backward, with its arguments
+// numbered from argIndex. A song comes after the cursor when it comes after
+// it by the first key it differs from it by, the ID being the last key, and
+// NULLs come last in either direction.
+func songKeyset(sort []entity.SongSort, cursor *entity.SongCursor, backward bool, argIndex int) (string, []interface{}) {
+	var alternatives, equal []string
+	var args []interface{}
+
+	for _, key := range sort {
+		column := songSortColumns[key.Field]
+		var value interface{}
+		switch key.Field {
+		case entity.SongSortTitle:
+			value = cursor.Title
+		case entity.SongSortGroup:
+			value = cursor.GroupName
+		case entity.SongSortReleaseDate:
+			if cursor.ReleaseDate != nil {
+				value = *cursor.ReleaseDate
+			}
+		case entity.SongSortCreatedAt:
+			if cursor.CreatedAt != nil {
+				value = *cursor.CreatedAt
+			}
+		}
+
+		if value == nil {
+			// nothing but NULLs comes after a NULL, and everything else before it
+			if backward {
+				alternatives = append(alternatives, songKeysetTerm(equal, column+" IS NOT NULL"))
+			}
+			equal = append(equal, column+" IS NULL")
+			continue
+		}
+
+		operator := ">"
+		if key.Desc != backward {
+			operator = "<"
+		}
+		beyond := fmt.Sprintf("%s %s $%d", column, operator, argIndex)
+		if !backward {
+			beyond = fmt.Sprintf("(%s OR %s IS NULL)", beyond, column)
+		}
+		alternatives = append(alternatives, songKeysetTerm(equal, beyond))
+		equal = append(equal, fmt.Sprintf("%s = $%d", column, argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	operator := ">"
+	if backward {
+		operator = "<"
+	}
+	alternatives = append(alternatives, songKeysetTerm(equal, fmt.Sprintf("s.id %s $%d", operator, argIndex)))
+	args = append(args, cursor.ID)
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+func songKeysetTerm(equal []string, beyond string) string {
+	if len(equal) == 0 {
+		return beyond
+	}
+
+	return "(" + strings.Join(equal, " AND ") + " AND " + beyond + ")"
+}
+
+// CountSongsByFilter counts the songs GetSongsByFilter lists, regardless of
+// the limit and offset of the filter.
+func (s *SongPostgres) CountSongsByFilter(ctx context.Context, filter *entity.SongFilter) (int, error) {
+	where, args := songFilterWhere(filter)
+
+	var count int
+	err := s.withinSongFilter(ctx, filter, func(ctx context.Context) error {
+		if err := s.QueryRow(ctx, `SELECT COUNT(*)`+songListFrom+where, args...).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count songs: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// withinSongFilter runs fn, which queries with the conditions of
+// songFilterWhere, where the fuzzy conditions match from the similarity
+// threshold of the filter on.
+func (s *SongPostgres) withinSongFilter(ctx context.Context, filter *entity.SongFilter, fn func(ctx context.Context) error) error {
+	if !filter.Fuzzy || (filter.Title == "" && filter.Group == "") {
+		return fn(ctx)
+	}
+
+	return s.withinWordSimilarity(ctx, filter.SimilarityThreshold, fn)
+}
+
+// songFilterWhere builds the WHERE clause of the filter over songListFrom,
+// with its arguments numbered from $1.
+func songFilterWhere(filter *entity.SongFilter) (string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argIndex := 1
 
-	if filter.StartDate != "" && filter.EndDate != "" {
-		conditions = append(conditions, fmt.Sprintf("release_date BETWEEN $%d AND $%d", argIndex, argIndex+1))
-		args = append(args, filter.StartDate, filter.EndDate)
-		argIndex += 2
-	} else if filter.StartDate != "" {
-		conditions = append(conditions, fmt.Sprintf("release_date >= $%d", argIndex))
+	if len(filter.IDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("s.id = ANY($%d)", argIndex))
+		args = append(args, filter.IDs)
+		argIndex++
+	}
+
+	// a partial release date is a period, which matches when it overlaps the range
+	if filter.StartDate != "" {
+		conditions = append(conditions, fmt.Sprintf("%s > $%d::date", songReleaseEnd, argIndex))
 		args = append(args, filter.StartDate)
 		argIndex++
-	} else if filter.EndDate != "" {
-		conditions = append(conditions, fmt.Sprintf("release_date <= $%d", argIndex))
+	}
+	if filter.EndDate != "" {
+		conditions = append(conditions, fmt.Sprintf("COALESCE(s.release_date, a.release_date) <= $%d::date", argIndex))
 		args = append(args, filter.EndDate)
 		argIndex++
 	}
 
-	if filter.Title != "" {
-		conditions = append(conditions, fmt.Sprintf("title ILIKE $%d", argIndex))
+	if filter.Title != "" && filter.Fuzzy {
+		// the threshold of <% is set by withinSongFilter
+		conditions = append(conditions, fmt.Sprintf("$%d <%% s.title", argIndex))
+		args = append(args, filter.Title)
+		argIndex++
+	} else if filter.Title != "" {
+		conditions = append(conditions, fmt.Sprintf("s.title ILIKE $%d", argIndex))
 		args = append(args, "%"+filter.Title+"%")
 		argIndex++
 	}
 
 	if filter.Link != "" {
-		conditions = append(conditions, fmt.Sprintf("link = $%d", argIndex))
+		conditions = append(conditions, fmt.Sprintf("s.link = $%d", argIndex))
 		args = append(args, filter.Link)
 		argIndex++
 	}
 
-	if filter.Group != "" {
+	if filter.Group != "" && filter.Fuzzy {
+		conditions = append(conditions, fmt.Sprintf("$%d <%% g.name", argIndex))
+		args = append(args, filter.Group)
+		argIndex++
+	} else if filter.Group != "" {
 		conditions = append(conditions, fmt.Sprintf("g.name ILIKE $%d", argIndex))
 		args = append(args, "%"+filter.Group+"%")
 		argIndex++
 	}
 
-	if filter.Text != "" {
-		conditions = append(conditions, fmt.Sprintf("l.verse ILIKE $%d", argIndex))
-		args = append(args, "%"+filter.Text+"%")
+	if filter.Album != "" {
+		conditions = append(conditions, fmt.Sprintf("a.title ILIKE $%d", argIndex))
+		args = append(args, "%"+filter.Album+"%")
 		argIndex++
 	}
 
-	if len(conditions) > 0 {
-		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
+	if filter.AlbumID != "" {
+		conditions = append(conditions, fmt.Sprintf("s.album_id = $%d", argIndex))
+		args = append(args, filter.AlbumID)
+		argIndex++
 	}
 
-	baseQuery += " ORDER BY s.id, release_date DESC"
-	if filter.Limit != 0 {
-		baseQuery += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, filter.Limit)
+	if filter.Tag != "" {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM song_tags st JOIN tags t ON st.tag_id = t.id WHERE st.song_id = s.id AND t.name = $%d)", argIndex))
+		args = append(args, filter.Tag)
 		argIndex++
 	}
-	if filter.Offset != 0 {
-		baseQuery += fmt.Sprintf(" OFFSET $%d", argIndex)
-		args = append(args, filter.Offset)
+
+	if len(filter.AnyTags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM song_tags st JOIN tags t ON st.tag_id = t.id WHERE st.song_id = s.id AND t.name = ANY($%d))", argIndex))
+		args = append(args, filter.AnyTags)
 		argIndex++
 	}
 
-	rows, err := s.Query(ctx, baseQuery, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query song: %w", err)
+	if len(filter.AllTags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("(SELECT COUNT(DISTINCT t.name) FROM song_tags st JOIN tags t ON st.tag_id = t.id WHERE st.song_id = s.id AND t.name = ANY($%d)) = $%d", argIndex, argIndex+1))
+		args = append(args, filter.AllTags, len(filter.AllTags))
+		argIndex += 2
 	}
-	defer rows.Close()
 
-	var songs []entity.Song
-	for rows.Next() {
-		var song entity.Song
-		if err := rows.Scan(&song.ID, &song.ReleaseDate, &song.GroupName, &song.Title, &song.Link); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		songs = append(songs, song)
+	if filter.HasLyrics != nil && *filter.HasLyrics {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM lyrics_verses l WHERE l.song_id = s.id)")
+	} else if filter.HasLyrics != nil {
+		conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM lyrics_verses l WHERE l.song_id = s.id)")
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration error: %w", err)
+	if filter.Text != "" {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM lyrics_verses l WHERE l.song_id = s.id AND l.search_vector @@ plainto_tsquery((SELECT language FROM search_settings), $%d))", argIndex))
+		args = append(args, filter.Text)
+		argIndex++
 	}
 
-	return songs, nil
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
 }
 
 func (s *SongPostgres) UpdateSong(ctx context.Context, update *entity.SongUpdate) error {
@@ -135,9 +361,9 @@ func (s *SongPostgres) UpdateSong(ctx context.Context, update *entity.SongUpdate
 		argIndex++
 	}
 	if update.ReleaseDate != nil {
-		updates = append(updates, fmt.Sprintf("release_date = $%d", argIndex))
-		args = append(args, update.ReleaseDate)
-		argIndex++
+		updates = append(updates, fmt.Sprintf("release_date = $%d, release_date_precision = COALESCE(NULLIF($%d, ''), 'day')", argIndex, argIndex+1))
+		args = append(args, update.ReleaseDate, update.ReleaseDatePrecision)
+		argIndex += 2
 	}
 	if update.GroupName != nil {
 		updates = append(updates, fmt.Sprintf("group_id = $%d", argIndex))
@@ -149,6 +375,21 @@ func (s *SongPostgres) UpdateSong(ctx context.Context, update *entity.SongUpdate
 		args = append(args, update.Link)
 		argIndex++
 	}
+	if update.AlbumID != nil {
+		updates = append(updates, fmt.Sprintf("album_id = NULLIF($%d, '')::uuid", argIndex))
+		args = append(args, update.AlbumID)
+		argIndex++
+	}
+	if update.TrackNumber != nil {
+		updates = append(updates, fmt.Sprintf("track_number = $%d", argIndex))
+		args = append(args, update.TrackNumber)
+		argIndex++
+	}
+	if update.DiscNumber != nil {
+		updates = append(updates, fmt.Sprintf("disc_number = $%d", argIndex))
+		args = append(args, update.DiscNumber)
+		argIndex++
+	}
 
 	if len(updates) == 0 {
 		return fmt.Errorf("no fields to update")
@@ -199,11 +440,19 @@ func (s *SongPostgres) GetSongByID(ctx context.Context, songID string) (*entity.
 		SELECT
 			s.id,
 			s.title,
-			s.release_date,
+			COALESCE(s.release_date, a.release_date),
+			` + songReleasePrecision + `,
 			g.name AS group_name,
-			s.link
+			s.link,
+			s.album_id,
+			a.title AS album_title,
+			s.track_number,
+			s.disc_number,
+			s.enrichment_status,
+			s.created_at
 		FROM songs s
 		JOIN groups g ON s.group_id = g.id
+		LEFT JOIN albums a ON s.album_id = a.id
 		WHERE s.id = $1
 	`
 
@@ -212,8 +461,15 @@ func (s *SongPostgres) GetSongByID(ctx context.Context, songID string) (*entity.
 		&song.ID,
 		&song.Title,
 		&song.ReleaseDate,
+		&song.ReleaseDatePrecision,
 		&song.GroupName,
 		&song.Link,
+		&song.AlbumID,
+		&song.AlbumTitle,
+		&song.TrackNumber,
+		&song.DiscNumber,
+		&song.EnrichmentStatus,
+		&song.CreatedAt,
 	)
 
 	if err != nil {
@@ -225,3 +481,67 @@ func (s *SongPostgres) GetSongByID(ctx context.Context, songID string) (*entity.
 
 	return &song, nil
 }
+
+func (s *SongPostgres) GetSongsByGroupID(ctx context.Context, groupID string) ([]entity.Song, error) {
+	query := `
+		SELECT s.id, s.title, COALESCE(s.release_date, a.release_date) AS release_date, ` + songReleasePrecision + `, g.name, s.link, s.album_id, a.title, s.track_number, s.disc_number, s.enrichment_status, s.created_at
+		FROM songs s
+		JOIN groups g ON s.group_id = g.id
+		LEFT JOIN albums a ON s.album_id = a.id
+		WHERE s.group_id = $1
+		ORDER BY release_date, s.title
+	`
+
+	rows, err := s.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query songs of the group: %w", err)
+	}
+	defer rows.Close()
+
+	var songs []entity.Song
+	for rows.Next() {
+		var song entity.Song
+		if err := rows.Scan(&song.ID, &song.Title, &song.ReleaseDate, &song.ReleaseDatePrecision, &song.GroupName, &song.Link, &song.AlbumID, &song.AlbumTitle, &song.TrackNumber, &song.DiscNumber, &song.EnrichmentStatus, &song.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return songs, nil
+}
+
+func (s *SongPostgres) GetSongsByAlbumID(ctx context.Context, albumID string) ([]entity.Song, error) {
+	query := `
+		SELECT s.id, s.title, COALESCE(s.release_date, a.release_date), ` + songReleasePrecision + `, g.name, s.link, s.album_id, a.title, s.track_number, s.disc_number, s.enrichment_status, s.created_at
+		FROM songs s
+		JOIN groups g ON s.group_id = g.id
+		JOIN albums a ON s.album_id = a.id
+		WHERE s.album_id = $1
+		ORDER BY s.disc_number NULLS LAST, s.track_number NULLS LAST, s.title
+	`
+
+	rows, err := s.Query(ctx, query, albumID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query songs of the album: %w", err)
+	}
+	defer rows.Close()
+
+	var songs []entity.Song
+	for rows.Next() {
+		var song entity.Song
+		if err := rows.Scan(&song.ID, &song.Title, &song.ReleaseDate, &song.ReleaseDatePrecision, &song.GroupName, &song.Link, &song.AlbumID, &song.AlbumTitle, &song.TrackNumber, &song.DiscNumber, &song.EnrichmentStatus, &song.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return songs, nil
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+type TagPostgres struct {
+	*DB
+}
+
+func NewTagPostgres(db *DB) *TagPostgres {
+	return &TagPostgres{DB: db}
+}
+
+func (t *TagPostgres) CreateTag(ctx context.Context, tag *entity.Tag) (string, error) {
+	query := `INSERT INTO tags (name, category) VALUES ($1, $2) RETURNING id`
+	var tagID string
+
+	err := t.QueryRow(ctx, query, tag.Name, tag.Category).Scan(&tagID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23505" {
+				return "", repoerrors.ErrAlreadyExists
+			}
+		}
+		return "", err
+	}
+
+	return tagID, nil
+}
+
+func (t *TagPostgres) GetTags(ctx context.Context, category string) ([]entity.TagUsage, error) {
+	query := `
+		SELECT t.id, t.name, t.category, COUNT(st.song_id) AS usage_count
+		FROM tags t
+		LEFT JOIN song_tags st ON st.tag_id = t.id
+		WHERE $1 = '' OR t.category = $1
+		GROUP BY t.id
+		ORDER BY usage_count DESC, t.name
+	`
+
+	rows, err := t.Query(ctx, query, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []entity.TagUsage
+	for rows.Next() {
+		var tag entity.TagUsage
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Category, &tag.UsageCount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (t *TagPostgres) GetTagByID(ctx context.Context, tagID string) (*entity.TagUsage, error) {
+	query := `
+		SELECT t.id, t.name, t.category, COUNT(st.song_id)
+		FROM tags t
+		LEFT JOIN song_tags st ON st.tag_id = t.id
+		WHERE t.id = $1
+		GROUP BY t.id
+	`
+
+	var tag entity.TagUsage
+	err := t.QueryRow(ctx, query, tagID).Scan(&tag.ID, &tag.Name, &tag.Category, &tag.UsageCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch the tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (t *TagPostgres) UpdateTag(ctx context.Context, update *entity.TagUpdate) error {
+	baseQuery := `UPDATE tags SET `
+	var updates []string
+	var args []interface{}
+	argIndex := 1
+
+	if update.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, update.Name)
+		argIndex++
+	}
+	if update.Category != nil {
+		updates = append(updates, fmt.Sprintf("category = $%d", argIndex))
+		args = append(args, update.Category)
+		argIndex++
+	}
+
+	if len(updates) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	query := baseQuery + strings.Join(updates, ", ") + fmt.Sprintf(" WHERE id = $%d", argIndex)
+	args = append(args, update.ID)
+
+	result, err := t.Exec(ctx, query, args...)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23505" {
+				return repoerrors.ErrAlreadyExists
+			}
+		}
+		return fmt.Errorf("failed to update tag with ID %s: %w", update.ID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (t *TagPostgres) DeleteTag(ctx context.Context, tagID string) error {
+	query := `DELETE FROM tags WHERE id = $1`
+
+	result, err := t.Exec(ctx, query, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag with ID %s: %w", tagID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (t *TagPostgres) AttachTag(ctx context.Context, songID, tagID string) error {
+	query := `INSERT INTO song_tags (song_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	_, err := t.Exec(ctx, query, songID, tagID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == "23503" {
+				return repoerrors.ErrNotFound
+			}
+		}
+		return fmt.Errorf("failed to attach tag %s to song %s: %w", tagID, songID, err)
+	}
+
+	return nil
+}
+
+func (t *TagPostgres) DetachTag(ctx context.Context, songID, tagID string) error {
+	query := `DELETE FROM song_tags WHERE song_id = $1 AND tag_id = $2`
+
+	result, err := t.Exec(ctx, query, songID, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to detach tag %s from song %s: %w", tagID, songID, err)
+	}
+
+	if result.RowsAffected() < 1 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetTagsBySongIDs returns the tags of every given song keyed by song ID.
+func (t *TagPostgres) GetTagsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.Tag, error) {
+	query := `
+		SELECT st.song_id, t.id, t.name, t.category
+		FROM song_tags st
+		JOIN tags t ON st.tag_id = t.id
+		WHERE st.song_id = ANY($1)
+		ORDER BY t.category, t.name
+	`
+
+	rows, err := t.Query(ctx, query, songIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags of songs: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]entity.Tag)
+	for rows.Next() {
+		var songID string
+		var tag entity.Tag
+		if err := rows.Scan(&songID, &tag.ID, &tag.Name, &tag.Category); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		tags[songID] = append(tags[songID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tags, nil
+}
@@ -2,17 +2,116 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"strconv"
 )
 
+type txKey struct{}
+
+// querier is implemented by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// DB is embedded by the repositories. Queries run on the transaction carried
+// by the context, and on the pool otherwise.
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+func NewDB(pool *pgxpool.Pool) *DB {
+	return &DB{pool: pool}
+}
+
+func (db *DB) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return db.pool
+}
+
+func (db *DB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return db.conn(ctx).Exec(ctx, sql, args...)
+}
+
+func (db *DB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return db.conn(ctx).Query(ctx, sql, args...)
+}
+
+func (db *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return db.conn(ctx).QueryRow(ctx, sql, args...)
+}
+
+// withinWordSimilarity runs fn in a transaction, or a savepoint of the one the
+// context carries, where the <% operator of pg_trgm matches from threshold on.
+// Unlike word_similarity() compared to a parameter, <% is served by the
+// trigram indexes.
+func (db *DB) withinWordSimilarity(ctx context.Context, threshold float64, fn func(ctx context.Context) error) error {
+	return NewDBConn(db.pool).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := db.Exec(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, strconv.FormatFloat(threshold, 'f', -1, 64))
+		if err != nil {
+			return fmt.Errorf("failed to set the word similarity threshold: %w", err)
+		}
+
+		return fn(ctx)
+	})
+}
+
 type DBConn struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
-func NewDBConn(db *pgx.Conn) *DBConn {
+func NewDBConn(db *pgxpool.Pool) *DBConn {
 	return &DBConn{db: db}
 }
 
-func (dbc *DBConn) Begin(ctx context.Context) (pgx.Tx, error) {
-	return dbc.db.Begin(ctx)
+// WithinTransaction runs fn in a transaction carried by the context passed to
+// it, committing if fn succeeds and rolling back otherwise. Called within
+// another transaction it runs fn in a savepoint of that transaction instead.
+func (dbc *DBConn) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbc.within(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithinSnapshot runs fn in a read-only repeatable read transaction, so that
+// every query in fn sees the same snapshot of the database.
+func (dbc *DBConn) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbc.within(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, fn)
+}
+
+func (dbc *DBConn) within(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) (err error) {
+	var tx pgx.Tx
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		// pgx turns a transaction started within a transaction into a savepoint
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = dbc.db.BeginTx(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+				err = errors.Join(err, rollbackErr)
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
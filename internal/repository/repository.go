@@ -3,46 +3,190 @@ package repository
 import (
 	"context"
 	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/memory"
 	"effective_mobile_tz/internal/repository/postgres"
-	"github.com/jackc/pgx/v4"
+	"effective_mobile_tz/pkg/textquery"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
 )
 
 type Song interface {
 	CreateSong(ctx context.Context, song *entity.Song) (string, error)
 	GetSongsByFilter(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error)
+	CountSongsByFilter(ctx context.Context, filter *entity.SongFilter) (int, error)
 	DeleteSong(ctx context.Context, songID string) error
 	GetSongByID(ctx context.Context, songID string) (*entity.Song, error)
 	UpdateSong(ctx context.Context, update *entity.SongUpdate) error
+	GetSongsByGroupID(ctx context.Context, groupID string) ([]entity.Song, error)
+	GetSongsByAlbumID(ctx context.Context, albumID string) ([]entity.Song, error)
 }
 
 type Group interface {
 	GetGroupIDByName(ctx context.Context, name string) (string, error)
 	CreateGroup(ctx context.Context, name string) (string, error)
+	GetGroups(ctx context.Context) ([]entity.Group, error)
+	GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error)
+	RenameGroup(ctx context.Context, groupID, name string) error
+	// LockGroup keeps songs from being added to the group until the
+	// transaction of the context ends.
+	LockGroup(ctx context.Context, groupID string) error
+	DeleteGroup(ctx context.Context, groupID string) error
+}
+
+type Album interface {
+	CreateAlbum(ctx context.Context, album *entity.Album) (string, error)
+	GetAlbums(ctx context.Context, filter *entity.AlbumFilter) ([]entity.Album, error)
+	GetAlbumByID(ctx context.Context, albumID string) (*entity.Album, error)
+	UpdateAlbum(ctx context.Context, update *entity.AlbumUpdate) error
+	DeleteAlbum(ctx context.Context, albumID string) error
+}
+
+type Tag interface {
+	CreateTag(ctx context.Context, tag *entity.Tag) (string, error)
+	GetTags(ctx context.Context, category string) ([]entity.TagUsage, error)
+	GetTagByID(ctx context.Context, tagID string) (*entity.TagUsage, error)
+	UpdateTag(ctx context.Context, update *entity.TagUpdate) error
+	DeleteTag(ctx context.Context, tagID string) error
+	AttachTag(ctx context.Context, songID, tagID string) error
+	DetachTag(ctx context.Context, songID, tagID string) error
+	GetTagsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.Tag, error)
+}
+
+type Playlist interface {
+	CreatePlaylist(ctx context.Context, playlist *entity.Playlist) (string, error)
+	GetPlaylists(ctx context.Context) ([]entity.Playlist, error)
+	GetPlaylistByID(ctx context.Context, playlistID string) (*entity.Playlist, error)
+	UpdatePlaylist(ctx context.Context, update *entity.PlaylistUpdate) error
+	DeletePlaylist(ctx context.Context, playlistID string) error
+	GetPlaylistEntries(ctx context.Context, playlistID string) ([]entity.PlaylistEntry, error)
+	InsertPlaylistEntry(ctx context.Context, entry *entity.PlaylistEntry) (string, error)
+	MovePlaylistEntry(ctx context.Context, playlistID, entryID string, position int) error
+	RemovePlaylistEntry(ctx context.Context, playlistID, entryID string) error
+	RemoveSongFromPlaylists(ctx context.Context, songID string) error
 }
 
 type Lyrics interface {
-	AddLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) error
-	GetAllLyrics(ctx context.Context, songID string) ([]entity.LyricsVerse, error)
+	AddLyricsStanza(ctx context.Context, stanza *entity.LyricsStanza) error
+	GetLyricsBySongIDs(ctx context.Context, songIDs []string) (map[string][]entity.LyricsStanza, error)
+	GetLyricsStanzas(ctx context.Context, songID string, first, last int) ([]entity.LyricsStanza, error)
+	UpdateLyricsStanza(ctx context.Context, songID string, stanzaNumber int, label *string) error
+	DeleteEmptyLyricsStanzas(ctx context.Context, songID string) error
 	GetPaginatedLyrics(ctx context.Context, songID string, limit, offset int) ([]entity.LyricsVerse, error)
+	GetLyricsAfter(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error)
+	GetLyricsBefore(ctx context.Context, songID string, verseNumber, limit int) ([]entity.LyricsVerse, error)
+	GetLyricsVerse(ctx context.Context, songID string, verseNumber int) (*entity.LyricsVerse, error)
+	GetLyricsVerseByID(ctx context.Context, songID, verseID string) (*entity.LyricsVerse, error)
+	CountLyricsVerses(ctx context.Context, songID string) (int, error)
+	UpdateLyricsVerse(ctx context.Context, songID string, verseNumber int, verse string) error
+	InsertLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) error
+	MoveLyricsVerse(ctx context.Context, songID string, verseNumber, position, stanzaNumber int) error
+	DeleteLyricsVerse(ctx context.Context, songID string, verseNumber int) error
 	DeleteLyrics(ctx context.Context, songID string) error
 }
 
+type Archive interface {
+	ExportGroups(ctx context.Context, fn func(group *entity.ArchiveGroup) error) error
+	ExportAlbums(ctx context.Context, fn func(album *entity.ArchiveAlbum) error) error
+	ExportTags(ctx context.Context, fn func(tag *entity.ArchiveTag) error) error
+	ExportSongs(ctx context.Context, fn func(song *entity.ArchiveSong) error) error
+	ExportVerses(ctx context.Context, fn func(verse *entity.ArchiveVerse) error) error
+	RestoreGroup(ctx context.Context, group *entity.ArchiveGroup, policy string) (string, bool, error)
+	RestoreAlbum(ctx context.Context, album *entity.ArchiveAlbum, policy string) (string, bool, error)
+	RestoreTag(ctx context.Context, tag *entity.ArchiveTag, policy string) (string, bool, error)
+	RestoreSong(ctx context.Context, song *entity.ArchiveSong, policy string) (string, bool, error)
+	RestoreVerse(ctx context.Context, verse *entity.ArchiveVerse) error
+}
+
+// Enrichment is the queue of song enrichment jobs. A claimed job is held for
+// the lease, after which it may be claimed again. The outcome of a job is
+// recorded with the claim it was got by, and not found once the job has been
+// claimed again or reset.
+type Enrichment interface {
+	CreateEnrichmentJob(ctx context.Context, songID string) error
+	ClaimEnrichmentJobs(ctx context.Context, limit int, lease time.Duration) ([]entity.EnrichmentJob, error)
+	CompleteEnrichmentJob(ctx context.Context, jobID, claim string) error
+	RetryEnrichmentJob(ctx context.Context, jobID, claim string, delay time.Duration, lastError string) error
+	FailEnrichmentJob(ctx context.Context, jobID, claim string, lastError string) error
+	GetEnrichmentJobBySongID(ctx context.Context, songID string) (*entity.EnrichmentJob, error)
+	GetEnrichmentJobs(ctx context.Context, filter *entity.EnrichmentJobFilter) ([]entity.EnrichmentJob, error)
+	RequeueFailedEnrichmentJobs(ctx context.Context, songID string) (int, error)
+}
+
+// SongDetailCache keeps lookups of the song metadata provider. Expired
+// entries are treated as missing.
+type SongDetailCache interface {
+	GetSongDetailCache(ctx context.Context, key string) (*entity.SongDetailCacheEntry, error)
+	SetSongDetailCache(ctx context.Context, entry *entity.SongDetailCacheEntry, ttl time.Duration) error
+	DeleteSongDetailCache(ctx context.Context, key string) (int, error)
+	CountSongDetailCache(ctx context.Context) (int, error)
+}
+
+// Search finds songs by their lyrics. Every verse is indexed in the search
+// language, so changing it reindexes the lyrics. It also suggests the song
+// titles and groups whose word similarity to a query reaches the threshold.
+type Search interface {
+	SearchSongs(ctx context.Context, query *textquery.Query, limit, offset int) ([]entity.SongSearchResult, error)
+	SetSearchLanguage(ctx context.Context, language string) (bool, error)
+	SuggestTitles(ctx context.Context, query string, threshold float64, limit int) ([]entity.TitleSuggestion, error)
+	SuggestGroups(ctx context.Context, query string, threshold float64, limit int) ([]entity.GroupSuggestion, error)
+}
+
+// DBTransaction runs units of work. Repository methods called with the
+// context passed to fn take part in the transaction; nested calls run in
+// savepoints.
 type DBTransaction interface {
-	Begin(ctx context.Context) (pgx.Tx, error)
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Repository struct {
 	Song
 	Group
+	Album
+	Tag
+	Playlist
 	Lyrics
+	Archive
+	Enrichment
+	SongDetailCache
+	Search
 	DBTransaction
 }
 
-func NewRepository(conn *pgx.Conn) *Repository {
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	db := postgres.NewDB(pool)
+
+	return &Repository{
+		Song:            postgres.NewSongPostgres(db),
+		Group:           postgres.NewGroupPostgres(db),
+		Album:           postgres.NewAlbumPostgres(db),
+		Tag:             postgres.NewTagPostgres(db),
+		Playlist:        postgres.NewPlaylistPostgres(db),
+		Lyrics:          postgres.NewLyricsPostgres(db),
+		Archive:         postgres.NewArchivePostgres(db),
+		Enrichment:      postgres.NewEnrichmentPostgres(db),
+		SongDetailCache: postgres.NewSongDetailCachePostgres(db),
+		Search:          postgres.NewSearchPostgres(db),
+		DBTransaction:   postgres.NewDBConn(pool),
+	}
+}
+
+// NewMemoryRepository returns repositories backed by an empty in-memory store,
+// which lets the service run without a database.
+func NewMemoryRepository() *Repository {
+	store := memory.NewStore()
+
 	return &Repository{
-		Song:          postgres.NewSongPostgres(conn),
-		Group:         postgres.NewGroupPostgres(conn),
-		Lyrics:        postgres.NewLyricsPostgres(conn),
-		DBTransaction: postgres.NewDBConn(conn),
+		Song:            memory.NewSongMemory(store),
+		Group:           memory.NewGroupMemory(store),
+		Album:           memory.NewAlbumMemory(store),
+		Tag:             memory.NewTagMemory(store),
+		Playlist:        memory.NewPlaylistMemory(store),
+		Lyrics:          memory.NewLyricsMemory(store),
+		Archive:         memory.NewArchiveMemory(store),
+		Enrichment:      memory.NewEnrichmentMemory(store),
+		SongDetailCache: memory.NewSongDetailCacheMemory(store),
+		Search:          memory.NewSearchMemory(store),
+		DBTransaction:   memory.NewDBTransaction(store),
 	}
 }
@@ -0,0 +1,251 @@
+package repository_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/pkg/partialdate"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// missingID is a well-formed ID no row has.
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+func TestGroups(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+
+		first, err := backend.CreateGroup(ctx, "First")
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := backend.CreateGroup(ctx, "Second")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := backend.CreateGroup(ctx, "First"); !errors.Is(err, repoerrors.ErrAlreadyExists) {
+			t.Errorf("got error %v creating a group of a taken name, want %v", err, repoerrors.ErrAlreadyExists)
+		}
+		if err := backend.RenameGroup(ctx, second, "First"); !errors.Is(err, repoerrors.ErrAlreadyExists) {
+			t.Errorf("got error %v renaming a group to a taken name, want %v", err, repoerrors.ErrAlreadyExists)
+		}
+		if err := backend.RenameGroup(ctx, missingID, "Third"); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v renaming a missing group, want %v", err, repoerrors.ErrNotFound)
+		}
+		if _, err := backend.GetGroupIDByName(ctx, "Third"); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v looking up a missing group, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		if id, err := backend.GetGroupIDByName(ctx, "First"); err != nil || id != first {
+			t.Errorf("got group %q, %v by name, want %q", id, err, first)
+		}
+		if err := backend.RenameGroup(ctx, second, "Renamed"); err != nil {
+			t.Fatal(err)
+		}
+		group, err := backend.GetGroupByID(ctx, second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group.Name != "Renamed" {
+			t.Errorf("got group %q after renaming, want %q", group.Name, "Renamed")
+		}
+	})
+}
+
+// TestDeleteGroup deletes a group along with its songs and their lyrics.
+func TestDeleteGroup(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := addSong(t, backend, "Deleted", "Deleted song", []string{"A line"})
+		keptID := addSong(t, backend, "Kept", "Kept song", []string{"Another line"})
+
+		groupID, err := backend.GetGroupIDByName(ctx, "Deleted")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.DeleteGroup(ctx, groupID); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.DeleteGroup(ctx, groupID); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v deleting a deleted group, want %v", err, repoerrors.ErrNotFound)
+		}
+
+		if _, err := backend.GetSongByID(ctx, songID); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v reading a song of the deleted group, want %v", err, repoerrors.ErrNotFound)
+		}
+		lyrics, err := backend.GetLyricsBySongIDs(ctx, []string{songID, keptID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := lyrics[songID]; ok {
+			t.Error("the lyrics of a song of the deleted group are left")
+		}
+		if _, ok := lyrics[keptID]; !ok {
+			t.Error("the lyrics of a song of another group are gone")
+		}
+	})
+}
+
+func TestSongUniqueTitle(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		addSong(t, backend, "Group", "Title")
+		otherID := addSong(t, backend, "Other group", "Title")
+
+		groupID, err := backend.GetGroupIDByName(ctx, "Group")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backend.CreateSong(ctx, &entity.Song{Title: "Title", GroupID: groupID}); !errors.Is(err, repoerrors.ErrAlreadyExists) {
+			t.Errorf("got error %v creating a song of a taken title, want %v", err, repoerrors.ErrAlreadyExists)
+		}
+
+		group := "Group"
+		err = backend.UpdateSong(ctx, &entity.SongUpdate{ID: otherID, GroupName: &group, GroupID: groupID})
+		if !errors.Is(err, repoerrors.ErrAlreadyExists) {
+			t.Errorf("got error %v moving a song to a group with its title, want %v", err, repoerrors.ErrAlreadyExists)
+		}
+		if err := backend.DeleteSong(ctx, missingID); !errors.Is(err, repoerrors.ErrNotFound) {
+			t.Errorf("got error %v deleting a missing song, want %v", err, repoerrors.ErrNotFound)
+		}
+	})
+}
+
+// TestSongReleaseDateOverlap filters songs released on a day, in a month or
+// in a year by date ranges, built as the listing builds them from its
+// startDate and endDate parameters: a partial release date matches when its
+// period overlaps the range.
+func TestSongReleaseDateOverlap(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		groupID, err := backend.CreateGroup(ctx, "Group")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, value := range []string{"2005", "2006", "2007", "2005-12", "2006-06", "2006-12", "2005-12-31", "2006-06-15", "2007-01-01", ""} {
+			song := &entity.Song{Title: value, GroupID: groupID}
+			if value == "" {
+				song.Title = "Undated"
+			} else {
+				date, err := partialdate.Parse(value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				song.ReleaseDate, song.ReleaseDatePrecision = &date.Time, string(date.Precision)
+			}
+			if _, err := backend.CreateSong(ctx, song); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, test := range []struct {
+			start, end string
+			want       []string
+		}{
+			{start: "2006", end: "2006", want: []string{"2006", "2006-06", "2006-06-15", "2006-12"}},
+			{start: "2006-06", end: "2006-06", want: []string{"2006", "2006-06", "2006-06-15"}},
+			{start: "2006-06-15", end: "2006-06-15", want: []string{"2006", "2006-06", "2006-06-15"}},
+			{start: "2005-12-31", end: "2006-01-01", want: []string{"2005", "2005-12", "2005-12-31", "2006"}},
+			{start: "2006-12", end: "2007", want: []string{"2006", "2006-12", "2007", "2007-01-01"}},
+		} {
+			start, err := partialdate.Parse(test.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			end, err := partialdate.Parse(test.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter := &entity.SongFilter{
+				StartDate: start.Time.Format(time.DateOnly),
+				EndDate:   end.End().AddDate(0, 0, -1).Format(time.DateOnly),
+			}
+			if got := songTitles(t, backend, filter); !slices.Equal(got, test.want) {
+				t.Errorf("startDate=%s&endDate=%s: got songs %q, want %q", test.start, test.end, got, test.want)
+			}
+		}
+	})
+}
+
+// addSong creates the song in the group, and the group unless it exists,
+// with lyrics of the given stanzas of lines. It returns the ID of the song.
+func addSong(t testing.TB, backend *repotest.Backend, group, title string, stanzas ...[]string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	groupID, err := backend.GetGroupIDByName(ctx, group)
+	if errors.Is(err, repoerrors.ErrNotFound) {
+		groupID, err = backend.CreateGroup(ctx, group)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	songID, err := backend.CreateSong(ctx, &entity.Song{Title: title, GroupID: groupID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addLyrics(t, backend, songID, stanzas...)
+
+	return songID
+}
+
+// addLyrics stores the stanzas of lines as the lyrics of the song.
+func addLyrics(t testing.TB, backend *repotest.Backend, songID string, stanzas ...[]string) {
+	t.Helper()
+
+	verseNumber := 1
+	for ind, lines := range stanzas {
+		stanza := &entity.LyricsStanza{SongID: songID, StanzaNumber: ind + 1}
+		for _, line := range lines {
+			stanza.Verses = append(stanza.Verses, entity.LyricsVerse{SongID: songID, Verse: line, VerseNumber: verseNumber})
+			verseNumber++
+		}
+		if err := backend.AddLyricsStanza(context.Background(), stanza); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func groupNames(t *testing.T, backend *repotest.Backend) []string {
+	t.Helper()
+
+	groups, err := backend.GetGroups(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// songTitles returns the sorted titles of the songs matching the filter.
+func songTitles(t *testing.T, backend *repotest.Backend, filter *entity.SongFilter) []string {
+	t.Helper()
+
+	if filter.Sort == nil {
+		filter.Sort = entity.DefaultSongSort
+	}
+	songs, err := backend.GetSongsByFilter(context.Background(), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titles := []string{}
+	for _, song := range songs {
+		titles = append(titles, song.Title)
+	}
+	slices.Sort(titles)
+
+	return titles
+}
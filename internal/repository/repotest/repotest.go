@@ -0,0 +1,170 @@
+// Package repotest sets up the repository backends for tests. The memory
+// backend is always available; the postgres one needs the URL of a database
+// the tests may wipe in TEST_PG_URL, and is skipped without it.
+package repotest
+
+import (
+	"context"
+	"effective_mobile_tz/internal/repository"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// PostgresURLEnv names the variable holding the URL of the test database.
+const PostgresURLEnv = "TEST_PG_URL"
+
+// lockKey is the advisory lock serializing the tests of every package that
+// share the test database.
+const lockKey = 7_350_211
+
+// SearchLanguage is the text search language the backends are set up with.
+const SearchLanguage = "english"
+
+// Backend is a set of repositories under test.
+type Backend struct {
+	Name string
+	*repository.Repository
+	// Pool is the connection pool of the postgres backend, nil for memory.
+	Pool *pgxpool.Pool
+}
+
+// Memory returns repositories backed by an empty in-memory store.
+func Memory(tb testing.TB) *Backend {
+	tb.Helper()
+
+	backend := &Backend{Name: "memory", Repository: repository.NewMemoryRepository()}
+	setSearchLanguage(tb, backend)
+
+	return backend
+}
+
+// Postgres returns repositories backed by the empty, migrated test database,
+// which the test holds until it ends. It skips the test when TEST_PG_URL is
+// not set.
+func Postgres(tb testing.TB) *Backend {
+	tb.Helper()
+
+	pool := Pool(tb)
+	backend := &Backend{Name: "postgres", Repository: repository.NewRepository(pool), Pool: pool}
+	setSearchLanguage(tb, backend)
+
+	return backend
+}
+
+// Pool connects the empty, migrated test database, which the test holds until
+// it ends. It skips the test when TEST_PG_URL is not set.
+func Pool(tb testing.TB) *pgxpool.Pool {
+	tb.Helper()
+
+	url := os.Getenv(PostgresURLEnv)
+	if url == "" {
+		tb.Skipf("%s is not set", PostgresURLEnv)
+	}
+	ctx := context.Background()
+
+	pool, err := pgxpool.Connect(ctx, url)
+	if err != nil {
+		tb.Fatalf("failed to connect postgres: %v", err)
+	}
+	tb.Cleanup(pool.Close)
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		tb.Fatalf("failed to acquire a connection: %v", err)
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		conn.Release()
+		tb.Fatalf("failed to lock the test database: %v", err)
+	}
+	tb.Cleanup(func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		conn.Release()
+	})
+
+	if err := migrateUp(url); err != nil {
+		tb.Fatal(err)
+	}
+	if err := truncate(ctx, pool); err != nil {
+		tb.Fatal(err)
+	}
+
+	return pool
+}
+
+// Each runs fn as a subtest against every backend, in a fresh state each.
+func Each(t *testing.T, fn func(t *testing.T, backend *Backend)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		fn(t, Memory(t))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		fn(t, Postgres(t))
+	})
+}
+
+func setSearchLanguage(tb testing.TB, backend *Backend) {
+	tb.Helper()
+
+	if _, err := backend.Search.SetSearchLanguage(context.Background(), SearchLanguage); err != nil {
+		tb.Fatalf("failed to set the search language: %v", err)
+	}
+}
+
+func migrateUp(url string) error {
+	_, file, _, _ := runtime.Caller(0)
+	migrations := filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations")
+
+	m, err := migrate.New("file://"+filepath.ToSlash(migrations), url)
+	if err != nil {
+		return fmt.Errorf("failed to initialize migration: %w", err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	return nil
+}
+
+// keptTables hold the migration bookkeeping and the settings the migrations
+// seed, which the tests start from.
+var keptTables = []string{"schema_migrations", "search_settings"}
+
+// truncate empties every other table.
+func truncate(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `SELECT quote_ident(tablename) FROM pg_tables WHERE schemaname = current_schema() AND tablename <> ALL($1)`, keptTables)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	if len(tables) > 0 {
+		if _, err := pool.Exec(ctx, `TRUNCATE `+strings.Join(tables, ", ")+` CASCADE`); err != nil {
+			return fmt.Errorf("failed to empty the tables: %w", err)
+		}
+	}
+
+	return nil
+}
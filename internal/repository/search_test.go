@@ -0,0 +1,212 @@
+package repository_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/pkg/textquery"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// addSearchSongs adds the songs the text search tests look for.
+func addSearchSongs(t *testing.T, backend *repotest.Backend) {
+	t.Helper()
+
+	addSong(t, backend, "Group", "Night run", []string{"We were running through the night", "Dogs barking"})
+	addSong(t, backend, "Group", "Love song", []string{"She loves the night"}, []string{"Love of my life"})
+	addSong(t, backend, "Group", "Quiet", []string{"Nothing here at all"})
+	addSong(t, backend, "Group", "Instrumental")
+}
+
+// TestSearchSongs searches the lyrics in english, which matches the words
+// by their stems and leaves stop words out.
+func TestSearchSongs(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		addSearchSongs(t, backend)
+
+		for _, test := range []struct {
+			query string
+			want  []string
+		}{
+			{query: "run", want: []string{"Night run"}},
+			{query: "loved", want: []string{"Love song"}},
+			{query: "night", want: []string{"Love song", "Night run"}},
+			{query: "lov*", want: []string{"Love song"}},
+			{query: "night -dog", want: []string{"Love song"}},
+			{query: "run OR life", want: []string{"Love song", "Night run"}},
+			{query: "night life", want: []string{"Love song"}},
+			{query: "night the OR life", want: []string{"Love song"}},
+			// the stop word keeps its place in the phrase
+			{query: `"loves the night"`, want: []string{"Love song"}},
+			{query: `"running the night"`, want: []string{}},
+			{query: "the", want: []string{}},
+			// as postgres ignores an empty tsquery, no text matches a clause
+			// of stop words only
+			{query: "night the", want: []string{}},
+		} {
+			query, err := textquery.Parse(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := backend.SearchSongs(context.Background(), query, 0, 0)
+			if err != nil {
+				t.Fatalf("%s: %v", test.query, err)
+			}
+
+			titles := []string{}
+			for _, result := range results {
+				titles = append(titles, result.Title)
+			}
+			slices.Sort(titles)
+			if !slices.Equal(titles, test.want) {
+				t.Errorf("%s: got songs %q, want %q", test.query, titles, test.want)
+			}
+		}
+	})
+}
+
+func TestSearchSongsVerses(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		addSearchSongs(t, backend)
+
+		query, err := textquery.Parse("loving")
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := backend.SearchSongs(context.Background(), query, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("got %d songs, want 1", len(results))
+		}
+
+		result := results[0]
+		if want := []int{1, 2}; !slices.Equal(result.Verses, want) {
+			t.Errorf("got verses %v, want %v", result.Verses, want)
+		}
+		for _, word := range []string{"<b>loves</b>", "<b>Love</b>"} {
+			if !strings.Contains(result.Snippet, word) {
+				t.Errorf("got snippet %q, want it to highlight %s", result.Snippet, word)
+			}
+		}
+	})
+}
+
+// TestSongFilterText filters songs by a text all of whose words but the stop
+// words a verse holds, in any of their forms.
+func TestSongFilterText(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		addSearchSongs(t, backend)
+
+		for _, test := range []struct {
+			text string
+			want []string
+		}{
+			{text: "runs", want: []string{"Night run"}},
+			{text: "loving night", want: []string{"Love song"}},
+			{text: "the night", want: []string{"Love song", "Night run"}},
+			// the words must be in the same verse
+			{text: "night life", want: []string{}},
+			{text: "the", want: []string{}},
+		} {
+			if got := songTitles(t, backend, &entity.SongFilter{Text: test.text}); !slices.Equal(got, test.want) {
+				t.Errorf("%s: got songs %q, want %q", test.text, got, test.want)
+			}
+		}
+	})
+}
+
+// TestSongFilterFuzzy filters songs by misspelt titles and groups, which
+// match by their word similarity alone.
+func TestSongFilterFuzzy(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		addSong(t, backend, "Muse", "Supermassive Black Hole")
+		addSong(t, backend, "Queen", "Bohemian Rhapsody")
+
+		for _, test := range []struct {
+			filter entity.SongFilter
+			want   []string
+		}{
+			{filter: entity.SongFilter{Group: "Mues"}, want: []string{"Supermassive Black Hole"}},
+			{filter: entity.SongFilter{Title: "rapsody"}, want: []string{"Bohemian Rhapsody"}},
+			{filter: entity.SongFilter{Title: "black", Group: "Quen"}, want: []string{}},
+			{filter: entity.SongFilter{Group: "Metallica"}, want: []string{}},
+		} {
+			filter := test.filter
+			filter.Fuzzy, filter.SimilarityThreshold = true, 0.3
+
+			if got := songTitles(t, backend, &filter); !slices.Equal(got, test.want) {
+				t.Errorf("%+v: got songs %q, want %q", test.filter, got, test.want)
+			}
+			count, err := backend.CountSongsByFilter(ctx, &filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(test.want) {
+				t.Errorf("%+v: counted %d songs, want %d", test.filter, count, len(test.want))
+			}
+		}
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		addSong(t, backend, "Muse", "Supermassive Black Hole")
+		addSong(t, backend, "Queen", "Bohemian Rhapsody")
+
+		titles, err := backend.SuggestTitles(ctx, "supermasive", 0.3, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(titles) != 1 || titles[0].Title != "Supermassive Black Hole" {
+			t.Errorf("got titles %+v, want the one of Muse", titles)
+		}
+
+		groups, err := backend.SuggestGroups(ctx, "quen", 0.3, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(groups) != 1 || groups[0].Name != "Queen" {
+			t.Errorf("got groups %+v, want Queen", groups)
+		}
+	})
+}
+
+// TestSongFilterHasLyrics selects the songs with lyrics and without, alone
+// and along with a text.
+func TestSongFilterHasLyrics(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		addSong(t, backend, "Group", "Night run", []string{"We were running through the night"})
+		addSong(t, backend, "Group", "Instrumental")
+		with, without := true, false
+
+		for _, test := range []struct {
+			name   string
+			filter entity.SongFilter
+			want   []string
+		}{
+			{name: "with lyrics", filter: entity.SongFilter{HasLyrics: &with}, want: []string{"Night run"}},
+			{name: "without lyrics", filter: entity.SongFilter{HasLyrics: &without}, want: []string{"Instrumental"}},
+			{name: "text with lyrics", filter: entity.SongFilter{Text: "night", HasLyrics: &with}, want: []string{"Night run"}},
+			{name: "text without lyrics", filter: entity.SongFilter{Text: "night", HasLyrics: &without}, want: []string{}},
+			{name: "text", filter: entity.SongFilter{Text: "run"}, want: []string{"Night run"}},
+		} {
+			filter := test.filter
+			if got := songTitles(t, backend, &filter); !slices.Equal(got, test.want) {
+				t.Errorf("%s: got songs %q, want %q", test.name, got, test.want)
+			}
+			count, err := backend.CountSongsByFilter(context.Background(), &filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(test.want) {
+				t.Errorf("%s: counted %d songs, want %d", test.name, count, len(test.want))
+			}
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package repository_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/internal/repository/repotest"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNestedTransactionRollback(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		errInner := errors.New("inner unit of work failed")
+
+		err := backend.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := backend.CreateGroup(ctx, "Before savepoint"); err != nil {
+				return err
+			}
+
+			err := backend.WithinTransaction(ctx, func(ctx context.Context) error {
+				if _, err := backend.CreateGroup(ctx, "In savepoint"); err != nil {
+					return err
+				}
+				return errInner
+			})
+			if !errors.Is(err, errInner) {
+				t.Errorf("got error %v from the savepoint, want %v", err, errInner)
+			}
+
+			// the outer transaction goes on after the savepoint is rolled back
+			_, err = backend.CreateGroup(ctx, "After savepoint")
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := groupNames(t, backend), []string{"After savepoint", "Before savepoint"}; !slices.Equal(got, want) {
+			t.Errorf("got groups %q, want %q", got, want)
+		}
+	})
+}
+
+func TestTransactionRollback(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		errFailed := errors.New("unit of work failed")
+
+		err := backend.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := backend.CreateGroup(ctx, "Rolled back"); err != nil {
+				return err
+			}
+
+			// uncommitted changes are visible within the transaction only
+			if _, err := backend.GetGroupIDByName(ctx, "Rolled back"); err != nil {
+				t.Errorf("group not found within its transaction: %v", err)
+			}
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("got error %v, want %v", err, errFailed)
+		}
+
+		if got := groupNames(t, backend); len(got) != 0 {
+			t.Errorf("got groups %q after the rollback, want none", got)
+		}
+	})
+}
+
+// TestUncommittedChanges reads outside of a transaction what it has written
+// but not committed yet.
+func TestUncommittedChanges(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+
+		err := backend.WithinTransaction(ctx, func(txCtx context.Context) error {
+			if _, err := backend.CreateGroup(txCtx, "Uncommitted"); err != nil {
+				return err
+			}
+
+			if _, err := backend.GetGroupIDByName(ctx, "Uncommitted"); !errors.Is(err, repoerrors.ErrNotFound) {
+				t.Errorf("got error %v reading the uncommitted group outside of its transaction, want %v", err, repoerrors.ErrNotFound)
+			}
+			if got := groupNames(t, backend); len(got) != 0 {
+				t.Errorf("got groups %q outside of the transaction, want none", got)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := groupNames(t, backend), []string{"Uncommitted"}; !slices.Equal(got, want) {
+			t.Errorf("got groups %q after the commit, want %q", got, want)
+		}
+	})
+}
+
+// TestSnapshot writes while a snapshot is read: the snapshot keeps the state
+// it started from and cannot be written to.
+func TestSnapshot(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		if _, err := backend.CreateGroup(ctx, "Before snapshot"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := backend.WithinSnapshot(ctx, func(snapshotCtx context.Context) error {
+			if _, err := backend.GetGroups(snapshotCtx); err != nil {
+				return err
+			}
+
+			if _, err := backend.CreateGroup(ctx, "During snapshot"); err != nil {
+				return err
+			}
+
+			groups, err := backend.GetGroups(snapshotCtx)
+			if err != nil {
+				return err
+			}
+			if len(groups) != 1 || groups[0].Name != "Before snapshot" {
+				t.Errorf("got groups %+v in the snapshot, want the one it started with", groups)
+			}
+
+			// a failed write aborts a postgres transaction, so it comes last
+			_, err = backend.CreateGroup(snapshotCtx, "In snapshot")
+			return err
+		})
+		if err == nil {
+			t.Error("created a group in a snapshot")
+		}
+
+		if got, want := groupNames(t, backend), []string{"Before snapshot", "During snapshot"}; !slices.Equal(got, want) {
+			t.Errorf("got groups %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+)
+
+type AlbumService struct {
+	albumRepo     repository.Album
+	groupRepo     repository.Group
+	songRepo      repository.Song
+	dbTransaction repository.DBTransaction
+}
+
+func NewAlbumService(albumRepo repository.Album, groupRepo repository.Group, songRepo repository.Song, dbTransaction repository.DBTransaction) *AlbumService {
+	return &AlbumService{
+		albumRepo:     albumRepo,
+		groupRepo:     groupRepo,
+		songRepo:      songRepo,
+		dbTransaction: dbTransaction,
+	}
+}
+
+// CreateAlbum creates an album for album.GroupName, creating the group if it
+// does not exist yet.
+func (s *AlbumService) CreateAlbum(ctx context.Context, album *entity.Album) (string, error) {
+	var albumID string
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		album.GroupID, err = s.groupRepo.GetGroupIDByName(ctx, album.GroupName)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				album.GroupID, err = s.groupRepo.CreateGroup(ctx, album.GroupName)
+				if err != nil {
+					return fmt.Errorf("failed to create group: %w", err)
+				}
+			} else {
+				return fmt.Errorf("failed to get group: %w", err)
+			}
+		}
+
+		albumID, err = s.albumRepo.CreateAlbum(ctx, album)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrAlreadyExists) {
+				return ErrAlbumAlreadyExists
+			}
+			return fmt.Errorf("failed to create album: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return albumID, nil
+}
+
+func (s *AlbumService) GetAlbums(ctx context.Context, filter *entity.AlbumFilter) ([]entity.Album, error) {
+	albums, err := s.albumRepo.GetAlbums(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve albums: %w", err)
+	}
+
+	return albums, nil
+}
+
+func (s *AlbumService) GetAlbumByID(ctx context.Context, albumID string) (*entity.Album, error) {
+	album, err := s.albumRepo.GetAlbumByID(ctx, albumID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrAlbumNotFound
+		}
+
+		return nil, fmt.Errorf("failed to retrieve the album: %w", err)
+	}
+
+	album.Songs, err = s.songRepo.GetSongsByAlbumID(ctx, albumID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve songs of the album: %w", err)
+	}
+
+	return album, nil
+}
+
+func (s *AlbumService) UpdateAlbum(ctx context.Context, update *entity.AlbumUpdate) error {
+	err := s.albumRepo.UpdateAlbum(ctx, update)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrAlbumNotFound
+		} else if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return ErrAlbumAlreadyExists
+		}
+
+		return fmt.Errorf("failed to update the album: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAlbum removes the album, its songs are kept and detached from it.
+func (s *AlbumService) DeleteAlbum(ctx context.Context, albumID string) error {
+	err := s.albumRepo.DeleteAlbum(ctx, albumID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrAlbumNotFound
+		}
+
+		return fmt.Errorf("failed to delete the album: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"testing"
+)
+
+// TestUpdateSongAlbumOfOtherGroup links a song to albums of its group and of
+// another one, and moves it to another group with and without an album.
+func TestUpdateSongAlbumOfOtherGroup(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		services := service.NewService(service.Dependencies{Repository: backend.Repository})
+		songID := importSong(t, backend, `{"group": "Muse", "title": "Starlight"}`)
+
+		albumIDs := make(map[string]string)
+		for _, group := range []string{"Muse", "Queen"} {
+			albumID, err := services.Album.CreateAlbum(ctx, &entity.Album{Title: "Album of " + group, GroupName: group, Type: "album"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			albumIDs[group] = albumID
+		}
+		queen, muse := "Queen", "Muse"
+
+		for _, test := range []struct {
+			name   string
+			update entity.SongUpdate
+			want   error
+		}{
+			{name: "album of another group", update: entity.SongUpdate{AlbumID: ptr(albumIDs["Queen"])}, want: service.ErrAlbumOfOtherGroup},
+			{name: "album of the group", update: entity.SongUpdate{AlbumID: ptr(albumIDs["Muse"])}},
+			{name: "group without its album", update: entity.SongUpdate{GroupName: &queen}, want: service.ErrAlbumOfOtherGroup},
+			{name: "group with its album", update: entity.SongUpdate{GroupName: &queen, AlbumID: ptr(albumIDs["Queen"])}},
+			{name: "group with another album", update: entity.SongUpdate{GroupName: &muse, AlbumID: ptr(albumIDs["Queen"])}, want: service.ErrAlbumOfOtherGroup},
+			{name: "group detached from the album", update: entity.SongUpdate{GroupName: &muse, AlbumID: ptr("")}},
+		} {
+			update := test.update
+			update.ID = songID
+			if err := services.Song.UpdateSong(ctx, &update); !errors.Is(err, test.want) {
+				t.Errorf("%s: got error %v, want %v", test.name, err, test.want)
+			}
+		}
+
+		song, err := backend.GetSongByID(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.GroupName != "Muse" || song.AlbumID != nil {
+			t.Errorf("got song of %q on album %v, want it of Muse on none", song.GroupName, song.AlbumID)
+		}
+	})
+}
+
+func ptr(value string) *string {
+	return &value
+}
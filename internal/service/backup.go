@@ -0,0 +1,647 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/pkg/partialdate"
+	"effective_mobile_tz/pkg/stanza"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ArchiveFormatJSON   = "json"
+	ArchiveFormatNDJSON = "ndjson"
+)
+
+type BackupService struct {
+	archiveRepo   repository.Archive
+	dbTransaction repository.DBTransaction
+}
+
+func NewBackupService(archiveRepo repository.Archive, dbTransaction repository.DBTransaction) *BackupService {
+	return &BackupService{
+		archiveRepo:   archiveRepo,
+		dbTransaction: dbTransaction,
+	}
+}
+
+// archiveRecord is a line of an NDJSON archive.
+type archiveRecord struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// archiveSections lists the record types in the order they are exported and
+// must be restored, together with their key in a JSON archive.
+var archiveSections = []struct {
+	recordType string
+	key        string
+}{
+	{entity.ArchiveRecordGroup, "groups"},
+	{entity.ArchiveRecordAlbum, "albums"},
+	{entity.ArchiveRecordTag, "tags"},
+	{entity.ArchiveRecordSong, "songs"},
+	{entity.ArchiveRecordVerse, "verses"},
+}
+
+// ExportLibrary streams every group, album, tag, song and lyrics verse to w as
+// a versioned JSON or NDJSON archive, gzip-compressed if asked to.
+func (s *BackupService) ExportLibrary(ctx context.Context, w io.Writer, format string, compress bool) error {
+	if format != ArchiveFormatJSON && format != ArchiveFormatNDJSON {
+		return fmt.Errorf("%w: unsupported format %q", ErrInvalidArchive, format)
+	}
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	bw := bufio.NewWriter(w)
+
+	header := entity.ArchiveHeader{
+		Version:    entity.ArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	// reading within a snapshot, so that the archive is consistent
+	err := s.dbTransaction.WithinSnapshot(ctx, func(ctx context.Context) error {
+		if format == ArchiveFormatNDJSON {
+			return writeNDJSONArchive(ctx, bw, s.archiveRepo, header)
+		}
+		return writeJSONArchive(ctx, bw, s.archiveRepo, header)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if gz != nil {
+		// writing the gzip footer may fail, which leaves the archive truncated
+		return gz.Close()
+	}
+
+	return nil
+}
+
+func writeNDJSONArchive(ctx context.Context, w io.Writer, archiveRepo repository.Archive, header entity.ArchiveHeader) error {
+	encoder := json.NewEncoder(w)
+
+	write := func(recordType string, data interface{}) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", recordType, err)
+		}
+		return encoder.Encode(archiveRecord{Type: recordType, Data: raw})
+	}
+
+	if err := write(entity.ArchiveRecordHeader, header); err != nil {
+		return err
+	}
+
+	return exportArchiveSections(ctx, archiveRepo, func(recordType, _ string) error {
+		return nil
+	}, write, func() error {
+		return nil
+	})
+}
+
+func writeJSONArchive(ctx context.Context, w io.Writer, archiveRepo repository.Archive, header entity.ArchiveHeader) error {
+	exportedAt, err := json.Marshal(header.ExportedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "{\"version\":%d,\"exportedAt\":%s", header.Version, exportedAt); err != nil {
+		return err
+	}
+
+	first := true
+	startSection := func(_, key string) error {
+		first = true
+		_, err := fmt.Fprintf(w, ",\n\"%s\":[", key)
+		return err
+	}
+	write := func(recordType string, data interface{}) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", recordType, err)
+		}
+
+		separator := ",\n"
+		if first {
+			separator, first = "\n", false
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		return err
+	}
+	endSection := func() error {
+		_, err := io.WriteString(w, "]")
+		return err
+	}
+
+	if err := exportArchiveSections(ctx, archiveRepo, startSection, write, endSection); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+func exportArchiveSections(
+	ctx context.Context,
+	archiveRepo repository.Archive,
+	startSection func(recordType, key string) error,
+	write func(recordType string, data interface{}) error,
+	endSection func() error,
+) error {
+	for _, section := range archiveSections {
+		if err := startSection(section.recordType, section.key); err != nil {
+			return err
+		}
+
+		var err error
+		switch section.recordType {
+		case entity.ArchiveRecordGroup:
+			err = archiveRepo.ExportGroups(ctx, func(group *entity.ArchiveGroup) error {
+				return write(section.recordType, group)
+			})
+		case entity.ArchiveRecordAlbum:
+			err = archiveRepo.ExportAlbums(ctx, func(album *entity.ArchiveAlbum) error {
+				return write(section.recordType, album)
+			})
+		case entity.ArchiveRecordTag:
+			err = archiveRepo.ExportTags(ctx, func(tag *entity.ArchiveTag) error {
+				return write(section.recordType, tag)
+			})
+		case entity.ArchiveRecordSong:
+			err = archiveRepo.ExportSongs(ctx, func(song *entity.ArchiveSong) error {
+				return write(section.recordType, song)
+			})
+		case entity.ArchiveRecordVerse:
+			err = archiveRepo.ExportVerses(ctx, func(verse *entity.ArchiveVerse) error {
+				return write(section.recordType, verse)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", section.key, err)
+		}
+
+		if err := endSection(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreLibrary replays a JSON or NDJSON archive, optionally gzip-compressed,
+// within a single transaction. Records keep their archived IDs. Under the skip
+// policy records that clash with existing ones are left alone and records
+// referring to them are attached to the existing ones; overwrite replaces
+// records with the same ID, or the existing records they clash with on their
+// name or title, and attaches the records referring to them accordingly; fail
+// aborts the whole restore on the first clash.
+func (s *BackupService) RestoreLibrary(ctx context.Context, r io.Reader, policy string) (*entity.RestoreReport, error) {
+	switch policy {
+	case entity.RestorePolicySkip, entity.RestorePolicyOverwrite, entity.RestorePolicyFail:
+	default:
+		return nil, fmt.Errorf("%w: unknown restore policy %q", ErrInvalidArchive, policy)
+	}
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	restorer := &archiveRestorer{
+		archiveRepo: s.archiveRepo,
+		report:      &entity.RestoreReport{Policy: policy},
+		groupIDs:    make(map[string]string),
+		albumIDs:    make(map[string]string),
+		tagIDs:      make(map[string]string),
+		songIDs:     make(map[string]string),
+		legacy:      make(map[string]*legacyLyrics),
+	}
+
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := readArchive(br, restorer.header, func(recordType string, raw json.RawMessage) error {
+			return restorer.restore(ctx, recordType, raw)
+		})
+		if err != nil {
+			return err
+		}
+
+		return restorer.restoreLegacyLabelLines(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restorer.report, nil
+}
+
+// readArchive detects the archive format by its first line: an NDJSON archive
+// starts with a complete header record.
+func readArchive(br *bufio.Reader, onHeader func(header *entity.ArchiveHeader) error, onRecord func(recordType string, raw json.RawMessage) error) error {
+	firstLine, err := br.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if len(bytes.TrimSpace(firstLine)) == 0 {
+		return fmt.Errorf("%w: empty archive", ErrInvalidArchive)
+	}
+
+	var record archiveRecord
+	if json.Unmarshal(firstLine, &record) == nil && record.Type == entity.ArchiveRecordHeader {
+		return readNDJSONArchive(io.MultiReader(bytes.NewReader(firstLine), br), onHeader, onRecord)
+	}
+
+	return readJSONArchive(io.MultiReader(bytes.NewReader(firstLine), br), onHeader, onRecord)
+}
+
+func readNDJSONArchive(r io.Reader, onHeader func(header *entity.ArchiveHeader) error, onRecord func(recordType string, raw json.RawMessage) error) error {
+	decoder := json.NewDecoder(r)
+
+	for number := 1; ; number++ {
+		var record archiveRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: record %d: %s", ErrInvalidArchive, number, err)
+		}
+
+		if record.Type == entity.ArchiveRecordHeader {
+			var header entity.ArchiveHeader
+			if err := json.Unmarshal(record.Data, &header); err != nil {
+				return fmt.Errorf("%w: invalid header: %s", ErrInvalidArchive, err)
+			}
+			if err := onHeader(&header); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := onRecord(record.Type, record.Data); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONArchive(r io.Reader, onHeader func(header *entity.ArchiveHeader) error, onRecord func(recordType string, raw json.RawMessage) error) error {
+	decoder := json.NewDecoder(r)
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	var header entity.ArchiveHeader
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "version":
+			if err := decoder.Decode(&header.Version); err != nil {
+				return fmt.Errorf("%w: invalid version: %s", ErrInvalidArchive, err)
+			}
+			if err := onHeader(&header); err != nil {
+				return err
+			}
+			continue
+		case "exportedAt":
+			if err := decoder.Decode(&header.ExportedAt); err != nil {
+				return fmt.Errorf("%w: invalid exportedAt: %s", ErrInvalidArchive, err)
+			}
+			continue
+		}
+
+		recordType := ""
+		for _, section := range archiveSections {
+			if section.key == key {
+				recordType = section.recordType
+			}
+		}
+		if recordType == "" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return fmt.Errorf("%w: %s: %s", ErrInvalidArchive, key, err)
+			}
+			if err := onRecord(recordType, raw); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	if token != delim {
+		return fmt.Errorf("%w: expected %s", ErrInvalidArchive, delim)
+	}
+
+	return nil
+}
+
+// archiveRestorer writes archived records and keeps track of the IDs they got,
+// so that references of later records can be redirected to existing records.
+type archiveRestorer struct {
+	archiveRepo repository.Archive
+	report      *entity.RestoreReport
+	groupIDs    map[string]string
+	albumIDs    map[string]string
+	tagIDs      map[string]string
+	// songIDs holds only written songs; verses of skipped songs are skipped too
+	songIDs map[string]string
+	// legacy holds, by archived song ID, how far the verses of a version 1
+	// archive have been split into stanzas
+	legacy map[string]*legacyLyrics
+}
+
+// legacyLyrics is the position reached in the verses of a song archived
+// before stanzas, which come in order with blank verses between stanzas.
+type legacyLyrics struct {
+	stanzaNumber int
+	verseNumber  int
+	blank        bool
+	// labelLine is the label line opening the current stanza, held until the
+	// next verse tells whether it labels more lines or is a line of its own
+	labelLine *entity.ArchiveVerse
+}
+
+func (ar *archiveRestorer) header(header *entity.ArchiveHeader) error {
+	if header.Version < 1 || header.Version > entity.ArchiveVersion {
+		return fmt.Errorf("%w: unsupported archive version %d", ErrInvalidArchive, header.Version)
+	}
+	ar.report.Version = header.Version
+
+	return nil
+}
+
+func (ar *archiveRestorer) restore(ctx context.Context, recordType string, raw json.RawMessage) error {
+	if ar.report.Version == 0 {
+		return fmt.Errorf("%w: records before the archive header", ErrInvalidArchive)
+	}
+
+	switch recordType {
+	case entity.ArchiveRecordGroup:
+		var group entity.ArchiveGroup
+		if err := decodeArchiveRecord(raw, &group); err != nil {
+			return err
+		}
+
+		id, written, err := ar.archiveRepo.RestoreGroup(ctx, &group, ar.report.Policy)
+		if err != nil {
+			return restoreError(recordType, group.ID, err)
+		}
+		ar.groupIDs[group.ID] = id
+		countRestored(&ar.report.Groups, written)
+
+	case entity.ArchiveRecordAlbum:
+		var album entity.ArchiveAlbum
+		if err := decodeArchiveRecord(raw, &album); err != nil {
+			return err
+		}
+		album.GroupID = mappedID(ar.groupIDs, album.GroupID)
+
+		id, written, err := ar.archiveRepo.RestoreAlbum(ctx, &album, ar.report.Policy)
+		if err != nil {
+			return restoreError(recordType, album.ID, err)
+		}
+		ar.albumIDs[album.ID] = id
+		countRestored(&ar.report.Albums, written)
+
+	case entity.ArchiveRecordTag:
+		var tag entity.ArchiveTag
+		if err := decodeArchiveRecord(raw, &tag); err != nil {
+			return err
+		}
+
+		id, written, err := ar.archiveRepo.RestoreTag(ctx, &tag, ar.report.Policy)
+		if err != nil {
+			return restoreError(recordType, tag.ID, err)
+		}
+		ar.tagIDs[tag.ID] = id
+		countRestored(&ar.report.Tags, written)
+
+	case entity.ArchiveRecordSong:
+		var song entity.ArchiveSong
+		if err := decodeArchiveRecord(raw, &song); err != nil {
+			return err
+		}
+		if song.ReleaseDatePrecision != "" && !partialdate.Precision(song.ReleaseDatePrecision).Valid() {
+			return fmt.Errorf("%w: song %s has an unknown release date precision %q", ErrInvalidArchive, song.ID, song.ReleaseDatePrecision)
+		}
+		song.GroupID = mappedID(ar.groupIDs, song.GroupID)
+		if song.AlbumID != nil {
+			albumID := mappedID(ar.albumIDs, *song.AlbumID)
+			song.AlbumID = &albumID
+		}
+		for ind, tagID := range song.TagIDs {
+			song.TagIDs[ind] = mappedID(ar.tagIDs, tagID)
+		}
+
+		id, written, err := ar.archiveRepo.RestoreSong(ctx, &song, ar.report.Policy)
+		if err != nil {
+			return restoreError(recordType, song.ID, err)
+		}
+		if written {
+			ar.songIDs[song.ID] = id
+		}
+		countRestored(&ar.report.Songs, written)
+
+	case entity.ArchiveRecordVerse:
+		var verse entity.ArchiveVerse
+		if err := decodeArchiveRecord(raw, &verse); err != nil {
+			return err
+		}
+
+		songID, ok := ar.songIDs[verse.SongID]
+		if !ok {
+			countRestored(&ar.report.Verses, false)
+			return nil
+		}
+
+		if ar.report.Version == 1 {
+			return ar.restoreVerses(ctx, songID, ar.splitLegacyVerse(&verse))
+		}
+		if verse.StanzaNumber < 1 {
+			return fmt.Errorf("%w: verse %s has no stanza", ErrInvalidArchive, verse.ID)
+		}
+		if verse.StanzaLabel != nil {
+			if err := validateStanzaLabel(*verse.StanzaLabel); err != nil {
+				return fmt.Errorf("%w: verse %s: %s", ErrInvalidArchive, verse.ID, err)
+			}
+		}
+
+		return ar.restoreVerses(ctx, songID, []entity.ArchiveVerse{verse})
+
+	default:
+		return fmt.Errorf("%w: unknown record type %q", ErrInvalidArchive, recordType)
+	}
+
+	return nil
+}
+
+// restoreVerses restores the verses as lines of the song.
+func (ar *archiveRestorer) restoreVerses(ctx context.Context, songID string, verses []entity.ArchiveVerse) error {
+	for _, verse := range verses {
+		verse.SongID = songID
+		if err := ar.archiveRepo.RestoreVerse(ctx, &verse); err != nil {
+			return restoreError(entity.ArchiveRecordVerse, verse.ID, err)
+		}
+		countRestored(&ar.report.Verses, true)
+	}
+
+	return nil
+}
+
+// splitLegacyVerse numbers the verse of a version 1 archive as a line of its
+// stanza, and returns the lines to restore. Blank verses set stanzas apart
+// and a label line such as [Chorus] opening a stanza of more lines labels it,
+// and neither is restored itself. A label line is held until the next verse,
+// and returned along with it as a line of its own if it is blank.
+func (ar *archiveRestorer) splitLegacyVerse(verse *entity.ArchiveVerse) []entity.ArchiveVerse {
+	lyrics, ok := ar.legacy[verse.SongID]
+	if !ok {
+		lyrics = &legacyLyrics{blank: true}
+		ar.legacy[verse.SongID] = lyrics
+	}
+
+	if strings.TrimSpace(verse.Verse) == "" {
+		lyrics.blank = true
+		countRestored(&ar.report.Verses, false)
+		return lyrics.releaseLabelLine()
+	}
+
+	var label *string
+	if lyrics.labelLine != nil {
+		text, _ := stanza.Label(lyrics.labelLine.Verse)
+		label = &text
+		lyrics.labelLine = nil
+		countRestored(&ar.report.Verses, false)
+	} else if lyrics.blank {
+		lyrics.stanzaNumber++
+		lyrics.blank = false
+		if text, ok := stanza.Label(verse.Verse); ok && validateStanzaLabel(text) == nil {
+			held := *verse
+			lyrics.labelLine = &held
+			return nil
+		}
+	}
+
+	return []entity.ArchiveVerse{lyrics.line(*verse, label)}
+}
+
+// restoreLegacyLabelLines restores the label lines that end the lyrics of
+// their songs, which are lines of their own.
+func (ar *archiveRestorer) restoreLegacyLabelLines(ctx context.Context) error {
+	for archivedID, lyrics := range ar.legacy {
+		if err := ar.restoreVerses(ctx, ar.songIDs[archivedID], lyrics.releaseLabelLine()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// line numbers the verse as the next line of the current stanza.
+func (l *legacyLyrics) line(verse entity.ArchiveVerse, label *string) entity.ArchiveVerse {
+	l.verseNumber++
+	verse.StanzaNumber = l.stanzaNumber
+	verse.VerseNumber = l.verseNumber
+	verse.StanzaLabel = label
+
+	return verse
+}
+
+// releaseLabelLine returns the label line held, if any, as the only line of
+// its stanza.
+func (l *legacyLyrics) releaseLabelLine() []entity.ArchiveVerse {
+	if l.labelLine == nil {
+		return nil
+	}
+	verse := l.line(*l.labelLine, nil)
+	l.labelLine = nil
+
+	return []entity.ArchiveVerse{verse}
+}
+
+func decodeArchiveRecord(raw json.RawMessage, record interface{}) error {
+	if err := json.Unmarshal(raw, record); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	return nil
+}
+
+func restoreError(recordType, id string, err error) error {
+	if errors.Is(err, repoerrors.ErrAlreadyExists) {
+		return fmt.Errorf("%w: %s %s clashes with an existing %s", ErrRestoreConflict, recordType, id, recordType)
+	}
+	if errors.Is(err, repoerrors.ErrNotFound) {
+		return fmt.Errorf("%w: %s %s refers to a missing record", ErrInvalidArchive, recordType, id)
+	}
+
+	return fmt.Errorf("failed to restore %s %s: %w", recordType, id, err)
+}
+
+// mappedID returns the ID an archived record got in the database, or the
+// archived ID if the record is not part of the archive.
+func mappedID(ids map[string]string, id string) string {
+	if mapped, ok := ids[id]; ok {
+		return mapped
+	}
+
+	return id
+}
+
+func countRestored(counts *entity.RestoreCounts, written bool) {
+	if written {
+		counts.Restored++
+	} else {
+		counts.Skipped++
+	}
+}
@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRestoreLegacyLabels restores a version 1 archive, whose label lines
+// label the stanzas of more lines they open and are lines of their own
+// otherwise.
+func TestRestoreLegacyLabels(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		services := service.NewService(service.Dependencies{Repository: backend.Repository})
+
+		const groupID, songID = "7c9e6679-7425-40de-944b-e07fc1f90ae7", "0b3a2f6e-2c7d-4a4e-9d8f-1e5c6b7a8d90"
+		var verses []string
+		for ind, line := range []string{"[Chorus]", "Sing along", "Sing again", "", "[Bridge]", "", "Plain line", "", " [ Outro ] "} {
+			verses = append(verses, fmt.Sprintf(`{"id": "00000000-0000-4000-8000-%012d", "songId": %q, "verse": %q, "verseNumber": %d}`, ind+1, songID, line, ind+1))
+		}
+		archive := fmt.Sprintf(`{
+			"version": 1,
+			"groups": [{"id": %q, "name": "Group"}],
+			"songs": [{"id": %q, "title": "Song", "groupId": %q, "link": ""}],
+			"verses": [%s]
+		}`, groupID, songID, groupID, strings.Join(verses, ", "))
+
+		report, err := services.Backup.RestoreLibrary(ctx, strings.NewReader(archive), entity.RestorePolicyFail)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (entity.RestoreCounts{Restored: 5, Skipped: 4}); report.Verses != want {
+			t.Errorf("got verses %+v, want %+v", report.Verses, want)
+		}
+
+		lyrics, err := backend.GetLyricsBySongIDs(ctx, []string{songID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, stanza := range lyrics[songID] {
+			label := ""
+			if stanza.Label != nil {
+				label = *stanza.Label
+			}
+			var lines []string
+			for _, verse := range stanza.Verses {
+				lines = append(lines, fmt.Sprintf("%d %s", verse.VerseNumber, verse.Verse))
+			}
+			got = append(got, fmt.Sprintf("%d %q: %s", stanza.StanzaNumber, label, strings.Join(lines, ", ")))
+		}
+		want := []string{
+			`1 "Chorus": 1 Sing along, 2 Sing again`,
+			`2 "": 3 [Bridge]`,
+			`3 "": 4 Plain line`,
+			`4 "": 5  [ Outro ] `,
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("got stanzas %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type EnrichmentConfig struct {
+	// Workers is the number of songs enriched concurrently.
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for due
+	// jobs again.
+	PollInterval time.Duration
+	// Lease is how long a claimed job is held by its worker. A job still
+	// running after that is claimed again, as its worker is presumed dead.
+	Lease time.Duration
+	// MaxAttempts attempts are made before a job fails for good.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled for every
+	// further one up to RetryMaxBackoff. Delays are jittered.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// releaseTimeout bounds putting back the jobs interrupted by the shutdown.
+const releaseTimeout = 5 * time.Second
+
+// EnrichmentService fills songs in with the details of the metadata provider
+// in the background, through the queue of enrichment jobs.
+type EnrichmentService struct {
+	enrichmentRepo repository.Enrichment
+	songRepo       repository.Song
+	lyricsRepo     repository.Lyrics
+	dbTransaction  repository.DBTransaction
+	songService    *SongService
+	cfg            EnrichmentConfig
+
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+	stopClaims context.CancelFunc
+	cancelJobs context.CancelFunc
+}
+
+func NewEnrichmentService(enrichmentRepo repository.Enrichment, songRepo repository.Song, lyricsRepo repository.Lyrics, dbTransaction repository.DBTransaction, songService *SongService, cfg EnrichmentConfig) *EnrichmentService {
+	return &EnrichmentService{
+		enrichmentRepo: enrichmentRepo,
+		songRepo:       songRepo,
+		lyricsRepo:     lyricsRepo,
+		dbTransaction:  dbTransaction,
+		songService:    songService,
+		cfg:            cfg,
+	}
+}
+
+func (s *EnrichmentService) GetEnrichmentJob(ctx context.Context, songID string) (*entity.EnrichmentJob, error) {
+	job, err := s.enrichmentRepo.GetEnrichmentJobBySongID(ctx, songID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrEnrichmentJobNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve the enrichment job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *EnrichmentService) GetEnrichmentJobs(ctx context.Context, filter *entity.EnrichmentJobFilter) ([]entity.EnrichmentJob, error) {
+	jobs, err := s.enrichmentRepo.GetEnrichmentJobs(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve enrichment jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// RequeueEnrichment puts the failed enrichment of the song back in the queue.
+func (s *EnrichmentService) RequeueEnrichment(ctx context.Context, songID string) error {
+	requeued, err := s.enrichmentRepo.RequeueFailedEnrichmentJobs(ctx, songID)
+	if err != nil {
+		return fmt.Errorf("failed to requeue the enrichment: %w", err)
+	}
+	if requeued > 0 {
+		return nil
+	}
+
+	if _, err := s.GetEnrichmentJob(ctx, songID); err != nil {
+		return err
+	}
+
+	return ErrEnrichmentNotFailed
+}
+
+// RequeueFailedEnrichments puts every failed enrichment back in the queue and
+// returns how many there were.
+func (s *EnrichmentService) RequeueFailedEnrichments(ctx context.Context) (int, error) {
+	requeued, err := s.enrichmentRepo.RequeueFailedEnrichmentJobs(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue enrichments: %w", err)
+	}
+
+	return requeued, nil
+}
+
+// StartWorkers starts the worker pool. Workers run until StopWorkers.
+func (s *EnrichmentService) StartWorkers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopClaims != nil || s.cfg.Workers <= 0 {
+		return
+	}
+
+	claimCtx, stopClaims := context.WithCancel(context.Background())
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	s.stopClaims, s.cancelJobs = stopClaims, cancelJobs
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.work(claimCtx, jobCtx)
+		}()
+	}
+}
+
+// StopWorkers stops claiming jobs and waits for the running ones to finish.
+// The jobs still running once ctx is done are interrupted and put back in the
+// queue.
+func (s *EnrichmentService) StopWorkers(ctx context.Context) error {
+	s.mu.Lock()
+	stopClaims, cancelJobs := s.stopClaims, s.cancelJobs
+	s.mu.Unlock()
+
+	if stopClaims == nil {
+		return nil
+	}
+	stopClaims()
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		cancelJobs()
+		return nil
+	case <-ctx.Done():
+		cancelJobs()
+		<-drained
+		return fmt.Errorf("enrichment workers interrupted: %w", ctx.Err())
+	}
+}
+
+func (s *EnrichmentService) work(claimCtx, jobCtx context.Context) {
+	for {
+		if claimCtx.Err() != nil {
+			return
+		}
+
+		jobs, err := s.enrichmentRepo.ClaimEnrichmentJobs(claimCtx, 1, s.cfg.Lease)
+		if err != nil && claimCtx.Err() == nil {
+			log.Errorf("failed to claim enrichment jobs: %s", err)
+		}
+
+		if len(jobs) == 0 {
+			select {
+			case <-claimCtx.Done():
+				return
+			case <-time.After(s.cfg.PollInterval):
+			}
+			continue
+		}
+
+		for _, job := range jobs {
+			s.process(jobCtx, job)
+		}
+	}
+}
+
+// process enriches the song of the claimed job and records the outcome.
+func (s *EnrichmentService) process(ctx context.Context, job entity.EnrichmentJob) {
+	err := s.enrich(ctx, job.SongID)
+
+	// the outcome is recorded even when ctx was cancelled by the shutdown
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	switch {
+	case err == nil:
+		err = s.enrichmentRepo.CompleteEnrichmentJob(recordCtx, job.ID, job.Claim)
+	case errors.Is(err, ErrSongNotFound):
+		// the song was deleted together with its job
+		return
+	case ctx.Err() != nil:
+		log.Infof("enrichment of song %s interrupted, putting it back in the queue", job.SongID)
+		err = s.enrichmentRepo.RetryEnrichmentJob(recordCtx, job.ID, job.Claim, 0, "interrupted by shutdown")
+	case errors.Is(err, ErrSongDetailNotFound) || errors.Is(err, ErrInvalidSongDetail) || job.Attempts >= s.cfg.MaxAttempts:
+		log.Warnf("enrichment of song %s failed after %d attempts: %s", job.SongID, job.Attempts, err)
+		err = s.enrichmentRepo.FailEnrichmentJob(recordCtx, job.ID, job.Claim, err.Error())
+	default:
+		delay := s.backoff(job.Attempts)
+		log.Debugf("enrichment of song %s failed, retrying in %s: %s", job.SongID, delay, err)
+		err = s.enrichmentRepo.RetryEnrichmentJob(recordCtx, job.ID, job.Claim, delay, err.Error())
+	}
+
+	switch {
+	case errors.Is(err, repoerrors.ErrNotFound):
+		// the job was claimed again after the lease ran out, reset or deleted
+		log.Warnf("enrichment job of song %s is no longer held, its outcome is dropped", job.SongID)
+	case err != nil:
+		log.Errorf("failed to record the enrichment of song %s: %s", job.SongID, err)
+	}
+}
+
+// enrich fills in the release date, link and lyrics of the song from the
+// metadata provider, keeping those the song already has.
+func (s *EnrichmentService) enrich(ctx context.Context, songID string) error {
+	song, err := s.songRepo.GetSongByID(ctx, songID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrSongNotFound
+		}
+		return fmt.Errorf("failed to retrieve the song: %w", err)
+	}
+
+	songDetail, err := s.songService.lookupSongDetail(ctx, song.GroupName, song.Title)
+	if err != nil {
+		return err
+	}
+
+	releaseDate, err := songDetailReleaseDate(songDetail)
+	if err != nil {
+		return err
+	}
+
+	update := &entity.SongUpdate{ID: songID}
+	if song.ReleaseDate == nil && releaseDate != nil {
+		setReleaseDate(update, *releaseDate)
+	}
+	if song.Link == "" && songDetail.Link != "" {
+		update.Link = &songDetail.Link
+	}
+
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if update.ReleaseDate != nil || update.Link != nil {
+			err := s.songRepo.UpdateSong(ctx, update)
+			if err != nil {
+				if errors.Is(err, repoerrors.ErrNotFound) {
+					return ErrSongNotFound
+				}
+				return fmt.Errorf("failed to update the song: %w", err)
+			}
+		}
+
+		if songDetail.Text == "" {
+			return nil
+		}
+
+		verses, err := s.lyricsRepo.CountLyricsVerses(ctx, songID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve lyrics of the song: %w", err)
+		}
+		if verses > 0 {
+			return nil
+		}
+
+		if err := s.songService.addLyrics(ctx, songID, songDetail.Text); err != nil {
+			return fmt.Errorf("failed to add lyrics for the song: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// backoff returns the delay before the retry following the given attempt:
+// half of the exponential delay plus a random share of the other half.
+func (s *EnrichmentService) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBackoff
+	for i := 1; i < attempt && (s.cfg.RetryMaxBackoff <= 0 || delay < s.cfg.RetryMaxBackoff); i++ {
+		delay *= 2
+	}
+	if s.cfg.RetryMaxBackoff > 0 && delay > s.cfg.RetryMaxBackoff {
+		delay = s.cfg.RetryMaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
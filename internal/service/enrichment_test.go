@@ -0,0 +1,240 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/metadata"
+	"effective_mobile_tz/internal/mockinfo"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"effective_mobile_tz/internal/webapi"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const fixture = `
+- group: Muse
+  song: Supermassive Black Hole
+  releaseDate: 16.07.2006
+  text: "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight"
+  link: https://www.youtube.com/watch?v=Xsp3_a-PMTw
+`
+
+// mockAPI is the mock song info API serving the fixture. Its first failures
+// requests are answered with an injected error.
+type mockAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+}
+
+func newMockAPI(t *testing.T, failures int) *mockAPI {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "songs.yaml")
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	songs, err := metadata.NewFixtureProvider(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &mockAPI{}
+	healthy := mockinfo.NewHandler(songs, mockinfo.Config{})
+	failing := mockinfo.NewHandler(songs, mockinfo.Config{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		api.mu.Lock()
+		handler := healthy
+		if len(api.statuses) < failures {
+			handler = failing
+		}
+		api.mu.Unlock()
+
+		handler.ServeHTTP(recorder, r)
+
+		api.mu.Lock()
+		api.statuses = append(api.statuses, recorder.status)
+		api.mu.Unlock()
+	}))
+	t.Cleanup(api.Close)
+
+	return api
+}
+
+// served returns the statuses of the responses so far.
+func (a *mockAPI) served() []int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return slices.Clone(a.statuses)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// enrichingServices returns services enriching songs from the API in the
+// background, until the test ends.
+func enrichingServices(t *testing.T, backend *repotest.Backend, api *mockAPI, maxAttempts int) *service.Service {
+	t.Helper()
+
+	songInfo := webapi.NewSongInfoClient(webapi.Config{URL: api.URL, Timeout: time.Second})
+	services := service.NewService(service.Dependencies{
+		Repository: backend.Repository,
+		Metadata:   metadata.NewAPIProvider(songInfo),
+		Enrichment: service.EnrichmentConfig{
+			Workers:      2,
+			PollInterval: 5 * time.Millisecond,
+			Lease:        time.Minute,
+			MaxAttempts:  maxAttempts,
+			RetryBackoff: time.Millisecond,
+		},
+	})
+
+	services.Enrichment.StartWorkers()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := services.Enrichment.StopWorkers(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return services
+}
+
+// waitForJob waits until the enrichment of the song is done or failed.
+func waitForJob(t *testing.T, services *service.Service, songID string) *entity.EnrichmentJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := services.Enrichment.GetEnrichmentJob(context.Background(), songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == entity.EnrichmentStatusDone || job.Status == entity.EnrichmentStatusFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("enrichment still %s after %d attempts", job.Status, job.Attempts)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEnrichKnownSong(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		api := newMockAPI(t, 0)
+		services := enrichingServices(t, backend, api, 3)
+
+		songID, err := services.Song.CreateSong(ctx, "Muse", "Supermassive Black Hole")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job := waitForJob(t, services, songID)
+		if job.Status != entity.EnrichmentStatusDone || job.Attempts != 1 {
+			t.Fatalf("got job %s after %d attempts, want it done at once", job.Status, job.Attempts)
+		}
+
+		song, err := backend.GetSongByID(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.EnrichmentStatus != entity.EnrichmentStatusDone || song.ReleaseDate == nil ||
+			song.ReleaseDate.Format("2006-01-02") != "2006-07-16" || song.Link != "https://www.youtube.com/watch?v=Xsp3_a-PMTw" {
+			t.Errorf("got song %+v, want it filled in from the fixture", song)
+		}
+		verses, err := backend.CountLyricsVerses(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verses != 4 {
+			t.Errorf("got %d lines of lyrics, want 4", verses)
+		}
+	})
+}
+
+func TestEnrichUnknownSong(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		api := newMockAPI(t, 0)
+		services := enrichingServices(t, backend, api, 3)
+
+		songID, err := services.Song.CreateSong(context.Background(), "Unknown group", "Unknown song")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a song the api does not know is not retried
+		job := waitForJob(t, services, songID)
+		if job.Status != entity.EnrichmentStatusFailed || job.Attempts != 1 {
+			t.Errorf("got job %s after %d attempts, want it failed at once", job.Status, job.Attempts)
+		}
+		if job.LastError == nil || !strings.Contains(*job.LastError, "not found") {
+			t.Errorf("got last error %v, want the song not found", job.LastError)
+		}
+		if got, want := api.served(), []int{http.StatusNotFound}; !slices.Equal(got, want) {
+			t.Errorf("got responses %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEnrichRetries(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		api := newMockAPI(t, 2)
+		services := enrichingServices(t, backend, api, 3)
+
+		songID, err := services.Song.CreateSong(context.Background(), "Muse", "Supermassive Black Hole")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job := waitForJob(t, services, songID)
+		if job.Status != entity.EnrichmentStatusDone || job.Attempts != 3 {
+			t.Errorf("got job %s after %d attempts, want it done by the third", job.Status, job.Attempts)
+		}
+		want := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+		if got := api.served(); !slices.Equal(got, want) {
+			t.Errorf("got responses %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEnrichGivesUp(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		api := newMockAPI(t, 3)
+		services := enrichingServices(t, backend, api, 3)
+
+		songID, err := services.Song.CreateSong(context.Background(), "Muse", "Supermassive Black Hole")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job := waitForJob(t, services, songID)
+		if job.Status != entity.EnrichmentStatusFailed || job.Attempts != 3 {
+			t.Errorf("got job %s after %d attempts, want it failed after the third", job.Status, job.Attempts)
+		}
+		if job.LastError == nil || !strings.Contains(*job.LastError, "unavailable") {
+			t.Errorf("got last error %v, want the api unavailable", job.LastError)
+		}
+	})
+}
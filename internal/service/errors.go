@@ -3,6 +3,44 @@ package service
 import "errors"
 
 var (
-	ErrSongAlreadyExists = errors.New("song already exists")
-	ErrSongNotFound      = errors.New("song not found")
+	ErrSongAlreadyExists  = errors.New("song already exists")
+	ErrSongNotFound       = errors.New("song not found")
+	ErrInvalidReleaseDate = errors.New("invalid release date")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrVerseNotFound      = errors.New("verse not found")
+	ErrInvalidVerse       = errors.New("invalid verse")
+	ErrStanzaNotFound     = errors.New("stanza not found")
+	ErrInvalidStanzaLabel = errors.New("invalid stanza label")
+
+	ErrSongDetailNotFound     = errors.New("song metadata not found")
+	ErrExternalAPIFailed      = errors.New("song metadata source failed")
+	ErrExternalAPIUnavailable = errors.New("song metadata source unavailable")
+	ErrExternalAPITimeout     = errors.New("song metadata source timed out")
+	ErrInvalidSongDetail      = errors.New("invalid song metadata")
+	ErrRefreshBatchTooLarge   = errors.New("refresh batch too large")
+	ErrInvalidSearchQuery     = errors.New("invalid search query")
+
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrGroupAlreadyExists = errors.New("group already exists")
+	ErrGroupHasSongs      = errors.New("group still has songs")
+
+	ErrAlbumNotFound      = errors.New("album not found")
+	ErrAlbumAlreadyExists = errors.New("album already exists")
+	ErrAlbumOfOtherGroup  = errors.New("album belongs to another group")
+
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagAlreadyExists = errors.New("tag already exists")
+	ErrInvalidTagName   = errors.New("tag name cannot be empty")
+
+	ErrPlaylistNotFound      = errors.New("playlist not found")
+	ErrPlaylistEntryNotFound = errors.New("playlist entry not found")
+	ErrInvalidPosition       = errors.New("invalid position")
+
+	ErrInvalidImport = errors.New("invalid import")
+
+	ErrEnrichmentJobNotFound = errors.New("enrichment job not found")
+	ErrEnrichmentNotFailed   = errors.New("enrichment has not failed")
+
+	ErrInvalidArchive  = errors.New("invalid archive")
+	ErrRestoreConflict = errors.New("restore conflict")
 )
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+)
+
+type GroupService struct {
+	groupRepo     repository.Group
+	songRepo      repository.Song
+	playlistRepo  repository.Playlist
+	dbTransaction repository.DBTransaction
+}
+
+func NewGroupService(groupRepo repository.Group, songRepo repository.Song, playlistRepo repository.Playlist, dbTransaction repository.DBTransaction) *GroupService {
+	return &GroupService{
+		groupRepo:     groupRepo,
+		songRepo:      songRepo,
+		playlistRepo:  playlistRepo,
+		dbTransaction: dbTransaction,
+	}
+}
+
+func (s *GroupService) GetGroups(ctx context.Context) ([]entity.Group, error) {
+	groups, err := s.groupRepo.GetGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (s *GroupService) GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error) {
+	group, err := s.groupRepo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrGroupNotFound
+		}
+
+		return nil, fmt.Errorf("failed to retrieve the group: %w", err)
+	}
+
+	group.Songs, err = s.songRepo.GetSongsByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve songs of the group: %w", err)
+	}
+
+	return group, nil
+}
+
+func (s *GroupService) RenameGroup(ctx context.Context, groupID, name string) error {
+	err := s.groupRepo.RenameGroup(ctx, groupID, name)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrGroupNotFound
+		} else if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return ErrGroupAlreadyExists
+		}
+
+		return fmt.Errorf("failed to rename the group: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGroup removes the group. When cascade is false the group is only
+// deleted if no songs reference it, otherwise its songs are removed as well.
+// The group is locked first, so that no song is added to it in between.
+func (s *GroupService) DeleteGroup(ctx context.Context, groupID string, cascade bool) error {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.groupRepo.LockGroup(ctx, groupID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrGroupNotFound
+			}
+			return fmt.Errorf("failed to lock the group: %w", err)
+		}
+
+		group, err := s.groupRepo.GetGroupByID(ctx, groupID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrGroupNotFound
+			}
+			return fmt.Errorf("failed to retrieve the group: %w", err)
+		}
+
+		if group.SongsCount > 0 && !cascade {
+			return ErrGroupHasSongs
+		}
+
+		if group.SongsCount > 0 {
+			songs, err := s.songRepo.GetSongsByGroupID(ctx, groupID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve songs of the group: %w", err)
+			}
+
+			for _, song := range songs {
+				err = s.playlistRepo.RemoveSongFromPlaylists(ctx, song.ID)
+				if err != nil {
+					return fmt.Errorf("failed to remove songs of the group from playlists: %w", err)
+				}
+			}
+		}
+
+		err = s.groupRepo.DeleteGroup(ctx, groupID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrGroupNotFound
+			}
+			return fmt.Errorf("failed to delete the group: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"testing"
+	"time"
+)
+
+// racingGroups calls race each time a group has been read.
+type racingGroups struct {
+	repository.Group
+	race func()
+}
+
+func (g racingGroups) GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error) {
+	group, err := g.Group.GetGroupByID(ctx, groupID)
+	g.race()
+	return group, err
+}
+
+// TestDeleteGroupConcurrentSong adds a song to the group while it is deleted
+// without cascading, once the group has been found empty: either the song is
+// kept from being added or the group from being deleted, but the song is
+// never deleted along with it.
+func TestDeleteGroupConcurrentSong(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		groupID, err := backend.CreateGroup(ctx, "Group")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		created := make(chan error, 1)
+		repo := *backend.Repository
+		repo.Group = racingGroups{Group: repo.Group, race: func() {
+			go func() {
+				_, err := backend.CreateSong(ctx, &entity.Song{Title: "Song", GroupID: groupID})
+				created <- err
+			}()
+
+			// the song waits for the group to be deleted, or is added meanwhile
+			select {
+			case err := <-created:
+				created <- err
+			case <-time.After(100 * time.Millisecond):
+			}
+		}}
+		services := service.NewService(service.Dependencies{Repository: &repo})
+
+		deleteErr := services.Group.DeleteGroup(ctx, groupID, false)
+		createErr := <-created
+
+		if deleteErr == nil && createErr == nil {
+			t.Error("deleted the song added to the group along with it")
+		}
+		if deleteErr != nil && !errors.Is(deleteErr, service.ErrGroupHasSongs) {
+			t.Errorf("got error %v deleting the group, want none or %v", deleteErr, service.ErrGroupHasSongs)
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/pkg/partialdate"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatJSON   = "json"
+	ImportFormatNDJSON = "ndjson"
+
+	defaultImportBatchSize = 100
+)
+
+// errDryRun rolls back the transaction of a dry run batch.
+var errDryRun = errors.New("dry run")
+
+type ImportService struct {
+	songService   *SongService
+	dbTransaction repository.DBTransaction
+}
+
+func NewImportService(songService *SongService, dbTransaction repository.DBTransaction) *ImportService {
+	return &ImportService{
+		songService:   songService,
+		dbTransaction: dbTransaction,
+	}
+}
+
+// importRow is a decoded row together with its 1-based position in the input
+// and the error that made it undecodable, if any.
+type importRow struct {
+	number int
+	row    entity.SongImportRow
+	err    error
+}
+
+// ImportSongs creates songs out of CSV, JSON array or NDJSON input. Rows are
+// committed in batches, each row within its own savepoint so that a duplicate
+// or broken row does not abort the rest of its batch. In dry-run mode every
+// batch is rolled back and the external API is never called.
+func (s *ImportService) ImportSongs(ctx context.Context, r io.Reader, opts entity.SongImportOptions) (*entity.SongImportReport, error) {
+	switch opts.Enrich {
+	case "":
+		opts.Enrich = entity.ImportEnrichAuto
+	case entity.ImportEnrichAuto, entity.ImportEnrichAlways, entity.ImportEnrichNever:
+	default:
+		return nil, fmt.Errorf("%w: unknown enrich mode %q", ErrInvalidImport, opts.Enrich)
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	rows, err := decodeImportRows(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.SongImportReport{
+		DryRun:  opts.DryRun,
+		Total:   len(rows),
+		Results: make([]entity.SongImportResult, 0, len(rows)),
+	}
+
+	for start := 0; start < len(rows); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		results, err := s.importBatch(ctx, rows[start:end], opts)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, results...)
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case entity.ImportStatusCreated:
+			report.Created++
+		case entity.ImportStatusDuplicate:
+			report.Duplicates++
+		case entity.ImportStatusFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+func (s *ImportService) importBatch(ctx context.Context, rows []importRow, opts entity.SongImportOptions) ([]entity.SongImportResult, error) {
+	results := make([]entity.SongImportResult, len(rows))
+	songs := make([]*entity.Song, len(rows))
+	lyrics := make([]string, len(rows))
+
+	// preparing rows before the transaction, so that no transaction stays open while the external api is called
+	for ind, row := range rows {
+		results[ind] = entity.SongImportResult{
+			Row:   row.number,
+			Group: row.row.Group,
+			Title: row.row.Title,
+		}
+
+		song, songLyrics, err := s.prepareRow(ctx, row, opts)
+		if err != nil {
+			results[ind].Status = entity.ImportStatusFailed
+			results[ind].Error = err.Error()
+			continue
+		}
+		songs[ind], lyrics[ind] = song, songLyrics
+	}
+
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		for ind, song := range songs {
+			if song == nil {
+				continue
+			}
+
+			// every row is created in a savepoint, so that a failing row does not abort the batch
+			var songID string
+			createErr := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+				var err error
+				songID, err = s.songService.createSong(ctx, song, lyrics[ind])
+				return err
+			})
+			if createErr != nil {
+				results[ind].Status = entity.ImportStatusFailed
+				if errors.Is(createErr, ErrSongAlreadyExists) {
+					results[ind].Status = entity.ImportStatusDuplicate
+				}
+				results[ind].Error = createErr.Error()
+				continue
+			}
+
+			results[ind].Status = entity.ImportStatusCreated
+			results[ind].SongID = songID
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		for ind := range results {
+			if results[ind].Status == entity.ImportStatusCreated || results[ind].Status == "" {
+				results[ind].Status = entity.ImportStatusFailed
+				results[ind].SongID = ""
+				results[ind].Error = fmt.Sprintf("failed to store batch: %s", err)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// prepareRow validates the row and merges it with the external API details
+// according to the enrich mode. Supplied values take precedence.
+func (s *ImportService) prepareRow(ctx context.Context, row importRow, opts entity.SongImportOptions) (*entity.Song, string, error) {
+	if row.err != nil {
+		return nil, "", row.err
+	}
+
+	data := row.row
+	data.Group = strings.TrimSpace(data.Group)
+	data.Title = strings.TrimSpace(data.Title)
+	if data.Group == "" || data.Title == "" {
+		return nil, "", errors.New("group and title are required")
+	}
+
+	supplied := data.ReleaseDate != "" || data.Link != "" || data.Lyrics != ""
+	enrich := opts.Enrich == entity.ImportEnrichAlways || (opts.Enrich == entity.ImportEnrichAuto && !supplied)
+	if enrich && !opts.DryRun {
+		detail, err := s.songService.lookupSongDetail(ctx, data.Group, data.Title)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to look up song metadata: %w", err)
+		}
+
+		if data.ReleaseDate == "" {
+			data.ReleaseDate = detail.ReleaseDate
+		}
+		if data.Link == "" {
+			data.Link = detail.Link
+		}
+		if data.Lyrics == "" {
+			data.Lyrics = detail.Text
+		}
+	}
+
+	song := &entity.Song{
+		Title:     data.Title,
+		GroupName: data.Group,
+		Link:      data.Link,
+	}
+
+	if strings.TrimSpace(data.ReleaseDate) != "" {
+		releaseDate, err := partialdate.Parse(data.ReleaseDate)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid release date %q", data.ReleaseDate)
+		}
+		song.ReleaseDate = &releaseDate.Time
+		song.ReleaseDatePrecision = string(releaseDate.Precision)
+	}
+
+	return song, data.Lyrics, nil
+}
+
+// DetectImportFormat guesses the import format from a file name extension or,
+// failing that, from a media type. It returns an empty string if neither helps.
+func DetectImportFormat(filename, contentType string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".json":
+		return ImportFormatJSON
+	case ".ndjson", ".jsonl":
+		return ImportFormatNDJSON
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return ImportFormatCSV
+	case "application/json":
+		return ImportFormatJSON
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return ImportFormatNDJSON
+	}
+
+	return ""
+}
+
+func decodeImportRows(r io.Reader, format string) ([]importRow, error) {
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return decodeCSVRows(r)
+	case ImportFormatJSON:
+		return decodeJSONRows(r)
+	case ImportFormatNDJSON, "jsonl":
+		return decodeNDJSONRows(r)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+}
+
+// decodeCSVRows reads CSV with a header row naming the group, title,
+// releaseDate, link and lyrics columns in any order.
+func decodeCSVRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: failed to read csv header: %s", ErrInvalidImport, err)
+	}
+
+	columns := make(map[string]int)
+	for ind, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = ind
+	}
+	if _, ok := columns["group"]; !ok {
+		return nil, fmt.Errorf("%w: csv header has no group column", ErrInvalidImport)
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: csv header has no title column", ErrInvalidImport)
+	}
+
+	field := func(record []string, name string) string {
+		ind, ok := columns[strings.ToLower(name)]
+		if !ok || ind >= len(record) {
+			return ""
+		}
+		return record[ind]
+	}
+
+	var rows []importRow
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, importRow{number: number, err: fmt.Errorf("invalid csv record: %w", err)})
+				continue
+			}
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		rows = append(rows, importRow{
+			number: number,
+			row: entity.SongImportRow{
+				Group:       field(record, "group"),
+				Title:       field(record, "title"),
+				ReleaseDate: field(record, "releaseDate"),
+				Link:        field(record, "link"),
+				Lyrics:      field(record, "lyrics"),
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+func decodeJSONRows(r io.Reader) ([]importRow, error) {
+	var records []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: expected a json array of songs: %s", ErrInvalidImport, err)
+	}
+
+	rows := make([]importRow, 0, len(records))
+	for ind, record := range records {
+		row := importRow{number: ind + 1}
+		if err := json.Unmarshal(record, &row.row); err != nil {
+			row.err = fmt.Errorf("invalid json record: %w", err)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func decodeNDJSONRows(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var rows []importRow
+	number := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		number++
+		row := importRow{number: number}
+		if err := json.Unmarshal([]byte(line), &row.row); err != nil {
+			row.err = fmt.Errorf("invalid json record: %w", err)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ndjson: %w", err)
+	}
+
+	return rows, nil
+}
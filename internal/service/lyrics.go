@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxStanzaLabelLength is the length of the label column of lyrics_stanzas.
+const maxStanzaLabelLength = 64
+
+func (s *SongService) GetLyricsVerse(ctx context.Context, songID string, verseNumber int) (*entity.LyricsVerse, error) {
+	if err := s.requireSong(ctx, songID); err != nil {
+		return nil, err
+	}
+
+	verse, err := s.lyricsRepo.GetLyricsVerse(ctx, songID, verseNumber)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrVerseNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve the verse: %w", err)
+	}
+
+	return verse, nil
+}
+
+func (s *SongService) UpdateLyricsVerse(ctx context.Context, songID string, verseNumber int, verse string) error {
+	if err := validateVerse(verse); err != nil {
+		return err
+	}
+
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requireSong(ctx, songID); err != nil {
+			return err
+		}
+
+		err := s.lyricsRepo.UpdateLyricsVerse(ctx, songID, verseNumber, verse)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrVerseNotFound
+			}
+			return fmt.Errorf("failed to update the verse: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// InsertLyricsVerse appends the verse to the lyrics, or inserts it at
+// verse.VerseNumber when one is given, and returns the number of the verse.
+func (s *SongService) InsertLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) (int, error) {
+	if err := validateVerse(verse.Verse); err != nil {
+		return 0, err
+	}
+
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requireSong(ctx, verse.SongID); err != nil {
+			return err
+		}
+
+		count, err := s.lyricsRepo.CountLyricsVerses(ctx, verse.SongID)
+		if err != nil {
+			return fmt.Errorf("failed to count the verses: %w", err)
+		}
+
+		if verse.VerseNumber == 0 {
+			verse.VerseNumber = count + 1
+		} else if verse.VerseNumber < 1 || verse.VerseNumber > count+1 {
+			return ErrInvalidPosition
+		}
+
+		if count == 0 {
+			// the first line of the lyrics opens their first stanza
+			verse.StanzaNumber = 1
+			err = s.lyricsRepo.AddLyricsStanza(ctx, &entity.LyricsStanza{
+				SongID:       verse.SongID,
+				StanzaNumber: verse.StanzaNumber,
+				Verses:       []entity.LyricsVerse{*verse},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert the verse: %w", err)
+			}
+			return nil
+		}
+
+		// the line joins the stanza of the line it takes the place of, or of
+		// the last line when appended
+		neighbour, err := s.lyricsRepo.GetLyricsVerse(ctx, verse.SongID, min(verse.VerseNumber, count))
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the verse: %w", err)
+		}
+		verse.StanzaNumber = neighbour.StanzaNumber
+
+		err = s.lyricsRepo.InsertLyricsVerse(ctx, verse)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			return fmt.Errorf("failed to insert the verse: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return verse.VerseNumber, nil
+}
+
+// MoveLyricsVerse moves the verse to position, shifting the verses in
+// between. The verse joins the stanza of the verse whose place it takes.
+func (s *SongService) MoveLyricsVerse(ctx context.Context, songID string, verseNumber, position int) error {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requireSong(ctx, songID); err != nil {
+			return err
+		}
+
+		count, err := s.lyricsRepo.CountLyricsVerses(ctx, songID)
+		if err != nil {
+			return fmt.Errorf("failed to count the verses: %w", err)
+		}
+
+		if verseNumber < 1 || verseNumber > count {
+			return ErrVerseNotFound
+		}
+		if position < 1 || position > count {
+			return ErrInvalidPosition
+		}
+
+		target, err := s.lyricsRepo.GetLyricsVerse(ctx, songID, position)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the verse: %w", err)
+		}
+
+		err = s.lyricsRepo.MoveLyricsVerse(ctx, songID, verseNumber, position, target.StanzaNumber)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrVerseNotFound
+			}
+			return fmt.Errorf("failed to move the verse: %w", err)
+		}
+
+		return s.deleteEmptyStanzas(ctx, songID)
+	})
+}
+
+// DeleteLyricsVerse deletes the verse, renumbering the verses after it, and
+// its stanza when it was the last verse of it.
+func (s *SongService) DeleteLyricsVerse(ctx context.Context, songID string, verseNumber int) error {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requireSong(ctx, songID); err != nil {
+			return err
+		}
+
+		err := s.lyricsRepo.DeleteLyricsVerse(ctx, songID, verseNumber)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrVerseNotFound
+			}
+			return fmt.Errorf("failed to delete the verse: %w", err)
+		}
+
+		return s.deleteEmptyStanzas(ctx, songID)
+	})
+}
+
+// UpdateLyricsStanza sets the label of the stanza, or removes it when label
+// is nil or blank.
+func (s *SongService) UpdateLyricsStanza(ctx context.Context, songID string, stanzaNumber int, label *string) error {
+	if label != nil {
+		trimmed := strings.TrimSpace(*label)
+		if err := validateStanzaLabel(trimmed); err != nil {
+			return err
+		}
+		label = &trimmed
+		if trimmed == "" {
+			label = nil
+		}
+	}
+
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.requireSong(ctx, songID); err != nil {
+			return err
+		}
+
+		err := s.lyricsRepo.UpdateLyricsStanza(ctx, songID, stanzaNumber, label)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrStanzaNotFound
+			}
+			return fmt.Errorf("failed to update the stanza: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// deleteEmptyStanzas drops the stanza a verse has left, if it has no verse
+// any more.
+func (s *SongService) deleteEmptyStanzas(ctx context.Context, songID string) error {
+	if err := s.lyricsRepo.DeleteEmptyLyricsStanzas(ctx, songID); err != nil {
+		return fmt.Errorf("failed to delete empty stanzas: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SongService) requireSong(ctx context.Context, songID string) error {
+	_, err := s.songRepo.GetSongByID(ctx, songID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrSongNotFound
+		}
+		return fmt.Errorf("failed to retrieve the song: %w", err)
+	}
+
+	return nil
+}
+
+// validateVerse rejects verses spanning several lines, as the lyrics of a
+// song hold one verse a line.
+func validateVerse(verse string) error {
+	if strings.ContainsAny(verse, "\r\n") {
+		return fmt.Errorf("%w: a verse must be a single line", ErrInvalidVerse)
+	}
+
+	return nil
+}
+
+// validateStanzaLabel rejects labels that would not read back as the label
+// line of the stanza in the text of the lyrics.
+func validateStanzaLabel(label string) error {
+	if strings.ContainsAny(label, "[]\r\n") {
+		return fmt.Errorf("%w: a label must be a single line without brackets", ErrInvalidStanzaLabel)
+	}
+	if utf8.RuneCountInString(label) > maxStanzaLabelLength {
+		return fmt.Errorf("%w: a label is at most %d characters long", ErrInvalidStanzaLabel, maxStanzaLabelLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/metadata"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/pkg/lru"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type MetadataCacheConfig struct {
+	Enabled bool
+	// Size is the number of lookups kept in memory.
+	Size int
+	// TTL is how long a found song detail is kept, NegativeTTL how long a
+	// song the provider did not know is. A zero NegativeTTL disables
+	// negative caching; a zero TTL keeps details until purged.
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	// Persistent keeps lookups in the database too, so that they survive
+	// restarts and are shared between instances.
+	Persistent bool
+}
+
+// MetadataCacheService sits in front of the metadata provider. Lookups are
+// served from the in-memory LRU, then from the database when the cache is
+// persistent, and only then from the provider. Failures of the provider other
+// than not knowing the song are never cached.
+type MetadataCacheService struct {
+	provider      MetadataProvider
+	cacheRepo     repository.SongDetailCache
+	dbTransaction repository.DBTransaction
+	cfg           MetadataCacheConfig
+	entries       *lru.Cache[string, entity.SongDetailCacheEntry]
+
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	storedHits   atomic.Int64
+	misses       atomic.Int64
+}
+
+func NewMetadataCacheService(provider MetadataProvider, cacheRepo repository.SongDetailCache, dbTransaction repository.DBTransaction, cfg MetadataCacheConfig) *MetadataCacheService {
+	s := &MetadataCacheService{
+		provider:      provider,
+		cacheRepo:     cacheRepo,
+		dbTransaction: dbTransaction,
+		cfg:           cfg,
+	}
+	if cfg.Enabled {
+		s.entries = lru.New[string, entity.SongDetailCacheEntry](cfg.Size)
+	}
+
+	return s
+}
+
+func (s *MetadataCacheService) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	if !s.cfg.Enabled {
+		return s.provider.GetSongDetail(ctx, groupName, title)
+	}
+
+	key := metadata.Key(groupName, title)
+	if entry, ok := s.entries.Get(key); ok {
+		return s.hit(&entry)
+	}
+
+	if s.cfg.Persistent {
+		entry, err := s.getStored(ctx, key)
+		if err == nil {
+			s.storedHits.Add(1)
+			s.entries.Add(key, *entry, s.remaining(entry))
+			return s.hit(entry)
+		}
+		if !errors.Is(err, repoerrors.ErrNotFound) {
+			log.Warnf("failed to read the song metadata cache: %s", err)
+		}
+	}
+
+	s.misses.Add(1)
+	return s.fetch(ctx, key, groupName, title)
+}
+
+// Refresh looks the song up in the provider, bypassing the cache, and
+// replaces the cached lookup with the answer. A song the provider no longer
+// knows is dropped from the cache unless negative caching is on; a failing
+// provider leaves the cache as it is.
+func (s *MetadataCacheService) Refresh(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	if !s.cfg.Enabled {
+		return s.provider.GetSongDetail(ctx, groupName, title)
+	}
+
+	key := metadata.Key(groupName, title)
+	songDetail, err := s.fetch(ctx, key, groupName, title)
+	if errors.Is(err, metadata.ErrNotFound) && s.cfg.NegativeTTL <= 0 {
+		s.drop(ctx, key)
+	}
+
+	return songDetail, err
+}
+
+// fetch asks the provider and caches what it knows about the song.
+func (s *MetadataCacheService) fetch(ctx context.Context, key, groupName, title string) (*entity.SongDetail, error) {
+	songDetail, err := s.provider.GetSongDetail(ctx, groupName, title)
+	switch {
+	case err == nil:
+		s.store(ctx, &entity.SongDetailCacheEntry{
+			Key:       key,
+			GroupName: strings.TrimSpace(groupName),
+			Title:     strings.TrimSpace(title),
+			Found:     true,
+			Detail:    *songDetail,
+		}, s.cfg.TTL)
+		return songDetail, nil
+	case errors.Is(err, metadata.ErrNotFound) && s.cfg.NegativeTTL > 0:
+		s.store(ctx, &entity.SongDetailCacheEntry{
+			Key:       key,
+			GroupName: strings.TrimSpace(groupName),
+			Title:     strings.TrimSpace(title),
+		}, s.cfg.NegativeTTL)
+	}
+
+	return nil, err
+}
+
+func (s *MetadataCacheService) hit(entry *entity.SongDetailCacheEntry) (*entity.SongDetail, error) {
+	if !entry.Found {
+		s.negativeHits.Add(1)
+		return nil, metadata.ErrNotFound
+	}
+
+	s.hits.Add(1)
+	songDetail := entry.Detail
+	return &songDetail, nil
+}
+
+// remaining returns how long an entry read from the database has left, so
+// that the copy kept in memory expires along with it.
+func (s *MetadataCacheService) remaining(entry *entity.SongDetailCacheEntry) time.Duration {
+	if entry.ExpiresAt == nil {
+		return 0
+	}
+
+	return max(time.Until(*entry.ExpiresAt), time.Millisecond)
+}
+
+// getStored, store and drop run in a transaction of their own, a savepoint when
+// the caller has one, so that a failing cache never aborts the caller's work.
+func (s *MetadataCacheService) getStored(ctx context.Context, key string) (*entity.SongDetailCacheEntry, error) {
+	var entry *entity.SongDetailCacheEntry
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		entry, err = s.cacheRepo.GetSongDetailCache(ctx, key)
+		return err
+	})
+
+	return entry, err
+}
+
+func (s *MetadataCacheService) store(ctx context.Context, entry *entity.SongDetailCacheEntry, ttl time.Duration) {
+	s.entries.Add(entry.Key, *entry, ttl)
+	if !s.cfg.Persistent {
+		return
+	}
+
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.cacheRepo.SetSongDetailCache(ctx, entry, ttl)
+	})
+	if err != nil {
+		log.Warnf("failed to write the song metadata cache: %s", err)
+	}
+}
+
+func (s *MetadataCacheService) drop(ctx context.Context, key string) {
+	s.entries.Remove(key)
+	if !s.cfg.Persistent {
+		return
+	}
+
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.cacheRepo.DeleteSongDetailCache(ctx, key)
+		return err
+	})
+	if err != nil {
+		log.Warnf("failed to write the song metadata cache: %s", err)
+	}
+}
+
+func (s *MetadataCacheService) GetMetadataCacheStats(ctx context.Context) (*entity.MetadataCacheStats, error) {
+	stats := &entity.MetadataCacheStats{
+		Enabled:      s.cfg.Enabled,
+		Persistent:   s.cfg.Enabled && s.cfg.Persistent,
+		Hits:         s.hits.Load(),
+		NegativeHits: s.negativeHits.Load(),
+		StoredHits:   s.storedHits.Load(),
+		Misses:       s.misses.Load(),
+	}
+	if !s.cfg.Enabled {
+		return stats, nil
+	}
+
+	stats.Capacity = s.entries.Capacity()
+	stats.Entries = s.entries.Len()
+	stats.Evictions = s.entries.Evictions()
+	if s.cfg.Persistent {
+		count, err := s.cacheRepo.CountSongDetailCache(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count cached song metadata: %w", err)
+		}
+		stats.StoredEntries = count
+	}
+
+	return stats, nil
+}
+
+// PurgeMetadataCache drops the cached lookup of the song, or every cached
+// lookup when both groupName and title are empty.
+func (s *MetadataCacheService) PurgeMetadataCache(ctx context.Context, groupName, title string) (*entity.MetadataCachePurge, error) {
+	purge := &entity.MetadataCachePurge{}
+	if !s.cfg.Enabled {
+		return purge, nil
+	}
+
+	var key string
+	if groupName != "" || title != "" {
+		key = metadata.Key(groupName, title)
+		if s.entries.Remove(key) {
+			purge.Entries = 1
+		}
+	} else {
+		purge.Entries = s.entries.Purge()
+	}
+
+	if s.cfg.Persistent {
+		deleted, err := s.cacheRepo.DeleteSongDetailCache(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge cached song metadata: %w", err)
+		}
+		purge.StoredEntries = deleted
+	}
+
+	return purge, nil
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/metadata"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"testing"
+	"time"
+)
+
+// knownProvider knows the songs of its titles only.
+type knownProvider map[string]bool
+
+func (p knownProvider) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	if !p[title] {
+		return nil, metadata.ErrNotFound
+	}
+
+	return &entity.SongDetail{Link: "https://example.com/" + title}, nil
+}
+
+// TestMetadataCacheExpiry keeps found songs and unknown ones for their own
+// TTLs, counting the hits, negative hits, misses and evictions.
+func TestMetadataCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := service.NewMetadataCacheService(knownProvider{"Known": true, "Other": true}, nil, nil, service.MetadataCacheConfig{
+		Enabled:     true,
+		Size:        2,
+		TTL:         time.Hour,
+		NegativeTTL: 20 * time.Millisecond,
+	})
+	lookup := func(title string) error {
+		t.Helper()
+
+		_, err := cache.GetSongDetail(ctx, "Group", title)
+		if err != nil && !errors.Is(err, metadata.ErrNotFound) {
+			t.Fatal(err)
+		}
+		return err
+	}
+	assertStats := func(want entity.MetadataCacheStats) {
+		t.Helper()
+
+		stats, err := cache.GetMetadataCacheStats(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.Enabled, want.Capacity = true, 2
+		if *stats != want {
+			t.Errorf("got stats %+v, want %+v", *stats, want)
+		}
+	}
+
+	for ind := 0; ind < 2; ind++ {
+		if err := lookup("Known"); err != nil {
+			t.Errorf("got error %v looking a known song up", err)
+		}
+		if err := lookup("Unknown"); !errors.Is(err, metadata.ErrNotFound) {
+			t.Errorf("got error %v looking an unknown song up, want %v", err, metadata.ErrNotFound)
+		}
+	}
+	assertStats(entity.MetadataCacheStats{Entries: 2, Hits: 1, NegativeHits: 1, Misses: 2})
+
+	// the unknown song expires, and the known one does not
+	time.Sleep(40 * time.Millisecond)
+	lookup("Known")
+	lookup("Unknown")
+	assertStats(entity.MetadataCacheStats{Entries: 2, Hits: 2, NegativeHits: 1, Misses: 3})
+
+	// the known song, the least recently used, makes room for another one
+	lookup("Other")
+	lookup("Known")
+	assertStats(entity.MetadataCacheStats{Entries: 2, Hits: 2, NegativeHits: 1, Misses: 5, Evictions: 2})
+}
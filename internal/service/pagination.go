@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"effective_mobile_tz/pkg/cursor"
+	"errors"
+	"fmt"
+)
+
+// ListSongs returns a page of the songs matching the filter along with the
+// number of them, both read from the same snapshot. The page follows the
+// after cursor, precedes the before cursor, or is taken at the offset of the
+// filter when neither is given. Cursors keep pages from skipping or repeating
+// songs while songs are added or removed between them.
+func (s *SongService) ListSongs(ctx context.Context, filter *entity.SongFilter, after, before string) (*entity.SongList, error) {
+	if len(filter.Sort) == 0 {
+		filter.Sort = entity.DefaultSongSort
+	}
+	sort := entity.FormatSongSort(filter.Sort)
+
+	var err error
+	if filter.After, err = s.decodeSongCursor(after, sort); err != nil {
+		return nil, err
+	}
+	if filter.Before, err = s.decodeSongCursor(before, sort); err != nil {
+		return nil, err
+	}
+	if filter.After != nil && filter.Before != nil {
+		return nil, fmt.Errorf("%w: either after or before can be given, not both", ErrInvalidCursor)
+	}
+
+	limit := filter.Limit
+	list := &entity.SongList{Limit: limit}
+	if limit > 0 && after == "" && before == "" {
+		list.Page = filter.Offset/limit + 1
+	}
+	// one more song tells whether there is another page
+	if limit > 0 {
+		filter.Limit = limit + 1
+	}
+
+	err = s.dbTransaction.WithinSnapshot(ctx, func(ctx context.Context) error {
+		var err error
+		list.Items, err = s.GetSongsByFilter(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		list.Total, err = s.songRepo.CountSongsByFilter(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to count songs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		return list, nil
+	}
+
+	more := len(list.Items) > limit
+	backward := filter.Before != nil
+	if more && backward {
+		list.Items = list.Items[1:]
+	} else if more {
+		list.Items = list.Items[:limit]
+	}
+
+	var next, prev *entity.SongCursor
+	if len(list.Items) > 0 {
+		first, last := songCursor(&list.Items[0], filter.Sort), songCursor(&list.Items[len(list.Items)-1], filter.Sort)
+		if more || backward {
+			next = last
+		}
+		if more && backward || !backward && (filter.After != nil || filter.Offset > 0) {
+			prev = first
+		}
+	}
+
+	if list.NextCursor, err = encodeCursor(s.cursors, next); err != nil {
+		return nil, err
+	}
+	if list.PrevCursor, err = encodeCursor(s.cursors, prev); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetLyricsPage returns up to limit verses of the song following the after
+// cursor, preceding the before cursor, or from the first verse when neither
+// is given. Cursors hold the line rather than its number, so that pages keep
+// from skipping or repeating lines while lines are inserted or deleted
+// between them. A cursor whose line has been deleted is invalid.
+func (s *SongService) GetLyricsPage(ctx context.Context, songID, after, before string, limit int) (*entity.LyricsPage, error) {
+	var afterVerse, beforeVerse *entity.VerseCursor
+	var err error
+	if afterVerse, err = s.decodeVerseCursor(after, songID, false); err != nil {
+		return nil, err
+	}
+	if beforeVerse, err = s.decodeVerseCursor(before, songID, false); err != nil {
+		return nil, err
+	}
+	if afterVerse != nil && beforeVerse != nil {
+		return nil, fmt.Errorf("%w: either after or before can be given, not both", ErrInvalidCursor)
+	}
+
+	page := &entity.LyricsPage{}
+	err = s.dbTransaction.WithinSnapshot(ctx, func(ctx context.Context) error {
+		position := afterVerse
+		if beforeVerse != nil {
+			position = beforeVerse
+		}
+
+		// the line of the cursor tells where the page starts now
+		var verseNumber int
+		if position != nil {
+			verse, err := s.lyricsRepo.GetLyricsVerseByID(ctx, songID, position.VerseID)
+			if err != nil {
+				if errors.Is(err, repoerrors.ErrNotFound) {
+					return fmt.Errorf("%w: the line of the cursor has been deleted", ErrInvalidCursor)
+				}
+				return fmt.Errorf("failed to retrieve the verse of the cursor: %w", err)
+			}
+			verseNumber = verse.VerseNumber
+		}
+
+		// one more verse tells whether there is another page
+		var err error
+		if beforeVerse != nil {
+			page.Verses, err = s.lyricsRepo.GetLyricsBefore(ctx, songID, verseNumber, limit+1)
+		} else {
+			page.Verses, err = s.lyricsRepo.GetLyricsAfter(ctx, songID, verseNumber, limit+1)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(page.Verses) > limit
+	backward := beforeVerse != nil
+	if more && backward {
+		page.Verses = page.Verses[1:]
+	} else if more {
+		page.Verses = page.Verses[:limit]
+	}
+
+	var next, prev *entity.VerseCursor
+	if len(page.Verses) > 0 {
+		first := &entity.VerseCursor{SongID: songID, VerseID: page.Verses[0].ID}
+		last := &entity.VerseCursor{SongID: songID, VerseID: page.Verses[len(page.Verses)-1].ID}
+		if more || backward {
+			next = last
+		}
+		if more && backward || !backward && afterVerse != nil {
+			prev = first
+		}
+	}
+
+	if page.NextCursor, err = encodeCursor(s.cursors, next); err != nil {
+		return nil, err
+	}
+	if page.PrevCursor, err = encodeCursor(s.cursors, prev); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetStanzaPage returns up to limit stanzas of the song following the after
+// cursor, preceding the before cursor, or from the first stanza when neither
+// is given.
+func (s *SongService) GetStanzaPage(ctx context.Context, songID, after, before string, limit int) (*entity.LyricsPage, error) {
+	var afterStanza, beforeStanza *entity.VerseCursor
+	var err error
+	if afterStanza, err = s.decodeVerseCursor(after, songID, true); err != nil {
+		return nil, err
+	}
+	if beforeStanza, err = s.decodeVerseCursor(before, songID, true); err != nil {
+		return nil, err
+	}
+	if afterStanza != nil && beforeStanza != nil {
+		return nil, fmt.Errorf("%w: either after or before can be given, not both", ErrInvalidCursor)
+	}
+
+	// stanzas are numbered without gaps, so a page is a range of numbers, and
+	// one more stanza tells whether there is another page
+	first, last := 1, limit+1
+	if afterStanza != nil {
+		first = afterStanza.StanzaNumber + 1
+		last = first + limit
+	} else if beforeStanza != nil {
+		last = beforeStanza.StanzaNumber - 1
+		first = max(last-limit, 1)
+	}
+
+	page := &entity.LyricsPage{}
+	page.Stanzas, err = s.lyricsRepo.GetLyricsStanzas(ctx, songID, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(page.Stanzas) > limit
+	backward := beforeStanza != nil
+	if more && backward {
+		page.Stanzas = page.Stanzas[1:]
+	} else if more {
+		page.Stanzas = page.Stanzas[:limit]
+	}
+
+	var next, prev *entity.VerseCursor
+	if len(page.Stanzas) > 0 {
+		first := &entity.VerseCursor{SongID: songID, StanzaNumber: page.Stanzas[0].StanzaNumber}
+		last := &entity.VerseCursor{SongID: songID, StanzaNumber: page.Stanzas[len(page.Stanzas)-1].StanzaNumber}
+		if more || backward {
+			next = last
+		}
+		if more && backward || !backward && afterStanza != nil {
+			prev = first
+		}
+	}
+
+	if page.NextCursor, err = encodeCursor(s.cursors, next); err != nil {
+		return nil, err
+	}
+	if page.PrevCursor, err = encodeCursor(s.cursors, prev); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// songCursor returns the position of the song in the listing sorted by sort.
+func songCursor(song *entity.Song, sort []entity.SongSort) *entity.SongCursor {
+	position := &entity.SongCursor{Sort: entity.FormatSongSort(sort), ID: song.ID}
+	for _, key := range sort {
+		switch key.Field {
+		case entity.SongSortTitle:
+			position.Title = song.Title
+		case entity.SongSortGroup:
+			position.GroupName = song.GroupName
+		case entity.SongSortReleaseDate:
+			position.ReleaseDate = song.ReleaseDate
+		case entity.SongSortCreatedAt:
+			position.CreatedAt = song.CreatedAt
+		}
+	}
+
+	return position
+}
+
+// decodeSongCursor reads the token, which must hold a position in the listing
+// sorted by sort. An empty token is no cursor.
+func (s *SongService) decodeSongCursor(token, sort string) (*entity.SongCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	var position entity.SongCursor
+	if err := s.cursors.Decode(token, &position); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if position.Sort != sort {
+		return nil, fmt.Errorf("%w: the cursor belongs to the listing sorted by %s", ErrInvalidCursor, position.Sort)
+	}
+
+	return &position, nil
+}
+
+// decodeVerseCursor reads the token, which must hold a position in the
+// lyrics of the song paginated by stanza, or by line when byStanza is false.
+// An empty token is no cursor.
+func (s *SongService) decodeVerseCursor(token, songID string, byStanza bool) (*entity.VerseCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	var position entity.VerseCursor
+	if err := s.cursors.Decode(token, &position); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if position.SongID != songID {
+		return nil, fmt.Errorf("%w: the cursor belongs to another song", ErrInvalidCursor)
+	}
+	if byStanza && position.StanzaNumber < 1 {
+		return nil, fmt.Errorf("%w: the cursor belongs to the lyrics paginated by line", ErrInvalidCursor)
+	}
+	if !byStanza && position.VerseID == "" {
+		return nil, fmt.Errorf("%w: the cursor belongs to the lyrics paginated by stanza", ErrInvalidCursor)
+	}
+
+	return &position, nil
+}
+
+// encodeCursor returns the token of the position, or an empty one for nil.
+func encodeCursor[T any](cursors *cursor.Codec, position *T) (string, error) {
+	if position == nil {
+		return "", nil
+	}
+
+	token, err := cursors.Encode(position)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the cursor: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,297 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// maxInserts bounds the rows added while the pages are walked, so that the
+// walk reaches the end.
+const maxInserts = 30
+
+// maxPages stops a walk that a faulty cursor sends round in circles.
+const maxPages = 100
+
+// TestListSongsConcurrentInserts walks the song listing forward and backward
+// page by page while songs are added, with release dates and NULL ones
+// interleaved in either order of them. Run it with -race.
+func TestListSongsConcurrentInserts(t *testing.T) {
+	for _, sort := range [][]entity.SongSort{
+		{{Field: entity.SongSortReleaseDate}},
+		{{Field: entity.SongSortReleaseDate, Desc: true}, {Field: entity.SongSortTitle}},
+	} {
+		t.Run(entity.FormatSongSort(sort), func(t *testing.T) {
+			repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+				ctx := context.Background()
+				services := service.NewService(service.Dependencies{Repository: backend.Repository, CursorSecret: "secret"})
+				groupID, err := backend.CreateGroup(ctx, "Group")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var songIDs []string
+				for ind := 0; ind < 9; ind++ {
+					songIDs = append(songIDs, createDatedSong(t, backend, groupID, fmt.Sprintf("Song %d", ind), ind))
+				}
+
+				stop := insertConcurrently(t, func(ind int) {
+					createDatedSong(t, backend, groupID, fmt.Sprintf("Added %d", ind), ind*7)
+				})
+				forward, backward := walkSongs(t, services, sort)
+				stop()
+
+				list, err := services.Song.ListSongs(ctx, &entity.SongFilter{Sort: sort}, "", "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var listed []string
+				for _, song := range list.Items {
+					listed = append(listed, song.ID)
+				}
+
+				for name, walked := range map[string][]string{"forward": forward, "backward": backward} {
+					if !isSubsequence(walked, listed) {
+						t.Errorf("walking %s got songs %q, want them once each in the order of %q", name, walked, listed)
+					}
+					for _, songID := range songIDs {
+						if !slices.Contains(walked, songID) {
+							t.Errorf("walking %s skipped song %s", name, songID)
+						}
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestGetLyricsPageConcurrentInserts walks the lyrics of a song forward and
+// backward page by page while stanzas are appended to them.
+func TestGetLyricsPageConcurrentInserts(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		services := service.NewService(service.Dependencies{Repository: backend.Repository, CursorSecret: "secret"})
+		songID := importSong(t, backend, `{"group": "Group", "title": "Song", "lyrics": "1\n2\n\n3\n4\n\n5\n6\n\n7\n8\n\n9\n10"}`)
+
+		const verses, stanzas = 10, 5
+		stop := insertConcurrently(t, func(ind int) {
+			stanza := &entity.LyricsStanza{SongID: songID, StanzaNumber: stanzas + ind + 1}
+			stanza.Verses = []entity.LyricsVerse{{SongID: songID, VerseNumber: verses + ind + 1, Verse: fmt.Sprint(verses + ind + 1)}}
+			if err := backend.AddLyricsStanza(ctx, stanza); err != nil {
+				t.Error(err)
+			}
+		})
+		forward, backward := walkLyrics(t, services, songID, nil)
+		stop()
+
+		// the appended lines carry on the numbering of the lines
+		for name, walked := range map[string][]string{"forward": forward, "backward": backward} {
+			var lines []string
+			for verse := 1; verse <= max(len(walked), verses); verse++ {
+				lines = append(lines, fmt.Sprint(verse))
+			}
+			if !slices.Equal(walked, lines) {
+				t.Errorf("walking %s got verses %q, want %q", name, walked, lines)
+			}
+		}
+	})
+}
+
+// TestGetLyricsPageMidSongEdits walks the lyrics of a song forward and
+// backward page by page while a line is inserted before the first line of
+// each page and the line inserted before the previous page is deleted, which
+// renumbers the lines the cursors point at.
+func TestGetLyricsPageMidSongEdits(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		services := service.NewService(service.Dependencies{Repository: backend.Repository, CursorSecret: "secret"})
+		songID := importSong(t, backend, `{"group": "Group", "title": "Song", "lyrics": "1\n2\n3\n\n4\n5\n6\n\n7\n8\n9\n10"}`)
+		lines := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+
+		inserted := 0
+		edit := func(page *entity.LyricsPage) {
+			position := page.Verses[0].VerseNumber
+			if inserted > 0 {
+				if err := services.Song.DeleteLyricsVerse(ctx, songID, inserted); err != nil {
+					t.Fatal(err)
+				}
+				if inserted < position {
+					position--
+				}
+			}
+
+			var err error
+			inserted, err = services.Song.InsertLyricsVerse(ctx, &entity.LyricsVerse{SongID: songID, VerseNumber: position, Verse: "Inserted"})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		forward, backward := walkLyrics(t, services, songID, edit)
+
+		for name, walked := range map[string][]string{"forward": forward, "backward": backward} {
+			walked = slices.DeleteFunc(walked, func(line string) bool { return line == "Inserted" })
+			if !slices.Equal(walked, lines) {
+				t.Errorf("walking %s got verses %q, want %q", name, walked, lines)
+			}
+		}
+	})
+}
+
+// createDatedSong creates the song with no release date when ind is odd, and
+// released ind/4 days into 2020 otherwise, so that songs created one after
+// the other share their release dates in pairs.
+func createDatedSong(t *testing.T, backend *repotest.Backend, groupID, title string, ind int) string {
+	t.Helper()
+
+	song := &entity.Song{Title: title, GroupID: groupID}
+	if ind%2 == 0 {
+		releaseDate := time.Date(2020, time.January, 1+ind/4, 0, 0, 0, 0, time.UTC)
+		song.ReleaseDate = &releaseDate
+	}
+	songID, err := backend.CreateSong(context.Background(), song)
+	if err != nil {
+		t.Error(err)
+	}
+
+	return songID
+}
+
+// insertConcurrently calls insert with 0, 1 and so on, up to maxInserts
+// times, until the returned function is called, which waits for the call in
+// progress.
+func insertConcurrently(t *testing.T, insert func(ind int)) (stop func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ind := 0; ind < maxInserts; ind++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			insert(ind)
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// walkSongs lists the songs two by two from the first page to the last one
+// and back, and returns the IDs each walk listed in the order of the listing.
+func walkSongs(t *testing.T, services *service.Service, sort []entity.SongSort) (forward, backward []string) {
+	t.Helper()
+
+	list := func(after, before string) *entity.SongList {
+		t.Helper()
+
+		list, err := services.Song.ListSongs(context.Background(), &entity.SongFilter{Sort: sort, Limit: 2}, after, before)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list
+	}
+	ids := func(songs []entity.Song) []string {
+		var ids []string
+		for _, song := range songs {
+			ids = append(ids, song.ID)
+		}
+		return ids
+	}
+
+	page := list("", "")
+	forward = ids(page.Items)
+	for pages := 1; page.NextCursor != ""; pages++ {
+		if pages == maxPages {
+			t.Fatalf("walked %d pages forward without reaching the last one", pages)
+		}
+		page = list(page.NextCursor, "")
+		forward = append(forward, ids(page.Items)...)
+	}
+
+	backward = ids(page.Items)
+	for pages := 1; page.PrevCursor != ""; pages++ {
+		if pages == maxPages {
+			t.Fatalf("walked %d pages backward without reaching the first one", pages)
+		}
+		page = list("", page.PrevCursor)
+		backward = append(ids(page.Items), backward...)
+	}
+
+	return forward, backward
+}
+
+// walkLyrics reads the lyrics of the song three lines at a time from the
+// first page to the last one and back, calling edit, unless nil, with each
+// page before reading the next one, and returns the lines each walk read in
+// the order of the lyrics.
+func walkLyrics(t *testing.T, services *service.Service, songID string, edit func(page *entity.LyricsPage)) (forward, backward []string) {
+	t.Helper()
+
+	read := func(after, before string) *entity.LyricsPage {
+		t.Helper()
+
+		page, err := services.Song.GetLyricsPage(context.Background(), songID, after, before, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if edit != nil && len(page.Verses) > 0 {
+			edit(page)
+		}
+		return page
+	}
+	lines := func(verses []entity.LyricsVerse) []string {
+		var lines []string
+		for _, verse := range verses {
+			lines = append(lines, verse.Verse)
+		}
+		return lines
+	}
+
+	page := read("", "")
+	forward = lines(page.Verses)
+	for pages := 1; page.NextCursor != ""; pages++ {
+		if pages == maxPages {
+			t.Fatalf("walked %d pages forward without reaching the last one", pages)
+		}
+		page = read(page.NextCursor, "")
+		forward = append(forward, lines(page.Verses)...)
+	}
+
+	backward = lines(page.Verses)
+	for pages := 1; page.PrevCursor != ""; pages++ {
+		if pages == maxPages {
+			t.Fatalf("walked %d pages backward without reaching the first one", pages)
+		}
+		page = read("", page.PrevCursor)
+		backward = append(lines(page.Verses), backward...)
+	}
+
+	return forward, backward
+}
+
+// isSubsequence tells whether every element of sub is in seq, in the same
+// order and as many times.
+func isSubsequence(sub, seq []string) bool {
+	for _, element := range sub {
+		ind := slices.Index(seq, element)
+		if ind < 0 {
+			return false
+		}
+		seq = seq[ind+1:]
+	}
+
+	return true
+}
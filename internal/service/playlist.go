@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+)
+
+type PlaylistService struct {
+	playlistRepo  repository.Playlist
+	songService   Song
+	dbTransaction repository.DBTransaction
+}
+
+func NewPlaylistService(playlistRepo repository.Playlist, songService Song, dbTransaction repository.DBTransaction) *PlaylistService {
+	return &PlaylistService{
+		playlistRepo:  playlistRepo,
+		songService:   songService,
+		dbTransaction: dbTransaction,
+	}
+}
+
+func (s *PlaylistService) CreatePlaylist(ctx context.Context, playlist *entity.Playlist) (string, error) {
+	playlistID, err := s.playlistRepo.CreatePlaylist(ctx, playlist)
+	if err != nil {
+		return "", fmt.Errorf("failed to create playlist: %w", err)
+	}
+
+	return playlistID, nil
+}
+
+func (s *PlaylistService) GetPlaylists(ctx context.Context) ([]entity.Playlist, error) {
+	playlists, err := s.playlistRepo.GetPlaylists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve playlists: %w", err)
+	}
+
+	return playlists, nil
+}
+
+// GetPlaylistByID returns the playlist with its entries in order, each entry
+// carrying the full song details.
+func (s *PlaylistService) GetPlaylistByID(ctx context.Context, playlistID string) (*entity.Playlist, error) {
+	playlist, err := s.playlistRepo.GetPlaylistByID(ctx, playlistID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve the playlist: %w", err)
+	}
+
+	playlist.Entries, err = s.playlistRepo.GetPlaylistEntries(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve playlist entries: %w", err)
+	}
+
+	for ind, entry := range playlist.Entries {
+		entry.Song, err = s.songService.GetSongByID(ctx, entry.SongID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve song of playlist entry: %w", err)
+		}
+		playlist.Entries[ind] = entry
+	}
+
+	return playlist, nil
+}
+
+func (s *PlaylistService) UpdatePlaylist(ctx context.Context, update *entity.PlaylistUpdate) error {
+	err := s.playlistRepo.UpdatePlaylist(ctx, update)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrPlaylistNotFound
+		}
+		return fmt.Errorf("failed to update the playlist: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PlaylistService) DeletePlaylist(ctx context.Context, playlistID string) error {
+	err := s.playlistRepo.DeletePlaylist(ctx, playlistID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrPlaylistNotFound
+		}
+		return fmt.Errorf("failed to delete the playlist: %w", err)
+	}
+
+	return nil
+}
+
+// AddPlaylistEntry appends the entry to the playlist, or inserts it at
+// entry.Position when one is given.
+func (s *PlaylistService) AddPlaylistEntry(ctx context.Context, entry *entity.PlaylistEntry) (string, error) {
+	var entryID string
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		playlist, err := s.playlistRepo.GetPlaylistByID(ctx, entry.PlaylistID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrPlaylistNotFound
+			}
+			return fmt.Errorf("failed to retrieve the playlist: %w", err)
+		}
+
+		if entry.Position == 0 {
+			entry.Position = playlist.EntriesCount + 1
+		} else if entry.Position < 1 || entry.Position > playlist.EntriesCount+1 {
+			return ErrInvalidPosition
+		}
+
+		entryID, err = s.playlistRepo.InsertPlaylistEntry(ctx, entry)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			return fmt.Errorf("failed to add playlist entry: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return entryID, nil
+}
+
+func (s *PlaylistService) MovePlaylistEntry(ctx context.Context, playlistID, entryID string, position int) error {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		playlist, err := s.playlistRepo.GetPlaylistByID(ctx, playlistID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrPlaylistNotFound
+			}
+			return fmt.Errorf("failed to retrieve the playlist: %w", err)
+		}
+
+		if position < 1 || position > playlist.EntriesCount {
+			return ErrInvalidPosition
+		}
+
+		err = s.playlistRepo.MovePlaylistEntry(ctx, playlistID, entryID, position)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrPlaylistEntryNotFound
+			}
+			return fmt.Errorf("failed to move playlist entry: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *PlaylistService) RemovePlaylistEntry(ctx context.Context, playlistID, entryID string) error {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.playlistRepo.RemovePlaylistEntry(ctx, playlistID, entryID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrPlaylistEntryNotFound
+			}
+			return fmt.Errorf("failed to remove playlist entry: %w", err)
+		}
+
+		return nil
+	})
+}
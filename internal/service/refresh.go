@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/pkg/partialdate"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxRefreshBatch bounds the songs refreshed by a single RefreshSongs call, as
+// each of them is looked up while the caller waits.
+const maxRefreshBatch = 100
+
+// RefreshSong looks the song up again in the metadata provider, bypassing the
+// metadata cache and updating it, and reports how its release date, link and
+// lyrics differ from the stored ones. Unless
+// mode is entity.RefreshModePreview the differences are stored, lyrics being
+// replaced as UpdateSong does.
+func (s *SongService) RefreshSong(ctx context.Context, songID, mode string) (*entity.SongRefresh, error) {
+	song, err := s.GetSongByID(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.refreshSong(ctx, song, mode)
+}
+
+// RefreshSongs refreshes every song matched by the filter, at most
+// maxRefreshBatch of them. A song that could not be looked up is reported with
+// its error rather than failing the whole batch.
+func (s *SongService) RefreshSongs(ctx context.Context, filter *entity.SongFilter, mode string) ([]entity.SongRefresh, error) {
+	if filter.Limit > maxRefreshBatch {
+		return nil, fmt.Errorf("%w: at most %d songs can be refreshed at once", ErrRefreshBatchTooLarge, maxRefreshBatch)
+	}
+	unbounded := filter.Limit == 0
+	if unbounded {
+		filter.Limit = maxRefreshBatch + 1
+	}
+	// the lyrics are compared with those of the provider
+	filter.Include = []string{entity.SongIncludeLyrics}
+
+	songs, err := s.GetSongsByFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if unbounded && len(songs) > maxRefreshBatch {
+		return nil, fmt.Errorf("%w: the filter matches more than %d songs, narrow it or paginate", ErrRefreshBatchTooLarge, maxRefreshBatch)
+	}
+
+	refreshes := make([]entity.SongRefresh, 0, len(songs))
+	for i := range songs {
+		song := &songs[i]
+
+		refresh, err := s.refreshSong(ctx, song, mode)
+		if err != nil {
+			if ctx.Err() != nil || !isRefreshError(err) {
+				return nil, err
+			}
+			refresh = &entity.SongRefresh{
+				SongID:    song.ID,
+				Title:     song.Title,
+				GroupName: song.GroupName,
+				Mode:      mode,
+				Changes:   []entity.SongFieldChange{},
+				Error:     err.Error(),
+			}
+		}
+		refreshes = append(refreshes, *refresh)
+	}
+
+	return refreshes, nil
+}
+
+func (s *SongService) refreshSong(ctx context.Context, song *entity.Song, mode string) (*entity.SongRefresh, error) {
+	songDetail, err := s.refreshSongDetail(ctx, song.GroupName, song.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseDate, err := songDetailReleaseDate(songDetail)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := &entity.SongRefresh{
+		SongID:    song.ID,
+		Title:     song.Title,
+		GroupName: song.GroupName,
+		Mode:      mode,
+		Changes:   []entity.SongFieldChange{},
+	}
+
+	// change records the difference the mode selects and reports whether
+	// there is one
+	change := func(field, oldValue, newValue string) bool {
+		if newValue == "" || newValue == oldValue || (mode == entity.RefreshModeFill && oldValue != "") {
+			return false
+		}
+		refresh.Changes = append(refresh.Changes, entity.SongFieldChange{Field: field, Old: oldValue, New: newValue})
+		return true
+	}
+
+	update := &entity.SongUpdate{ID: song.ID}
+	if releaseDate != nil && !refinesReleaseDate(song, *releaseDate) &&
+		change(entity.SongFieldReleaseDate, formatReleaseDate(song), releaseDate.String()) {
+		formatted := releaseDate.String()
+		update.ReleaseDate = &formatted
+	}
+	if change(entity.SongFieldLink, song.Link, songDetail.Link) {
+		update.Link = &songDetail.Link
+	}
+	// the lyrics are compared as they would be stored
+	if change(entity.SongFieldLyrics, song.LyricsText, normalizeLyrics(songDetail.Text)) {
+		update.Lyrics = &songDetail.Text
+	}
+
+	if mode == entity.RefreshModePreview || len(refresh.Changes) == 0 {
+		return refresh, nil
+	}
+
+	if err := s.UpdateSong(ctx, update); err != nil {
+		return nil, err
+	}
+	refresh.Applied = true
+
+	return refresh, nil
+}
+
+// songDetailReleaseDate parses the possibly partial release date of the
+// metadata provider, nil when it has none.
+func songDetailReleaseDate(songDetail *entity.SongDetail) (*partialdate.Date, error) {
+	if strings.TrimSpace(songDetail.ReleaseDate) == "" {
+		return nil, nil
+	}
+
+	releaseDate, err := partialdate.Parse(songDetail.ReleaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: release date %q", ErrInvalidSongDetail, songDetail.ReleaseDate)
+	}
+
+	return &releaseDate, nil
+}
+
+// refinesReleaseDate tells whether the release date of the song falls within
+// the period of releaseDate, being as or more precise, in which case it is
+// kept.
+func refinesReleaseDate(song *entity.Song, releaseDate partialdate.Date) bool {
+	if song.ReleaseDate == nil {
+		return false
+	}
+
+	current := partialdate.New(*song.ReleaseDate, partialdate.Precision(song.ReleaseDatePrecision))
+	return !current.Time.Before(releaseDate.Time) && !current.End().After(releaseDate.End())
+}
+
+// formatReleaseDate formats the release date of the song as far as it is
+// known, so that it compares with songDetailReleaseDate.
+func formatReleaseDate(song *entity.Song) string {
+	if song.ReleaseDate == nil {
+		return ""
+	}
+
+	return partialdate.New(*song.ReleaseDate, partialdate.Precision(song.ReleaseDatePrecision)).String()
+}
+
+// isRefreshError tells whether err concerns the song alone.
+func isRefreshError(err error) bool {
+	return errors.Is(err, ErrSongDetailNotFound) ||
+		errors.Is(err, ErrInvalidSongDetail) ||
+		errors.Is(err, ErrExternalAPIFailed) ||
+		errors.Is(err, ErrExternalAPIUnavailable) ||
+		errors.Is(err, ErrExternalAPITimeout) ||
+		errors.Is(err, ErrSongNotFound)
+}
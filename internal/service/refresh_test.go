@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubProvider answers every lookup with its current link, counting them.
+type stubProvider struct {
+	mu      sync.Mutex
+	link    string
+	lookups int
+}
+
+func (p *stubProvider) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lookups++
+	return &entity.SongDetail{Link: p.link}, nil
+}
+
+func (p *stubProvider) setLink(link string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.link = link
+}
+
+func (p *stubProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lookups
+}
+
+// TestRefreshSongBypassesCache refreshes a song whose lookup is cached: the
+// provider is asked again, and its answer replaces the cached one both in
+// memory and in the database.
+func TestRefreshSongBypassesCache(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		provider := &stubProvider{link: "https://example.com/old"}
+		newServices := func() *service.Service {
+			return service.NewService(service.Dependencies{
+				Repository:    backend.Repository,
+				Metadata:      provider,
+				MetadataCache: service.MetadataCacheConfig{Enabled: true, Size: 10, Persistent: true},
+			})
+		}
+		services := newServices()
+
+		songID := importEnriched(t, services)
+		provider.setLink("https://example.com/new")
+
+		refresh, err := services.Song.RefreshSong(ctx, songID, entity.RefreshModePreview)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(refresh.Changes) != 1 || refresh.Changes[0].New != "https://example.com/new" {
+			t.Errorf("got changes %+v, want the new link", refresh.Changes)
+		}
+		if got := provider.count(); got != 2 {
+			t.Errorf("got %d lookups, want the refresh to look the song up again", got)
+		}
+
+		// the cached lookups in memory and in the database are the new one
+		for _, services := range []*service.Service{services, newServices()} {
+			if err := backend.DeleteSong(ctx, songID); err != nil {
+				t.Fatal(err)
+			}
+			songID = importEnriched(t, services)
+
+			song, err := backend.GetSongByID(ctx, songID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if song.Link != "https://example.com/new" {
+				t.Errorf("got link %q from the cache, want the refreshed one", song.Link)
+			}
+		}
+		if got := provider.count(); got != 2 {
+			t.Errorf("got %d lookups, want the cache to serve the ones after the refresh", got)
+		}
+	})
+}
+
+// importEnriched imports the song filled in from the metadata provider and
+// returns its ID.
+func importEnriched(t *testing.T, services *service.Service) string {
+	t.Helper()
+
+	report, err := services.Import.ImportSongs(context.Background(), strings.NewReader(`[{"group": "Group", "title": "Song"}]`), entity.SongImportOptions{
+		Format: service.ImportFormatJSON,
+		Enrich: entity.ImportEnrichAlways,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := report.Results[0]; result.Status != entity.ImportStatusCreated {
+		t.Fatalf("failed to import the song: %s", result.Error)
+	}
+
+	return report.Results[0].SongID
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/pkg/textquery"
+	"fmt"
+	"strings"
+)
+
+// maxSuggestions bounds the suggestions of each kind a caller may ask for.
+const maxSuggestions = 50
+
+type FuzzySearchConfig struct {
+	// SimilarityThreshold is the word similarity, from 0 to 1, a title or a
+	// group name must reach to match a fuzzy filter or be suggested.
+	SimilarityThreshold float64
+	// SuggestionLimit is the number of suggestions of each kind returned
+	// when the caller does not ask for a number.
+	SuggestionLimit int
+}
+
+type SearchService struct {
+	searchRepo    repository.Search
+	dbTransaction repository.DBTransaction
+	fuzzy         FuzzySearchConfig
+}
+
+func NewSearchService(searchRepo repository.Search, dbTransaction repository.DBTransaction, fuzzy FuzzySearchConfig) *SearchService {
+	return &SearchService{
+		searchRepo:    searchRepo,
+		dbTransaction: dbTransaction,
+		fuzzy:         fuzzy,
+	}
+}
+
+// SearchSongs finds songs by their lyrics. The query takes words, "quoted
+// phrases", prefixes ending with *, terms excluded with a leading - and
+// alternatives joined by OR.
+func (s *SearchService) SearchSongs(ctx context.Context, query string, limit, offset int) ([]entity.SongSearchResult, error) {
+	parsed, err := textquery.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSearchQuery, err)
+	}
+
+	results, err := s.searchRepo.SearchSongs(ctx, parsed, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search songs: %w", err)
+	}
+
+	return results, nil
+}
+
+// SetSearchLanguage makes the search use the text search configuration named
+// language, reindexing the lyrics if it changed. It reports whether it did.
+func (s *SearchService) SetSearchLanguage(ctx context.Context, language string) (bool, error) {
+	var reindexed bool
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		reindexed, err = s.searchRepo.SetSearchLanguage(ctx, language)
+		return err
+	})
+
+	return reindexed, err
+}
+
+// SuggestSongs returns the song titles and the groups most alike query, up to
+// limit of each, or the configured number of them when limit is 0.
+func (s *SearchService) SuggestSongs(ctx context.Context, query string, limit int) (*entity.SongSuggestions, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query has no terms to search for", ErrInvalidSearchQuery)
+	}
+	if limit == 0 {
+		limit = s.fuzzy.SuggestionLimit
+	}
+	limit = min(limit, maxSuggestions)
+
+	titles, err := s.searchRepo.SuggestTitles(ctx, query, s.fuzzy.SimilarityThreshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest song titles: %w", err)
+	}
+
+	groups, err := s.searchRepo.SuggestGroups(ctx, query, s.fuzzy.SimilarityThreshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest groups: %w", err)
+	}
+
+	return &entity.SongSuggestions{
+		Titles: titles,
+		Groups: groups,
+	}, nil
+}
@@ -4,33 +4,173 @@ import (
 	"context"
 	"effective_mobile_tz/internal/entity"
 	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/pkg/cursor"
+	"io"
 )
 
 type Song interface {
 	CreateSong(ctx context.Context, groupName, title string) (string, error)
 	GetPaginatedLyrics(ctx context.Context, songID string, page, limit int) ([]entity.LyricsVerse, error)
+	GetLyricsPage(ctx context.Context, songID, after, before string, limit int) (*entity.LyricsPage, error)
+	GetPaginatedStanzas(ctx context.Context, songID string, page, limit int) ([]entity.LyricsStanza, error)
+	GetStanzaPage(ctx context.Context, songID, after, before string, limit int) (*entity.LyricsPage, error)
+	UpdateLyricsStanza(ctx context.Context, songID string, stanzaNumber int, label *string) error
+	GetLyricsVerse(ctx context.Context, songID string, verseNumber int) (*entity.LyricsVerse, error)
+	UpdateLyricsVerse(ctx context.Context, songID string, verseNumber int, verse string) error
+	InsertLyricsVerse(ctx context.Context, verse *entity.LyricsVerse) (int, error)
+	MoveLyricsVerse(ctx context.Context, songID string, verseNumber, position int) error
+	DeleteLyricsVerse(ctx context.Context, songID string, verseNumber int) error
 	GetSongsByFilter(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error)
+	ListSongs(ctx context.Context, filter *entity.SongFilter, after, before string) (*entity.SongList, error)
 	GetSongByID(ctx context.Context, songID string) (*entity.Song, error)
 	UpdateSong(ctx context.Context, update *entity.SongUpdate) error
 	DeleteSong(ctx context.Context, songID string) error
+	AttachTag(ctx context.Context, songID, tagID string) error
+	DetachTag(ctx context.Context, songID, tagID string) error
+	RefreshSong(ctx context.Context, songID, mode string) (*entity.SongRefresh, error)
+	RefreshSongs(ctx context.Context, filter *entity.SongFilter, mode string) ([]entity.SongRefresh, error)
+}
+
+type Group interface {
+	GetGroups(ctx context.Context) ([]entity.Group, error)
+	GetGroupByID(ctx context.Context, groupID string) (*entity.Group, error)
+	RenameGroup(ctx context.Context, groupID, name string) error
+	DeleteGroup(ctx context.Context, groupID string, cascade bool) error
+}
+
+type Album interface {
+	CreateAlbum(ctx context.Context, album *entity.Album) (string, error)
+	GetAlbums(ctx context.Context, filter *entity.AlbumFilter) ([]entity.Album, error)
+	GetAlbumByID(ctx context.Context, albumID string) (*entity.Album, error)
+	UpdateAlbum(ctx context.Context, update *entity.AlbumUpdate) error
+	DeleteAlbum(ctx context.Context, albumID string) error
+}
+
+type Tag interface {
+	CreateTag(ctx context.Context, tag *entity.Tag) (string, error)
+	GetTags(ctx context.Context, category string) ([]entity.TagUsage, error)
+	GetTagByID(ctx context.Context, tagID string) (*entity.TagUsage, error)
+	UpdateTag(ctx context.Context, update *entity.TagUpdate) error
+	DeleteTag(ctx context.Context, tagID string) error
+}
+
+type Playlist interface {
+	CreatePlaylist(ctx context.Context, playlist *entity.Playlist) (string, error)
+	GetPlaylists(ctx context.Context) ([]entity.Playlist, error)
+	GetPlaylistByID(ctx context.Context, playlistID string) (*entity.Playlist, error)
+	UpdatePlaylist(ctx context.Context, update *entity.PlaylistUpdate) error
+	DeletePlaylist(ctx context.Context, playlistID string) error
+	AddPlaylistEntry(ctx context.Context, entry *entity.PlaylistEntry) (string, error)
+	MovePlaylistEntry(ctx context.Context, playlistID, entryID string, position int) error
+	RemovePlaylistEntry(ctx context.Context, playlistID, entryID string) error
+}
+
+type Import interface {
+	ImportSongs(ctx context.Context, r io.Reader, opts entity.SongImportOptions) (*entity.SongImportReport, error)
+}
+
+type Backup interface {
+	ExportLibrary(ctx context.Context, w io.Writer, format string, compress bool) error
+	RestoreLibrary(ctx context.Context, r io.Reader, policy string) (*entity.RestoreReport, error)
+}
+
+type Enrichment interface {
+	GetEnrichmentJob(ctx context.Context, songID string) (*entity.EnrichmentJob, error)
+	GetEnrichmentJobs(ctx context.Context, filter *entity.EnrichmentJobFilter) ([]entity.EnrichmentJob, error)
+	RequeueEnrichment(ctx context.Context, songID string) error
+	RequeueFailedEnrichments(ctx context.Context) (int, error)
+	StartWorkers()
+	StopWorkers(ctx context.Context) error
+}
+
+type MetadataCache interface {
+	GetMetadataCacheStats(ctx context.Context) (*entity.MetadataCacheStats, error)
+	PurgeMetadataCache(ctx context.Context, groupName, title string) (*entity.MetadataCachePurge, error)
+}
+
+type Search interface {
+	SearchSongs(ctx context.Context, query string, limit, offset int) ([]entity.SongSearchResult, error)
+	SetSearchLanguage(ctx context.Context, language string) (bool, error)
+	SuggestSongs(ctx context.Context, query string, limit int) (*entity.SongSuggestions, error)
 }
 
 type Service struct {
 	Song
+	Group
+	Album
+	Tag
+	Playlist
+	Import
+	Backup
+	Enrichment
+	MetadataCache
+	Search
+}
+
+// MetadataProvider is the source new songs are enriched from with their
+// release date, lyrics and link.
+type MetadataProvider interface {
+	GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error)
 }
 
 type Dependencies struct {
-	Repository     *repository.Repository
-	ExternalApiURL string
+	Repository    *repository.Repository
+	Metadata      MetadataProvider
+	MetadataCache MetadataCacheConfig
+	Enrichment    EnrichmentConfig
+	FuzzySearch   FuzzySearchConfig
+	// CursorSecret signs the pagination cursors; cursors signed with a
+	// random secret when it is empty are only valid until a restart.
+	CursorSecret string
 }
 
 func NewService(dependencies Dependencies) *Service {
+	metadataCache := NewMetadataCacheService(
+		dependencies.Metadata,
+		dependencies.Repository.SongDetailCache,
+		dependencies.Repository.DBTransaction,
+		dependencies.MetadataCache)
+
+	songService := NewSongService(
+		dependencies.Repository.Song,
+		dependencies.Repository.Group,
+		dependencies.Repository.Lyrics,
+		dependencies.Repository.Album,
+		dependencies.Repository.Tag,
+		dependencies.Repository.Playlist,
+		dependencies.Repository.Enrichment,
+		dependencies.Repository.DBTransaction,
+		metadataCache,
+		dependencies.FuzzySearch.SimilarityThreshold,
+		cursor.New(dependencies.CursorSecret))
+
 	return &Service{
-		Song: NewSongService(
+		Song: songService,
+		Group: NewGroupService(
+			dependencies.Repository.Group,
 			dependencies.Repository.Song,
+			dependencies.Repository.Playlist,
+			dependencies.Repository.DBTransaction),
+		Album: NewAlbumService(
+			dependencies.Repository.Album,
 			dependencies.Repository.Group,
+			dependencies.Repository.Song,
+			dependencies.Repository.DBTransaction),
+		Tag: NewTagService(dependencies.Repository.Tag),
+		Playlist: NewPlaylistService(
+			dependencies.Repository.Playlist,
+			songService,
+			dependencies.Repository.DBTransaction),
+		Import: NewImportService(songService, dependencies.Repository.DBTransaction),
+		Backup: NewBackupService(dependencies.Repository.Archive, dependencies.Repository.DBTransaction),
+		Enrichment: NewEnrichmentService(
+			dependencies.Repository.Enrichment,
+			dependencies.Repository.Song,
 			dependencies.Repository.Lyrics,
 			dependencies.Repository.DBTransaction,
-			dependencies.ExternalApiURL),
+			songService,
+			dependencies.Enrichment),
+		MetadataCache: metadataCache,
+		Search:        NewSearchService(dependencies.Repository.Search, dependencies.Repository.DBTransaction, dependencies.FuzzySearch),
 	}
 }
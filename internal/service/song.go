@@ -3,52 +3,88 @@ package service
 import (
 	"context"
 	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/metadata"
 	"effective_mobile_tz/internal/repository"
 	"effective_mobile_tz/internal/repository/repoerrors"
-	"encoding/json"
+	"effective_mobile_tz/internal/webapi"
+	"effective_mobile_tz/pkg/cursor"
+	"effective_mobile_tz/pkg/partialdate"
+	"effective_mobile_tz/pkg/stanza"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type SongService struct {
-	songRepo      repository.Song
-	groupRepo     repository.Group
-	lyricsRepo    repository.Lyrics
-	dbTransaction repository.DBTransaction
-	externalAPI   string
+	songRepo            repository.Song
+	groupRepo           repository.Group
+	lyricsRepo          repository.Lyrics
+	albumRepo           repository.Album
+	tagRepo             repository.Tag
+	playlistRepo        repository.Playlist
+	enrichmentRepo      repository.Enrichment
+	dbTransaction       repository.DBTransaction
+	metadata            *MetadataCacheService
+	similarityThreshold float64
+	cursors             *cursor.Codec
 }
 
-func NewSongService(songPostgres repository.Song, groupPostgres repository.Group, lyricsRepo repository.Lyrics, dbTransaction repository.DBTransaction, externalAPI string) *SongService {
+func NewSongService(songPostgres repository.Song, groupPostgres repository.Group, lyricsRepo repository.Lyrics, albumRepo repository.Album, tagRepo repository.Tag, playlistRepo repository.Playlist, enrichmentRepo repository.Enrichment, dbTransaction repository.DBTransaction, metadata *MetadataCacheService, similarityThreshold float64, cursors *cursor.Codec) *SongService {
 	return &SongService{
-		songRepo:      songPostgres,
-		groupRepo:     groupPostgres,
-		lyricsRepo:    lyricsRepo,
-		dbTransaction: dbTransaction,
-		externalAPI:   externalAPI}
+		songRepo:            songPostgres,
+		groupRepo:           groupPostgres,
+		lyricsRepo:          lyricsRepo,
+		albumRepo:           albumRepo,
+		tagRepo:             tagRepo,
+		playlistRepo:        playlistRepo,
+		enrichmentRepo:      enrichmentRepo,
+		dbTransaction:       dbTransaction,
+		metadata:            metadata,
+		similarityThreshold: similarityThreshold,
+		cursors:             cursors}
 }
 
+// CreateSong stores the song right away and queues its enrichment with the
+// details of the metadata provider.
 func (s *SongService) CreateSong(ctx context.Context, groupName, title string) (string, error) {
-	// starting transaction
-	tx, err := s.dbTransaction.Begin(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	song := &entity.Song{
+		Title:            title,
+		GroupName:        groupName,
+		EnrichmentStatus: entity.EnrichmentStatusPending,
 	}
-	defer func() {
+
+	var songID string
+	err := s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		songID, err = s.createSong(ctx, song, "")
 		if err != nil {
-			_ = tx.Rollback(ctx)
+			return err
 		}
-	}()
 
-	var groupID string
-	groupID, err = s.groupRepo.GetGroupIDByName(ctx, groupName)
+		err = s.enrichmentRepo.CreateEnrichmentJob(ctx, songID)
+		if err != nil {
+			return fmt.Errorf("failed to queue the enrichment of the song: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return songID, nil
+}
+
+// createSong stores the song of song.GroupName, creating the group if needed,
+// together with its lyrics. It must be called with the context of a
+// transaction.
+func (s *SongService) createSong(ctx context.Context, song *entity.Song, lyrics string) (string, error) {
+	groupID, err := s.groupRepo.GetGroupIDByName(ctx, song.GroupName)
 	if err != nil {
 		if errors.Is(err, repoerrors.ErrNotFound) {
-			groupID, err = s.groupRepo.CreateGroup(ctx, groupName)
+			groupID, err = s.groupRepo.CreateGroup(ctx, song.GroupName)
 			if err != nil {
 				return "", fmt.Errorf("failed to create group: %w", err)
 			}
@@ -56,22 +92,7 @@ func (s *SongService) CreateSong(ctx context.Context, groupName, title string) (
 			return "", fmt.Errorf("failed to get group: %w", err)
 		}
 	}
-
-	songDetail, err := fetchSongDetail(s.externalAPI, groupName, title)
-	if err != nil {
-		return "", fmt.Errorf("failed to access to external api: %w", err)
-	}
-
-	releaseDate, err := time.Parse("02.01.2006", songDetail.ReleaseDate)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse release date: %w", err)
-	}
-	song := &entity.Song{
-		Title:       title,
-		GroupID:     groupID,
-		ReleaseDate: releaseDate,
-		Link:        songDetail.Link,
-	}
+	song.GroupID = groupID
 
 	songID, err := s.songRepo.CreateSong(ctx, song)
 	if err != nil {
@@ -82,54 +103,119 @@ func (s *SongService) CreateSong(ctx context.Context, groupName, title string) (
 		return "", err
 	}
 
-	if strings.Trim(songDetail.Text, " ") != "" {
-		lyricsVerses := strings.Split(songDetail.Text, "\n")
+	if strings.Trim(lyrics, " ") != "" {
+		if err = s.addLyrics(ctx, songID, lyrics); err != nil {
+			return "", fmt.Errorf("failed to add lyrics for the song: %w", err)
+		}
+	}
 
-		for verseNumber, verse := range lyricsVerses {
-			lyricsVerse := &entity.LyricsVerse{
-				SongID:      songID,
-				Verse:       verse,
-				VerseNumber: verseNumber + 1,
-			}
+	return songID, nil
+}
 
-			err = s.lyricsRepo.AddLyricsVerse(ctx, lyricsVerse)
-			if err != nil {
-				return "", fmt.Errorf("failed to add lyrics for the song: %w", err)
-			}
+// addLyrics stores the lyrics of the song stanza by stanza, numbering their
+// lines through the whole song.
+func (s *SongService) addLyrics(ctx context.Context, songID, lyrics string) error {
+	var verseNumber int
+	for ind, parsed := range stanza.Parse(lyrics) {
+		lyricsStanza := &entity.LyricsStanza{
+			SongID:       songID,
+			StanzaNumber: ind + 1,
+		}
+
+		lines := parsed.Lines
+		if utf8.RuneCountInString(parsed.Label) > maxStanzaLabelLength {
+			// too long for a label, the line is kept as it is
+			lines = append([]string{"[" + parsed.Label + "]"}, lines...)
+		} else if parsed.Label != "" {
+			lyricsStanza.Label = &parsed.Label
+		}
+
+		for _, line := range lines {
+			verseNumber++
+			lyricsStanza.Verses = append(lyricsStanza.Verses, entity.LyricsVerse{
+				SongID:       songID,
+				Verse:        line,
+				VerseNumber:  verseNumber,
+				StanzaNumber: lyricsStanza.StanzaNumber,
+			})
 		}
-	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return "", fmt.Errorf("failed to commit transaction: %w", err)
+		if err := s.lyricsRepo.AddLyricsStanza(ctx, lyricsStanza); err != nil {
+			return err
+		}
 	}
 
-	return songID, nil
+	return nil
 }
 
 func (s *SongService) GetSongsByFilter(ctx context.Context, filter *entity.SongFilter) ([]entity.Song, error) {
+	filter.Tag = normalizeTagName(filter.Tag)
+	filter.AnyTags = normalizeTagNames(filter.AnyTags)
+	filter.AllTags = normalizeTagNames(filter.AllTags)
+	if filter.Fuzzy {
+		filter.SimilarityThreshold = s.similarityThreshold
+	}
+	if len(filter.Sort) == 0 {
+		filter.Sort = entity.DefaultSongSort
+	}
+
 	songs, err := s.songRepo.GetSongsByFilter(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve songs: %w", err)
 	}
 
-	for ind, song := range songs {
-		lyrics, err := s.lyricsRepo.GetAllLyrics(ctx, song.ID)
+	songIDs := make([]string, 0, len(songs))
+	for _, song := range songs {
+		songIDs = append(songIDs, song.ID)
+	}
+
+	// the details of every song are read at once rather than song by song
+	if filter.Includes(entity.SongIncludeTags) && len(songIDs) > 0 {
+		tags, err := s.tagRepo.GetTagsBySongIDs(ctx, songIDs)
 		if err != nil {
-			return nil, fmt.Errorf("error while retrieving lyrics for song: %w", err)
+			return nil, fmt.Errorf("error while retrieving tags for songs: %w", err)
 		}
-
-		var lyricsSliceOfStrings []string
-		for _, verse := range lyrics {
-			lyricsSliceOfStrings = append(lyricsSliceOfStrings, verse.Verse)
+		for ind := range songs {
+			songs[ind].Tags = tags[songs[ind].ID]
 		}
+	}
 
-		song.LyricsText = strings.Join(lyricsSliceOfStrings, "\n")
-		songs[ind] = song
+	if filter.Includes(entity.SongIncludeLyrics) && len(songIDs) > 0 {
+		lyrics, err := s.lyricsRepo.GetLyricsBySongIDs(ctx, songIDs)
+		if err != nil {
+			return nil, fmt.Errorf("error while retrieving lyrics for songs: %w", err)
+		}
+		for ind := range songs {
+			songs[ind].LyricsText = formatLyrics(lyrics[songs[ind].ID])
+		}
 	}
 
 	return songs, nil
 }
 
+// formatLyrics puts the stanzas back together into the text of the lyrics,
+// as addLyrics reads it.
+func formatLyrics(stanzas []entity.LyricsStanza) string {
+	parsed := make([]stanza.Stanza, 0, len(stanzas))
+	for _, lyricsStanza := range stanzas {
+		var formatted stanza.Stanza
+		if lyricsStanza.Label != nil {
+			formatted.Label = *lyricsStanza.Label
+		}
+		for _, verse := range lyricsStanza.Verses {
+			formatted.Lines = append(formatted.Lines, verse.Verse)
+		}
+		parsed = append(parsed, formatted)
+	}
+
+	return stanza.Format(parsed)
+}
+
+// normalizeLyrics returns the lyrics as they read once stored.
+func normalizeLyrics(lyrics string) string {
+	return stanza.Format(stanza.Parse(lyrics))
+}
+
 func (s *SongService) GetSongByID(ctx context.Context, songID string) (*entity.Song, error) {
 	song, err := s.songRepo.GetSongByID(ctx, songID)
 	if err != nil {
@@ -140,114 +226,180 @@ func (s *SongService) GetSongByID(ctx context.Context, songID string) (*entity.S
 		return nil, fmt.Errorf("failed to retrieve the song: %w", err)
 	}
 
-	lyrics, err := s.lyricsRepo.GetAllLyrics(ctx, song.ID)
+	lyrics, err := s.lyricsRepo.GetLyricsBySongIDs(ctx, []string{song.ID})
 	if err != nil {
 		return nil, fmt.Errorf("error while retrieving lyrics for song: %w", err)
 	}
 
-	var lyricsSliceOfStrings []string
-	for _, verse := range lyrics {
-		lyricsSliceOfStrings = append(lyricsSliceOfStrings, verse.Verse)
-	}
+	song.LyricsText = formatLyrics(lyrics[song.ID])
 
-	song.LyricsText = strings.Join(lyricsSliceOfStrings, "\n")
+	tags, err := s.tagRepo.GetTagsBySongIDs(ctx, []string{song.ID})
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving tags for song: %w", err)
+	}
+	song.Tags = tags[song.ID]
 
 	return song, nil
 }
 
+// UpdateSong changes the given fields of the song. The release date may be
+// partial, and is stored along with how much of it is known.
 func (s *SongService) UpdateSong(ctx context.Context, update *entity.SongUpdate) error {
-	// starting transaction
-	tx, err := s.dbTransaction.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
+	if update.ReleaseDate != nil {
+		releaseDate, err := partialdate.Parse(*update.ReleaseDate)
 		if err != nil {
-			_ = tx.Rollback(ctx)
+			return fmt.Errorf("%w: %q", ErrInvalidReleaseDate, *update.ReleaseDate)
 		}
-	}()
+		setReleaseDate(update, releaseDate)
+	}
 
-	if update.GroupName != nil {
-		update.GroupID, err = s.groupRepo.GetGroupIDByName(ctx, *update.GroupName)
-		if err != nil {
-			if errors.Is(err, repoerrors.ErrNotFound) {
-				update.GroupID, err = s.groupRepo.CreateGroup(ctx, *update.GroupName)
-				if err != nil {
-					return fmt.Errorf("failed to create group: %w", err)
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if update.GroupName != nil {
+			update.GroupID, err = s.groupRepo.GetGroupIDByName(ctx, *update.GroupName)
+			if err != nil {
+				if errors.Is(err, repoerrors.ErrNotFound) {
+					update.GroupID, err = s.groupRepo.CreateGroup(ctx, *update.GroupName)
+					if err != nil {
+						return fmt.Errorf("failed to create group: %w", err)
+					}
+				} else {
+					return fmt.Errorf("failed to get group: %w", err)
 				}
-			} else {
-				return fmt.Errorf("failed to get group: %w", err)
 			}
 		}
-	}
 
-	err = s.songRepo.UpdateSong(ctx, update)
-	if err != nil {
-		if errors.Is(err, repoerrors.ErrNotFound) {
-			return ErrSongNotFound
-		} else if errors.Is(err, repoerrors.ErrAlreadyExists) {
-			return ErrSongAlreadyExists
+		if err = s.checkAlbumGroup(ctx, update); err != nil {
+			return err
 		}
 
-		return fmt.Errorf("failed to update the song: %w", err)
-	}
-
-	if update.Lyrics != nil {
-		err = s.lyricsRepo.DeleteLyrics(ctx, update.ID)
+		err = s.songRepo.UpdateSong(ctx, update)
 		if err != nil {
-			return fmt.Errorf("failed to delete old lyrics: %w", err)
-		}
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrSongNotFound
+			} else if errors.Is(err, repoerrors.ErrAlreadyExists) {
+				return ErrSongAlreadyExists
+			}
 
-		lyricsVerses := strings.Split(*update.Lyrics, "\n")
+			return fmt.Errorf("failed to update the song: %w", err)
+		}
 
-		for verseNumber, verse := range lyricsVerses {
-			lyricsVerse := &entity.LyricsVerse{
-				SongID:      update.ID,
-				Verse:       verse,
-				VerseNumber: verseNumber + 1,
+		if update.Lyrics != nil {
+			err = s.lyricsRepo.DeleteLyrics(ctx, update.ID)
+			if err != nil {
+				return fmt.Errorf("failed to delete old lyrics: %w", err)
 			}
 
-			err = s.lyricsRepo.AddLyricsVerse(ctx, lyricsVerse)
+			err = s.addLyrics(ctx, update.ID, *update.Lyrics)
 			if err != nil {
 				return fmt.Errorf("failed to add new lyrics for the song: %w", err)
 			}
 		}
+
+		return nil
+	})
+}
+
+// checkAlbumGroup makes sure that the album the song is on after the update,
+// if any, belongs to the group the song belongs to after it.
+func (s *SongService) checkAlbumGroup(ctx context.Context, update *entity.SongUpdate) error {
+	if update.AlbumID == nil && update.GroupName == nil || update.AlbumID != nil && *update.AlbumID == "" {
+		return nil
+	}
+
+	song, err := s.songRepo.GetSongByID(ctx, update.ID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrSongNotFound
+		}
+		return fmt.Errorf("failed to get the song: %w", err)
+	}
+	albumID := song.AlbumID
+	if update.AlbumID != nil {
+		albumID = update.AlbumID
+	}
+	if albumID == nil {
+		return nil
+	}
+
+	album, err := s.albumRepo.GetAlbumByID(ctx, *albumID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrAlbumNotFound
+		}
+		return fmt.Errorf("failed to get album: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	sameGroup := album.GroupName == song.GroupName
+	if update.GroupName != nil {
+		sameGroup = album.GroupID == update.GroupID
+	}
+	if !sameGroup {
+		return ErrAlbumOfOtherGroup
 	}
 
 	return nil
 }
 
 func (s *SongService) DeleteSong(ctx context.Context, songID string) error {
-	// starting transaction
-	tx, err := s.dbTransaction.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
+	return s.dbTransaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.lyricsRepo.DeleteLyrics(ctx, songID)
+		if err != nil {
+			return fmt.Errorf("failed to delete lyrics: %w", err)
+		}
+
+		err = s.playlistRepo.RemoveSongFromPlaylists(ctx, songID)
+		if err != nil {
+			return fmt.Errorf("failed to remove the song from playlists: %w", err)
+		}
+
+		err = s.songRepo.DeleteSong(ctx, songID)
 		if err != nil {
-			_ = tx.Rollback(ctx)
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			return fmt.Errorf("failed to delete the song: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SongService) AttachTag(ctx context.Context, songID, tagID string) error {
+	_, err := s.songRepo.GetSongByID(ctx, songID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrSongNotFound
 		}
-	}()
+		return fmt.Errorf("failed to retrieve the song: %w", err)
+	}
 
-	err = s.lyricsRepo.DeleteLyrics(ctx, songID)
+	_, err = s.tagRepo.GetTagByID(ctx, tagID)
 	if err != nil {
-		return fmt.Errorf("failed to delete lyrics: %w", err)
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrTagNotFound
+		}
+		return fmt.Errorf("failed to retrieve the tag: %w", err)
 	}
 
-	err = s.songRepo.DeleteSong(ctx, songID)
+	err = s.tagRepo.AttachTag(ctx, songID, tagID)
 	if err != nil {
 		if errors.Is(err, repoerrors.ErrNotFound) {
 			return ErrSongNotFound
 		}
-		return fmt.Errorf("failed to delete the song: %w", err)
+		return fmt.Errorf("failed to attach the tag: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	return nil
+}
+
+func (s *SongService) DetachTag(ctx context.Context, songID, tagID string) error {
+	err := s.tagRepo.DetachTag(ctx, songID, tagID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrTagNotFound
+		}
+		return fmt.Errorf("failed to detach the tag: %w", err)
 	}
 
 	return nil
@@ -258,51 +410,53 @@ func (s *SongService) GetPaginatedLyrics(ctx context.Context, songID string, pag
 	return s.lyricsRepo.GetPaginatedLyrics(ctx, songID, limit, offset)
 }
 
-type SongDetail struct {
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+// GetPaginatedStanzas returns the stanzas of the page, with their lines.
+func (s *SongService) GetPaginatedStanzas(ctx context.Context, songID string, page, limit int) ([]entity.LyricsStanza, error) {
+	first := (page-1)*limit + 1
+	return s.lyricsRepo.GetLyricsStanzas(ctx, songID, first, first+limit-1)
 }
 
-func fetchSongDetail(apiURL, groupName, songTitle string) (*SongDetail, error) {
-	params := url.Values{}
-	params.Add("group", groupName)
-	params.Add("song", songTitle)
-
-	fullURL := fmt.Sprintf("%s/info?%s", apiURL, params.Encode())
+// setReleaseDate puts the date in the update the way the repositories store
+// it: the first day of the period, and the precision.
+func setReleaseDate(update *entity.SongUpdate, releaseDate partialdate.Date) {
+	formatted := releaseDate.Time.Format(time.DateOnly)
+	update.ReleaseDate = &formatted
+	update.ReleaseDatePrecision = string(releaseDate.Precision)
+}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+// lookupSongDetail asks the metadata provider about the song, through the
+// cache, translating its failures into the errors of this package.
+func (s *SongService) lookupSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	songDetail, err := s.metadata.GetSongDetail(ctx, groupName, title)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, songDetailError(err)
 	}
 
-	req.Header.Set("Accept", "application/json")
+	return songDetail, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// refreshSongDetail is lookupSongDetail bypassing the cache, whose lookup of
+// the song it replaces.
+func (s *SongService) refreshSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	songDetail, err := s.metadata.Refresh(ctx, groupName, title)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
-		}
-		fmt.Printf("Response: %s", string(body))
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, songDetailError(err)
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+	return songDetail, nil
+}
 
-	var songDetail SongDetail
-	if err := json.Unmarshal(body, &songDetail); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+func songDetailError(err error) error {
+	switch {
+	case errors.Is(err, metadata.ErrNotFound):
+		return ErrSongDetailNotFound
+	case errors.Is(err, webapi.ErrTimeout):
+		return fmt.Errorf("%w: %w", ErrExternalAPITimeout, err)
+	case errors.Is(err, webapi.ErrUnavailable), errors.Is(err, webapi.ErrCircuitOpen):
+		return fmt.Errorf("%w: %w", ErrExternalAPIUnavailable, err)
+	case errors.Is(err, webapi.ErrBadResponse):
+		return fmt.Errorf("%w: %w", ErrExternalAPIFailed, err)
 	}
 
-	return &songDetail, nil
+	return err
 }
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repoerrors"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type TagService struct {
+	tagRepo repository.Tag
+}
+
+func NewTagService(tagRepo repository.Tag) *TagService {
+	return &TagService{tagRepo: tagRepo}
+}
+
+func (s *TagService) CreateTag(ctx context.Context, tag *entity.Tag) (string, error) {
+	tag.Name = normalizeTagName(tag.Name)
+	if tag.Name == "" {
+		return "", ErrInvalidTagName
+	}
+
+	tagID, err := s.tagRepo.CreateTag(ctx, tag)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return "", ErrTagAlreadyExists
+		}
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tagID, nil
+}
+
+func (s *TagService) GetTags(ctx context.Context, category string) ([]entity.TagUsage, error) {
+	tags, err := s.tagRepo.GetTags(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (s *TagService) GetTagByID(ctx context.Context, tagID string) (*entity.TagUsage, error) {
+	tag, err := s.tagRepo.GetTagByID(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve the tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) UpdateTag(ctx context.Context, update *entity.TagUpdate) error {
+	if update.Name != nil {
+		name := normalizeTagName(*update.Name)
+		if name == "" {
+			return ErrInvalidTagName
+		}
+		update.Name = &name
+	}
+
+	err := s.tagRepo.UpdateTag(ctx, update)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrTagNotFound
+		} else if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return ErrTagAlreadyExists
+		}
+		return fmt.Errorf("failed to update the tag: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TagService) DeleteTag(ctx context.Context, tagID string) error {
+	err := s.tagRepo.DeleteTag(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return ErrTagNotFound
+		}
+		return fmt.Errorf("failed to delete the tag: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeTagName makes tag names case-insensitive, so "Rock" and "rock " are the same tag.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func normalizeTagNames(names []string) []string {
+	var normalized []string
+	for _, name := range names {
+		if name = normalizeTagName(name); name != "" {
+			normalized = append(normalized, name)
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"effective_mobile_tz/internal/repository"
+	"effective_mobile_tz/internal/repository/repotest"
+	"effective_mobile_tz/internal/service"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var errLyricsFailed = errors.New("lyrics storage failed")
+
+// failingLyrics fails to store any lyrics.
+type failingLyrics struct {
+	repository.Lyrics
+}
+
+func (failingLyrics) AddLyricsStanza(ctx context.Context, stanza *entity.LyricsStanza) error {
+	return errLyricsFailed
+}
+
+// withFailingLyrics returns services on the backend whose lyrics cannot be
+// stored.
+func withFailingLyrics(backend *repotest.Backend) *service.Service {
+	repo := *backend.Repository
+	repo.Lyrics = failingLyrics{Lyrics: repo.Lyrics}
+
+	return service.NewService(service.Dependencies{Repository: &repo})
+}
+
+// TestCreateSongLyricsFailure imports a song whose lyrics fail to be stored
+// next to one without lyrics in the same batch: the first leaves neither its
+// group nor its song behind, and the savepoint rolled back does not keep the
+// second from being created.
+func TestCreateSongLyricsFailure(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		services := withFailingLyrics(backend)
+
+		input := `[
+			{"group": "Broken group", "title": "Broken song", "lyrics": "First line\nSecond line"},
+			{"group": "Sound group", "title": "Sound song"}
+		]`
+		report, err := services.Import.ImportSongs(ctx, strings.NewReader(input), entity.SongImportOptions{
+			Format: service.ImportFormatJSON,
+			Enrich: entity.ImportEnrichNever,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := report.Results[0]; got.Status != entity.ImportStatusFailed || !strings.Contains(got.Error, errLyricsFailed.Error()) {
+			t.Errorf("got %+v for the song with lyrics, want it failed by the lyrics", got)
+		}
+		if got := report.Results[1]; got.Status != entity.ImportStatusCreated {
+			t.Errorf("got %+v for the song without lyrics, want it created", got)
+		}
+
+		if got, want := groupNames(t, backend), []string{"Sound group"}; !slices.Equal(got, want) {
+			t.Errorf("got groups %q, want %q", got, want)
+		}
+		if got, want := songTitles(t, backend), []string{"Sound song"}; !slices.Equal(got, want) {
+			t.Errorf("got songs %q, want %q", got, want)
+		}
+	})
+}
+
+// TestUpdateSongLyricsFailure updates a song into a new group with lyrics
+// that fail to be stored: the song keeps its title, group and lyrics, and the
+// new group is not created.
+func TestUpdateSongLyricsFailure(t *testing.T) {
+	repotest.Each(t, func(t *testing.T, backend *repotest.Backend) {
+		ctx := context.Background()
+		songID := importSong(t, backend, `{"group": "Old group", "title": "Old title", "lyrics": "Old line"}`)
+
+		title, group, lyrics := "New title", "New group", "New line"
+		err := withFailingLyrics(backend).Song.UpdateSong(ctx, &entity.SongUpdate{
+			ID:        songID,
+			Title:     &title,
+			GroupName: &group,
+			Lyrics:    &lyrics,
+		})
+		if !errors.Is(err, errLyricsFailed) {
+			t.Fatalf("got error %v, want %v", err, errLyricsFailed)
+		}
+
+		song, err := backend.GetSongByID(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.Title != "Old title" || song.GroupName != "Old group" {
+			t.Errorf("got song %q of %q, want it unchanged", song.Title, song.GroupName)
+		}
+		verses, err := backend.CountLyricsVerses(ctx, songID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verses != 1 {
+			t.Errorf("got %d verses, want the 1 verse the song had", verses)
+		}
+		if got, want := groupNames(t, backend), []string{"Old group"}; !slices.Equal(got, want) {
+			t.Errorf("got groups %q, want %q", got, want)
+		}
+	})
+}
+
+// importSong imports the JSON row without enrichment and returns the ID of
+// the song.
+func importSong(t *testing.T, backend *repotest.Backend, row string) string {
+	t.Helper()
+
+	services := service.NewService(service.Dependencies{Repository: backend.Repository})
+	report, err := services.Import.ImportSongs(context.Background(), strings.NewReader("["+row+"]"), entity.SongImportOptions{
+		Format: service.ImportFormatJSON,
+		Enrich: entity.ImportEnrichNever,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := report.Results[0]; result.Status != entity.ImportStatusCreated {
+		t.Fatalf("failed to import %s: %s", row, result.Error)
+	}
+
+	return report.Results[0].SongID
+}
+
+func groupNames(t *testing.T, backend *repotest.Backend) []string {
+	t.Helper()
+
+	groups, err := backend.GetGroups(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func songTitles(t *testing.T, backend *repotest.Backend) []string {
+	t.Helper()
+
+	songs, err := backend.GetSongsByFilter(context.Background(), &entity.SongFilter{Sort: entity.DefaultSongSort})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, song := range songs {
+		titles = append(titles, song.Title)
+	}
+	slices.Sort(titles)
+
+	return titles
+}
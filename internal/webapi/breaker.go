@@ -0,0 +1,82 @@
+package webapi
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops requests to the API after threshold consecutive
+// failures. Once cooldown has passed a single probe request is let through:
+// its success closes the circuit again, its failure reopens it, and when it
+// is released another probe may go. Requests sent before the circuit opened
+// may still end while the probe is out, and leave the probe alone.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a request may be sent, and whether it is the probe,
+// which the request passes on to success, failure or release.
+func (b *circuitBreaker) allow() (allowed, probe bool) {
+	if b.threshold <= 0 {
+		return true, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, false
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false, false
+	}
+
+	b.probing = true
+	return true, true
+}
+
+func (b *circuitBreaker) success(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if probe {
+		b.probing = false
+	}
+}
+
+func (b *circuitBreaker) failure(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if probe {
+		b.probing = false
+	}
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release ends a request that tells nothing about the API, such as one the
+// caller gave up on, without counting it either way.
+func (b *circuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
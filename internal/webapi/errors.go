@@ -0,0 +1,52 @@
+package webapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound means the API does not know the requested song.
+	ErrNotFound = errors.New("song not found by the external api")
+	// ErrBadResponse means the API answered with an unexpected status or an
+	// undecodable body.
+	ErrBadResponse = errors.New("bad response from the external api")
+	// ErrUnavailable means the API could not be reached or kept failing.
+	ErrUnavailable = errors.New("external api unavailable")
+	// ErrTimeout means the API did not answer in time.
+	ErrTimeout = errors.New("external api timed out")
+	// ErrCircuitOpen means requests are not sent, as the API failed recently.
+	ErrCircuitOpen = errors.New("external api circuit open")
+)
+
+// Error describes a failed request. It matches one of the sentinel errors
+// above with errors.Is.
+type Error struct {
+	Kind       error
+	StatusCode int
+	Attempts   int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(": status %d", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+
+	return []error{e.Kind, e.Err}
+}
@@ -0,0 +1,192 @@
+package webapi
+
+import (
+	"context"
+	"effective_mobile_tz/internal/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SongInfo looks up song details in the external API.
+type SongInfo interface {
+	GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error)
+}
+
+type Config struct {
+	URL string
+	// Timeout bounds every single attempt.
+	Timeout time.Duration
+	// MaxRetries is the number of attempts made after the first one fails
+	// with a network error, a timeout or a 5xx or 429 status.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every
+	// further one up to RetryMaxBackoff. Delays are jittered.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+	// BreakerThreshold consecutive failed attempts open the circuit for
+	// BreakerCooldown. Zero disables the circuit breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+const maxLoggedBodySize = 512
+
+type SongInfoClient struct {
+	cfg     Config
+	client  *http.Client
+	breaker *circuitBreaker
+}
+
+func NewSongInfoClient(cfg Config) *SongInfoClient {
+	return &SongInfoClient{
+		cfg:     cfg,
+		client:  &http.Client{},
+		breaker: newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+func (c *SongInfoClient) GetSongDetail(ctx context.Context, groupName, title string) (*entity.SongDetail, error) {
+	params := url.Values{}
+	params.Add("group", groupName)
+	params.Add("song", title)
+	fullURL := fmt.Sprintf("%s/info?%s", strings.TrimRight(c.cfg.URL, "/"), params.Encode())
+
+	for attempt := 1; ; attempt++ {
+		allowed, probe := c.breaker.allow()
+		if !allowed {
+			return nil, &Error{Kind: ErrCircuitOpen, Attempts: attempt - 1}
+		}
+
+		detail, err := c.fetch(ctx, fullURL)
+		if err == nil {
+			c.breaker.success(probe)
+			return detail, nil
+		}
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			// the caller gave up, which says nothing about the api
+			c.breaker.release(probe)
+			return nil, err
+		}
+		apiErr.Attempts = attempt
+
+		if !retryable(apiErr) {
+			// the api answered, so it is up
+			c.breaker.success(probe)
+			return nil, apiErr
+		}
+		c.breaker.failure(probe)
+
+		if attempt > c.cfg.MaxRetries {
+			return nil, apiErr
+		}
+
+		delay := c.backoff(attempt)
+		log.Debugf("external api attempt %d failed, retrying in %s: %s", attempt, delay, apiErr)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// fetch makes a single attempt. Failures are returned as *Error, unless the
+// context of the caller is done.
+func (c *SongInfoClient) fetch(ctx context.Context, fullURL string) (*entity.SongDetail, error) {
+	attemptCtx := ctx
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, &Error{Kind: ErrBadResponse, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, c.requestError(ctx, attemptCtx, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, c.requestError(ctx, attemptCtx, fmt.Errorf("failed to read response body: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Debugf("external api responded with status %d: %s", resp.StatusCode, truncate(body, maxLoggedBodySize))
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, &Error{Kind: ErrNotFound, StatusCode: resp.StatusCode}
+		case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+			return nil, &Error{Kind: ErrUnavailable, StatusCode: resp.StatusCode}
+		default:
+			return nil, &Error{Kind: ErrBadResponse, StatusCode: resp.StatusCode}
+		}
+	}
+
+	var songDetail entity.SongDetail
+	if err := json.Unmarshal(body, &songDetail); err != nil {
+		return nil, &Error{Kind: ErrBadResponse, StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to decode response: %w", err)}
+	}
+
+	return &songDetail, nil
+}
+
+func (c *SongInfoClient) requestError(ctx, attemptCtx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return &Error{Kind: ErrTimeout, Err: err}
+	}
+
+	return &Error{Kind: ErrUnavailable, Err: err}
+}
+
+// backoff returns the delay before the retry following the given attempt:
+// half of the exponential delay plus a random share of the other half.
+func (c *SongInfoClient) backoff(attempt int) time.Duration {
+	delay := c.cfg.RetryBackoff
+	for i := 1; i < attempt && (c.cfg.RetryMaxBackoff <= 0 || delay < c.cfg.RetryMaxBackoff); i++ {
+		delay *= 2
+	}
+	if c.cfg.RetryMaxBackoff > 0 && delay > c.cfg.RetryMaxBackoff {
+		delay = c.cfg.RetryMaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+func retryable(err *Error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrTimeout)
+}
+
+func truncate(body []byte, size int) string {
+	if len(body) > size {
+		return string(body[:size]) + "..."
+	}
+
+	return string(body)
+}
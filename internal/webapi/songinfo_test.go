@@ -0,0 +1,274 @@
+package webapi_test
+
+import (
+	"context"
+	"effective_mobile_tz/internal/webapi"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAPI serves the handler for the attempts made, counting them, and returns
+// a client of it.
+func newAPI(t *testing.T, cfg webapi.Config, handler func(w http.ResponseWriter, r *http.Request, attempt int32)) (*webapi.SongInfoClient, *atomic.Int32) {
+	t.Helper()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, attempts.Add(1))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg.URL = server.URL
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = time.Millisecond
+	}
+
+	return webapi.NewSongInfoClient(cfg), &attempts
+}
+
+func respondDetail(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"releaseDate": "16.07.2006", "text": "First line", "link": "https://example.com"}`))
+}
+
+func TestGetSongDetail(t *testing.T) {
+	client, attempts := newAPI(t, webapi.Config{}, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		if r.URL.Path != "/info" || r.URL.Query().Get("group") != "Muse" || r.URL.Query().Get("song") != "Supermassive Black Hole" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		respondDetail(w)
+	})
+
+	detail, err := client.GetSongDetail(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.ReleaseDate != "16.07.2006" || detail.Text != "First line" || detail.Link != "https://example.com" {
+		t.Errorf("got detail %+v", detail)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("made %d attempts, want 1", got)
+	}
+}
+
+func TestGetSongDetailRetries(t *testing.T) {
+	client, attempts := newAPI(t, webapi.Config{MaxRetries: 2}, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			respondDetail(w)
+		}
+	})
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("made %d attempts, want 3", got)
+	}
+}
+
+func TestGetSongDetailErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		status   int
+		body     string
+		want     error
+		attempts int32
+	}{
+		{name: "not found", status: http.StatusNotFound, want: webapi.ErrNotFound, attempts: 1},
+		{name: "bad request", status: http.StatusBadRequest, want: webapi.ErrBadResponse, attempts: 1},
+		{name: "bad body", status: http.StatusOK, body: "not json", want: webapi.ErrBadResponse, attempts: 1},
+		{name: "server error", status: http.StatusInternalServerError, want: webapi.ErrUnavailable, attempts: 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client, attempts := newAPI(t, webapi.Config{MaxRetries: 2}, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			})
+
+			_, err := client.GetSongDetail(context.Background(), "Group", "Song")
+			if !errors.Is(err, test.want) {
+				t.Fatalf("got error %v, want %v", err, test.want)
+			}
+			var apiErr *webapi.Error
+			if !errors.As(err, &apiErr) || apiErr.Attempts != int(test.attempts) {
+				t.Errorf("got error %#v, want it to count %d attempts", err, test.attempts)
+			}
+			if got := attempts.Load(); got != test.attempts {
+				t.Errorf("made %d attempts, want %d", got, test.attempts)
+			}
+		})
+	}
+}
+
+func TestGetSongDetailTimeout(t *testing.T) {
+	client, attempts := newAPI(t, webapi.Config{Timeout: 20 * time.Millisecond, MaxRetries: 1}, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		if attempt == 1 {
+			<-r.Context().Done()
+			return
+		}
+		respondDetail(w)
+	})
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); err != nil {
+		t.Fatalf("got error %v, want the retry after the timeout to succeed", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("made %d attempts, want 2", got)
+	}
+}
+
+func TestGetSongDetailCancelled(t *testing.T) {
+	client, _ := newAPI(t, webapi.Config{MaxRetries: 5, RetryBackoff: time.Hour}, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the caller stops waiting for the retry
+	if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	cfg := webapi.Config{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond}
+	client, attempts := newAPI(t, cfg, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		respondDetail(w)
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, webapi.ErrUnavailable) {
+			t.Fatalf("got error %v, want %v", err, webapi.ErrUnavailable)
+		}
+	}
+	if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, webapi.ErrCircuitOpen) {
+		t.Fatalf("got error %v with the circuit open, want %v", err, webapi.ErrCircuitOpen)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("made %d attempts, want none with the circuit open", got)
+	}
+
+	// the failed probe reopens the circuit
+	time.Sleep(cfg.BreakerCooldown)
+	if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, webapi.ErrUnavailable) {
+		t.Fatalf("got error %v from the probe, want %v", err, webapi.ErrUnavailable)
+	}
+	if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, webapi.ErrCircuitOpen) {
+		t.Fatalf("got error %v after the failed probe, want %v", err, webapi.ErrCircuitOpen)
+	}
+
+	// the successful one closes it
+	healthy.Store(true)
+	time.Sleep(cfg.BreakerCooldown)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetSongDetail(ctx, "Group", "Song"); err != nil {
+			t.Fatalf("got error %v with the api back, want none", err)
+		}
+	}
+}
+
+// TestCircuitBreakerCancelledProbe gives up on the probe: another request
+// probes the api in its place.
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	cfg := webapi.Config{BreakerThreshold: 1, BreakerCooldown: 20 * time.Millisecond}
+	client, _ := newAPI(t, cfg, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			<-r.Context().Done()
+		default:
+			respondDetail(w)
+		}
+	})
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); !errors.Is(err, webapi.ErrUnavailable) {
+		t.Fatalf("got error %v, want %v", err, webapi.ErrUnavailable)
+	}
+	time.Sleep(cfg.BreakerCooldown)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetSongDetail(ctx, "Group", "Song"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v from the probe, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); err != nil {
+		t.Errorf("got error %v after the probe was given up, want another probe to succeed", err)
+	}
+}
+
+// TestCircuitBreakerStaleRequest gives up on a request sent before the
+// circuit opened while the probe is out: the probe stays the only request
+// let through.
+func TestCircuitBreakerStaleRequest(t *testing.T) {
+	cfg := webapi.Config{BreakerThreshold: 1, BreakerCooldown: 20 * time.Millisecond}
+	started := make(chan int32, 4)
+	unblock := make(chan struct{})
+	client, _ := newAPI(t, cfg, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		started <- attempt
+		switch attempt {
+		case 1:
+			<-r.Context().Done()
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			<-unblock
+			respondDetail(w)
+		default:
+			respondDetail(w)
+		}
+	})
+
+	staleCtx, cancelStale := context.WithCancel(context.Background())
+	staleDone := make(chan error, 1)
+	go func() {
+		_, err := client.GetSongDetail(staleCtx, "Group", "Song")
+		staleDone <- err
+	}()
+	<-started
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); !errors.Is(err, webapi.ErrUnavailable) {
+		t.Fatalf("got error %v, want %v", err, webapi.ErrUnavailable)
+	}
+	<-started
+	time.Sleep(cfg.BreakerCooldown)
+
+	probeDone := make(chan error, 1)
+	go func() {
+		_, err := client.GetSongDetail(context.Background(), "Group", "Song")
+		probeDone <- err
+	}()
+	<-started
+
+	cancelStale()
+	if err := <-staleDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v from the stale request, want %v", err, context.Canceled)
+	}
+
+	if _, err := client.GetSongDetail(context.Background(), "Group", "Song"); !errors.Is(err, webapi.ErrCircuitOpen) {
+		t.Errorf("got error %v while the probe is out, want %v", err, webapi.ErrCircuitOpen)
+	}
+
+	close(unblock)
+	if err := <-probeDone; err != nil {
+		t.Errorf("got error %v from the probe, want none", err)
+	}
+}
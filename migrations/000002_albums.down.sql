@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS songs_album_id_idx;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS disc_number,
+    DROP COLUMN IF EXISTS track_number,
+    DROP COLUMN IF EXISTS album_id;
+
+DROP TABLE IF EXISTS albums;
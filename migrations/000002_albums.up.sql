@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS albums (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        title TEXT NOT NULL,
+                        group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+                        release_date DATE,
+                        album_type VARCHAR(32) NOT NULL DEFAULT 'album',
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        UNIQUE(title, group_id)
+);
+
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS album_id UUID REFERENCES albums(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS track_number INTEGER,
+    ADD COLUMN IF NOT EXISTS disc_number INTEGER;
+
+CREATE INDEX IF NOT EXISTS songs_album_id_idx ON songs(album_id);
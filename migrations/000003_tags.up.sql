@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tags (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        name VARCHAR(255) NOT NULL UNIQUE,
+                        category VARCHAR(32) NOT NULL DEFAULT 'tag',
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS song_tags (
+                        song_id UUID NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                        tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        PRIMARY KEY (song_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS song_tags_tag_id_idx ON song_tags(tag_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS playlists (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        name VARCHAR(255) NOT NULL,
+                        description TEXT,
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS playlist_entries (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        playlist_id UUID NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+                        song_id UUID NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                        position INTEGER NOT NULL CHECK (position > 0),
+                        note TEXT,
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        CONSTRAINT playlist_entries_position_key UNIQUE (playlist_id, position) DEFERRABLE INITIALLY IMMEDIATE
+);
+
+CREATE INDEX IF NOT EXISTS playlist_entries_song_id_idx ON playlist_entries(song_id);
@@ -0,0 +1,17 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done'
+    CHECK (enrichment_status IN ('pending', 'done', 'failed'));
+
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        song_id UUID NOT NULL UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+                        status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+                        attempts INTEGER NOT NULL DEFAULT 0,
+                        run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                        locked_until TIMESTAMP,
+                        claim_token UUID,
+                        last_error TEXT,
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS enrichment_jobs_due_idx ON enrichment_jobs(run_at) WHERE status IN ('pending', 'running');
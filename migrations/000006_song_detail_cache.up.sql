@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS song_detail_cache (
+                        cache_key TEXT PRIMARY KEY,
+                        group_name TEXT NOT NULL,
+                        title TEXT NOT NULL,
+                        found BOOLEAN NOT NULL,
+                        release_date TEXT,
+                        text TEXT,
+                        link TEXT,
+                        expires_at TIMESTAMP,
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS song_detail_cache_expires_at_idx ON song_detail_cache(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS release_date_precision VARCHAR(8) NOT NULL DEFAULT 'day'
+    CHECK (release_date_precision IN ('day', 'month', 'year'));
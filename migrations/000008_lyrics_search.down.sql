@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS lyrics_verses_search_vector ON lyrics_verses;
+DROP FUNCTION IF EXISTS lyrics_verses_search_vector();
+ALTER TABLE lyrics_verses DROP COLUMN IF EXISTS search_vector;
+DROP TABLE IF EXISTS search_settings;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS search_settings (
+                        id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+                        language REGCONFIG NOT NULL DEFAULT 'simple'
+);
+
+INSERT INTO search_settings DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+ALTER TABLE lyrics_verses ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION lyrics_verses_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := to_tsvector((SELECT language FROM search_settings), NEW.verse);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS lyrics_verses_search_vector ON lyrics_verses;
+CREATE TRIGGER lyrics_verses_search_vector BEFORE INSERT OR UPDATE OF verse ON lyrics_verses
+    FOR EACH ROW EXECUTE FUNCTION lyrics_verses_search_vector();
+
+UPDATE lyrics_verses SET search_vector = to_tsvector((SELECT language FROM search_settings), verse);
+
+CREATE INDEX IF NOT EXISTS lyrics_verses_search_vector_idx ON lyrics_verses USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS groups_name_trgm_idx;
+DROP INDEX IF EXISTS songs_title_trgm_idx;
+DROP EXTENSION IF EXISTS pg_trgm;
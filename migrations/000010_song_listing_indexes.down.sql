@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS songs_created_at_idx;
+DROP INDEX IF EXISTS lyrics_verses_song_id_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS lyrics_verses_song_id_idx ON lyrics_verses(song_id, verse_number);
+CREATE INDEX IF NOT EXISTS songs_created_at_idx ON songs(created_at, id);
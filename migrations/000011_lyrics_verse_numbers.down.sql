@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS lyrics_verses_verse_numbers_contiguous ON lyrics_verses;
+DROP FUNCTION IF EXISTS lyrics_verses_check_numbers();
+ALTER TABLE lyrics_verses DROP CONSTRAINT IF EXISTS lyrics_verses_verse_number_key;
+ALTER TABLE lyrics_verses DROP CONSTRAINT IF EXISTS lyrics_verses_verse_number_check;
+CREATE INDEX IF NOT EXISTS lyrics_verses_song_id_idx ON lyrics_verses(song_id, verse_number);
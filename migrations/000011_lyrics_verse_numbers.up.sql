@@ -0,0 +1,50 @@
+-- verses are renumbered 1, 2, 3... within each song, keeping their order
+UPDATE lyrics_verses lv SET verse_number = ranked.new_number
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY verse_number, created_at, id) AS new_number
+    FROM lyrics_verses
+) ranked
+WHERE lv.id = ranked.id AND lv.verse_number <> ranked.new_number;
+
+ALTER TABLE lyrics_verses ADD CONSTRAINT lyrics_verses_verse_number_check CHECK (verse_number > 0);
+ALTER TABLE lyrics_verses ADD CONSTRAINT lyrics_verses_verse_number_key
+    UNIQUE (song_id, verse_number) DEFERRABLE INITIALLY IMMEDIATE;
+
+-- the unique constraint indexes (song_id, verse_number) already
+DROP INDEX IF EXISTS lyrics_verses_song_id_idx;
+
+-- with unique positive numbers, a song has verses 1 to n when it has n of them
+CREATE OR REPLACE FUNCTION lyrics_verses_check_numbers() RETURNS TRIGGER AS $$
+DECLARE
+    checked_song_id UUID;
+    verses_count INTEGER;
+    last_number INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        checked_song_id := OLD.song_id;
+    ELSE
+        checked_song_id := NEW.song_id;
+    END IF;
+
+    IF checked_song_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    SELECT COUNT(*), COALESCE(MAX(verse_number), 0) INTO verses_count, last_number
+    FROM lyrics_verses WHERE song_id = checked_song_id;
+
+    IF verses_count <> last_number THEN
+        RAISE EXCEPTION 'verse numbers of song % are not contiguous', checked_song_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'lyrics_verses_verse_numbers_contiguous';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- checked at commit, so that verses can be renumbered over several statements
+DROP TRIGGER IF EXISTS lyrics_verses_verse_numbers_contiguous ON lyrics_verses;
+CREATE CONSTRAINT TRIGGER lyrics_verses_verse_numbers_contiguous
+    AFTER INSERT OR UPDATE OF song_id, verse_number OR DELETE ON lyrics_verses
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION lyrics_verses_check_numbers();
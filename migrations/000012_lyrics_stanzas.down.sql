@@ -0,0 +1,36 @@
+ALTER TABLE lyrics_verses DISABLE TRIGGER lyrics_verses_verse_numbers_contiguous;
+
+-- a blank row goes back between stanzas, and a label row such as [Chorus] at
+-- the head of labelled ones
+CREATE TEMPORARY TABLE stanza_offsets AS
+SELECT id, song_id, stanza_number, label, first_line,
+       stanza_number - 1 + COUNT(label) OVER (PARTITION BY song_id ORDER BY stanza_number) AS line_offset
+FROM (
+    SELECT ls.id, ls.song_id, ls.stanza_number, ls.label, MIN(lv.verse_number) AS first_line
+    FROM lyrics_stanzas ls
+    JOIN lyrics_verses lv ON lv.stanza_id = ls.id
+    GROUP BY ls.id
+) stanzas;
+
+UPDATE lyrics_verses lv SET verse_number = lv.verse_number + so.line_offset
+FROM stanza_offsets so
+WHERE lv.stanza_id = so.id AND so.line_offset > 0;
+
+INSERT INTO lyrics_verses (song_id, verse, verse_number)
+SELECT song_id, '[' || label || ']', first_line + line_offset - 1
+FROM stanza_offsets
+WHERE label IS NOT NULL;
+
+INSERT INTO lyrics_verses (song_id, verse, verse_number)
+SELECT song_id, '', first_line + line_offset - 1 - (label IS NOT NULL)::int
+FROM stanza_offsets
+WHERE stanza_number > 1;
+
+DROP TABLE stanza_offsets;
+
+ALTER TABLE lyrics_verses DROP COLUMN IF EXISTS stanza_id;
+ALTER TABLE lyrics_verses ENABLE TRIGGER lyrics_verses_verse_numbers_contiguous;
+
+DROP TRIGGER IF EXISTS lyrics_stanzas_stanza_numbers_contiguous ON lyrics_stanzas;
+DROP FUNCTION IF EXISTS lyrics_stanzas_check_numbers();
+DROP TABLE IF EXISTS lyrics_stanzas;
@@ -0,0 +1,95 @@
+CREATE TABLE IF NOT EXISTS lyrics_stanzas (
+                        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        song_id UUID NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                        stanza_number INTEGER NOT NULL CHECK (stanza_number > 0),
+                        label VARCHAR(64),
+                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                        CONSTRAINT lyrics_stanzas_stanza_number_key UNIQUE (song_id, stanza_number) DEFERRABLE INITIALLY IMMEDIATE
+);
+
+ALTER TABLE lyrics_verses ADD COLUMN IF NOT EXISTS stanza_id UUID REFERENCES lyrics_stanzas(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS lyrics_verses_stanza_id_idx ON lyrics_verses(stanza_id);
+
+-- the lines are renumbered below at once, and a pending check would prevent
+-- altering the table afterwards
+ALTER TABLE lyrics_verses DISABLE TRIGGER lyrics_verses_verse_numbers_contiguous;
+
+-- the rows between blank rows of a song make a stanza, the blank rows go and
+-- the remaining lines are renumbered 1, 2, 3...
+CREATE TEMPORARY TABLE lyrics_lines AS
+SELECT id, song_id, verse, verse_number,
+       DENSE_RANK() OVER (PARTITION BY song_id ORDER BY blanks_before) AS stanza_number,
+       ROW_NUMBER() OVER (PARTITION BY song_id, blanks_before ORDER BY verse_number) AS stanza_line,
+       COUNT(*) OVER (PARTITION BY song_id, blanks_before) AS stanza_lines
+FROM (
+    SELECT id, song_id, verse, verse_number,
+           COUNT(*) FILTER (WHERE verse ~ '^\s*$') OVER (PARTITION BY song_id ORDER BY verse_number) AS blanks_before
+    FROM lyrics_verses
+    WHERE song_id IS NOT NULL
+) counted
+WHERE verse !~ '^\s*$';
+
+-- a first row in brackets, such as [Chorus], labels a stanza of more rows and
+-- goes too
+CREATE TEMPORARY TABLE lyrics_labels AS
+SELECT id, song_id, stanza_number, label
+FROM (
+    SELECT id, song_id, stanza_number, regexp_replace(verse, '^\s*\[\s*|\s*\]\s*$', '', 'g') AS label
+    FROM lyrics_lines
+    WHERE stanza_line = 1 AND stanza_lines > 1 AND verse ~ '^\s*\[[^][]+\]\s*$'
+) labelled
+WHERE label <> '' AND char_length(label) <= 64;
+
+DELETE FROM lyrics_lines ll USING lyrics_labels lb WHERE ll.id = lb.id;
+
+INSERT INTO lyrics_stanzas (song_id, stanza_number, label)
+SELECT DISTINCT ll.song_id, ll.stanza_number, lb.label
+FROM lyrics_lines ll
+LEFT JOIN lyrics_labels lb ON lb.song_id = ll.song_id AND lb.stanza_number = ll.stanza_number;
+
+DELETE FROM lyrics_verses lv WHERE NOT EXISTS (SELECT 1 FROM lyrics_lines ll WHERE ll.id = lv.id);
+
+UPDATE lyrics_verses lv SET stanza_id = ls.id, verse_number = ll.line_number
+FROM (
+    SELECT id, song_id, stanza_number, ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY verse_number) AS line_number
+    FROM lyrics_lines
+) ll
+JOIN lyrics_stanzas ls ON ls.song_id = ll.song_id AND ls.stanza_number = ll.stanza_number
+WHERE lv.id = ll.id;
+
+DROP TABLE lyrics_labels;
+DROP TABLE lyrics_lines;
+
+ALTER TABLE lyrics_verses ALTER COLUMN stanza_id SET NOT NULL;
+ALTER TABLE lyrics_verses ENABLE TRIGGER lyrics_verses_verse_numbers_contiguous;
+
+-- stanzas are numbered like lines: 1 to n for a song with n of them
+CREATE OR REPLACE FUNCTION lyrics_stanzas_check_numbers() RETURNS TRIGGER AS $$
+DECLARE
+    checked_song_id UUID;
+    stanzas_count INTEGER;
+    last_number INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        checked_song_id := OLD.song_id;
+    ELSE
+        checked_song_id := NEW.song_id;
+    END IF;
+
+    SELECT COUNT(*), COALESCE(MAX(stanza_number), 0) INTO stanzas_count, last_number
+    FROM lyrics_stanzas WHERE song_id = checked_song_id;
+
+    IF stanzas_count <> last_number THEN
+        RAISE EXCEPTION 'stanza numbers of song % are not contiguous', checked_song_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'lyrics_stanzas_stanza_numbers_contiguous';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS lyrics_stanzas_stanza_numbers_contiguous ON lyrics_stanzas;
+CREATE CONSTRAINT TRIGGER lyrics_stanzas_stanza_numbers_contiguous
+    AFTER INSERT OR UPDATE OF song_id, stanza_number OR DELETE ON lyrics_stanzas
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION lyrics_stanzas_check_numbers();
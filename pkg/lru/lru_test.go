@@ -0,0 +1,119 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEvictionOrder fills the cache past its capacity: the least recently
+// used entry is evicted, reading or replacing an entry making it the most
+// recently used.
+func TestEvictionOrder(t *testing.T) {
+	cache := New[string, int](3)
+	cache.Add("a", 1, 0)
+	cache.Add("b", 2, 0)
+	cache.Add("c", 3, 0)
+
+	// reading a leaves b the least recently used, then replacing c leaves a
+	cache.Get("a")
+	cache.Add("d", 4, 0)
+	cache.Add("c", 30, 0)
+	cache.Add("e", 5, 0)
+
+	for key, want := range map[string]int{"c": 30, "d": 4, "e": 5} {
+		if got, ok := cache.Get(key); !ok || got != want {
+			t.Errorf("Get(%q) = %d, %t, want %d, true", key, got, ok, want)
+		}
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("Get(%q) found the entry, want it evicted", key)
+		}
+	}
+	if got := cache.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	if got := cache.Evictions(); got != 2 {
+		t.Errorf("Evictions() = %d, want 2", got)
+	}
+}
+
+// TestExpiry keeps entries for their own TTL, and for ever without one.
+func TestExpiry(t *testing.T) {
+	cache := New[string, int](10)
+	cache.Add("short", 1, 20*time.Millisecond)
+	cache.Add("long", 2, time.Hour)
+	cache.Add("forever", 3, 0)
+
+	if _, ok := cache.Get("short"); !ok {
+		t.Error("Get(\"short\") missed the entry before it expired")
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Get(\"short\") found the entry after it expired")
+	}
+	for _, key := range []string{"long", "forever"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Get(%q) missed the entry before it expired", key)
+		}
+	}
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Len() = %d, want the expired entry dropped once looked up", got)
+	}
+
+	// replacing an entry replaces its TTL too
+	cache.Add("long", 2, 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Get("long"); ok {
+		t.Error("Get(\"long\") found the entry after its new TTL")
+	}
+}
+
+// TestEvictionsSkipExpired counts only the live entries evicted to make room.
+func TestEvictionsSkipExpired(t *testing.T) {
+	cache := New[string, int](2)
+	cache.Add("expiring", 1, 20*time.Millisecond)
+	cache.Add("live", 2, 0)
+	time.Sleep(40 * time.Millisecond)
+
+	cache.Add("new", 3, 0)
+	if got := cache.Evictions(); got != 0 {
+		t.Errorf("Evictions() = %d after dropping an expired entry, want 0", got)
+	}
+
+	cache.Add("newer", 4, 0)
+	if got := cache.Evictions(); got != 1 {
+		t.Errorf("Evictions() = %d after evicting a live entry, want 1", got)
+	}
+	if _, ok := cache.Get("live"); ok {
+		t.Error("Get(\"live\") found the least recently used entry, want it evicted")
+	}
+}
+
+func TestRemoveAndPurge(t *testing.T) {
+	cache := New[string, int](0)
+	if got := cache.Capacity(); got != 1 {
+		t.Errorf("Capacity() = %d, want a capacity below 1 raised to 1", got)
+	}
+
+	cache = New[string, int](3)
+	cache.Add("a", 1, 0)
+	cache.Add("b", 2, 0)
+
+	if !cache.Remove("a") {
+		t.Error("Remove(\"a\") = false, want true")
+	}
+	if cache.Remove("a") {
+		t.Error("Remove(\"a\") = true for a removed entry, want false")
+	}
+	if got := cache.Purge(); got != 1 {
+		t.Errorf("Purge() = %d, want 1", got)
+	}
+	if got := cache.Len(); got != 0 {
+		t.Errorf("Len() = %d after purging, want 0", got)
+	}
+	if got := cache.Evictions(); got != 0 {
+		t.Errorf("Evictions() = %d, want removed and purged entries left out", got)
+	}
+}
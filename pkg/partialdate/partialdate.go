@@ -0,0 +1,123 @@
+// Package partialdate parses dates that may be known to the day, the month or
+// only the year, as release dates often are.
+package partialdate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Precision string
+
+const (
+	Day   Precision = "day"
+	Month Precision = "month"
+	Year  Precision = "year"
+)
+
+// Valid reports whether p is one of the known precisions.
+func (p Precision) Valid() bool {
+	return p == Day || p == Month || p == Year
+}
+
+// Date is the first day of the period it stands for: the day itself, the
+// first of the month or the first of January.
+type Date struct {
+	Time      time.Time
+	Precision Precision
+}
+
+type layout struct {
+	value     string
+	precision Precision
+}
+
+// layouts are tried in order. Numeric day-first dates are read the European
+// way, so 02/01/2006 is the 2nd of January.
+var layouts = []layout{
+	{"02.01.2006", Day},
+	{"2.1.2006", Day},
+	{"2006-01-02", Day},
+	{"2006-1-2", Day},
+	{"02/01/2006", Day},
+	{"2/1/2006", Day},
+	{"02-01-2006", Day},
+	{"2006/01/02", Day},
+	{"2006.01.02", Day},
+	{"20060102", Day},
+	{time.RFC3339Nano, Day},
+	{"2006-01-02T15:04:05", Day},
+	{"2006-01-02T15:04", Day},
+	{"2006-01-02 15:04:05", Day},
+	{"2 January 2006", Day},
+	{"2 Jan 2006", Day},
+	{"January 2, 2006", Day},
+	{"Jan 2, 2006", Day},
+	{"January 2 2006", Day},
+	{"Jan 2 2006", Day},
+	{"2006-01", Month},
+	{"2006/01", Month},
+	{"01.2006", Month},
+	{"1.2006", Month},
+	{"01/2006", Month},
+	{"1/2006", Month},
+	{"January 2006", Month},
+	{"Jan 2006", Month},
+	{"2006", Year},
+}
+
+// Parse reads value in any of the supported layouts: DD.MM.YYYY, ISO 8601
+// dates and date-times, YYYY-MM, YYYY and their common variants.
+func Parse(value string) (Date, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	for _, l := range layouts {
+		t, err := time.Parse(l.value, value)
+		if err != nil {
+			continue
+		}
+
+		return New(t, l.precision), nil
+	}
+
+	return Date{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// New returns the date of t truncated to the precision, in UTC.
+func New(t time.Time, precision Precision) Date {
+	year, month, day := t.Date()
+	switch precision {
+	case Year:
+		month, day = time.January, 1
+	case Month:
+		day = 1
+	default:
+		precision = Day
+	}
+
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Precision: precision}
+}
+
+// End returns the first day after the period of the date.
+func (d Date) End() time.Time {
+	switch d.Precision {
+	case Year:
+		return d.Time.AddDate(1, 0, 0)
+	case Month:
+		return d.Time.AddDate(0, 1, 0)
+	}
+
+	return d.Time.AddDate(0, 0, 1)
+}
+
+// String formats the date as far as it is known: 2006-01-02, 2006-01 or 2006.
+func (d Date) String() string {
+	switch d.Precision {
+	case Year:
+		return d.Time.Format("2006")
+	case Month:
+		return d.Time.Format("2006-01")
+	}
+
+	return d.Time.Format(time.DateOnly)
+}
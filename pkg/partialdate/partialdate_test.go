@@ -0,0 +1,121 @@
+package partialdate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for value, want := range map[string]string{
+		"02.01.2006":                "2006-01-02",
+		"2.1.2006":                  "2006-01-02",
+		"2006-01-02":                "2006-01-02",
+		"2006-1-2":                  "2006-01-02",
+		"02/01/2006":                "2006-01-02",
+		"2/1/2006":                  "2006-01-02",
+		"02-01-2006":                "2006-01-02",
+		"2006/01/02":                "2006-01-02",
+		"2006.01.02":                "2006-01-02",
+		"20060102":                  "2006-01-02",
+		"2006-01-02T15:04:05Z":      "2006-01-02",
+		"2006-01-02T15:04:05.5Z":    "2006-01-02",
+		"2006-01-02T23:04:05-07:00": "2006-01-02",
+		"2006-01-02T15:04:05":       "2006-01-02",
+		"2006-01-02T15:04":          "2006-01-02",
+		"2006-01-02 15:04:05":       "2006-01-02",
+		"2 January 2006":            "2006-01-02",
+		"2 Jan 2006":                "2006-01-02",
+		"January 2, 2006":           "2006-01-02",
+		"Jan 2, 2006":               "2006-01-02",
+		"January 2 2006":            "2006-01-02",
+		"Jan 2 2006":                "2006-01-02",
+		"  Jan   2,  2006 ":         "2006-01-02",
+		"2006-01":                   "2006-01",
+		"2006/01":                   "2006-01",
+		"01.2006":                   "2006-01",
+		"1.2006":                    "2006-01",
+		"01/2006":                   "2006-01",
+		"1/2006":                    "2006-01",
+		"January 2006":              "2006-01",
+		"Jan 2006":                  "2006-01",
+		"2006":                      "2006",
+		// numeric dates are read day first, the European way
+		"03/04/2006": "2006-04-03",
+		"12.11.2006": "2006-11-12",
+		"13/01/2006": "2006-01-13",
+	} {
+		date, err := Parse(value)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", value, err)
+			continue
+		}
+		if got := date.String(); got != want {
+			t.Errorf("Parse(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+// TestParseInvalid rejects dates out of range, 01/13/2006 among them, which
+// only the US way of reading dates makes sense of.
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{"", "yesterday", "01/13/2006", "2006-13", "32.01.2006", "2006-02-30", "06", "2006-01-02T25:00"} {
+		if date, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", value, date)
+		}
+	}
+}
+
+// TestNew truncates a time to the first day of its period, in UTC, and reads
+// an unknown precision as a day.
+func TestNew(t *testing.T) {
+	moment := time.Date(2006, time.March, 15, 23, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	for _, test := range []struct {
+		precision Precision
+		want      Date
+	}{
+		{Day, Date{time.Date(2006, time.March, 15, 0, 0, 0, 0, time.UTC), Day}},
+		{Month, Date{time.Date(2006, time.March, 1, 0, 0, 0, 0, time.UTC), Month}},
+		{Year, Date{time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC), Year}},
+		{"", Date{time.Date(2006, time.March, 15, 0, 0, 0, 0, time.UTC), Day}},
+		{"week", Date{time.Date(2006, time.March, 15, 0, 0, 0, 0, time.UTC), Day}},
+	} {
+		if got := New(moment, test.precision); got != test.want {
+			t.Errorf("New(%s, %q) = %v, want %v", moment, test.precision, got, test.want)
+		}
+	}
+}
+
+func TestEnd(t *testing.T) {
+	for value, want := range map[string]string{
+		"2006-02-28": "2006-03-01",
+		"2006-12-31": "2007-01-01",
+		"2006-02":    "2006-03-01",
+		"2006-12":    "2007-01-01",
+		"2006":       "2007-01-01",
+		"2008-02-29": "2008-03-01",
+	} {
+		date, err := Parse(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := date.End().Format(time.DateOnly); got != want {
+			t.Errorf("Parse(%q).End() = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	day := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		date Date
+		want string
+	}{
+		{Date{day, Day}, "2006-01-02"},
+		{Date{day, Month}, "2006-01"},
+		{Date{day, Year}, "2006"},
+	} {
+		if got := test.date.String(); got != test.want {
+			t.Errorf("%+v.String() = %s, want %s", test.date, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package playlistfmt writes lists of tracks in playlist formats understood by media players.
+package playlistfmt
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+type Format string
+
+const (
+	M3U8 Format = "m3u8"
+	XSPF Format = "xspf"
+	PLS  Format = "pls"
+)
+
+type Track struct {
+	Title    string
+	Creator  string
+	Album    string
+	Location string
+}
+
+var contentTypes = map[Format]string{
+	M3U8: "application/vnd.apple.mpegurl",
+	XSPF: "application/xspf+xml",
+	PLS:  "audio/x-scpls",
+}
+
+var mediaTypes = map[string]Format{
+	"application/vnd.apple.mpegurl": M3U8,
+	"application/x-mpegurl":         M3U8,
+	"audio/mpegurl":                 M3U8,
+	"audio/x-mpegurl":               M3U8,
+	"application/xspf+xml":          XSPF,
+	"audio/x-scpls":                 PLS,
+	"audio/scpls":                   PLS,
+}
+
+// ParseFormat resolves a format name such as "m3u8", "m3u", "xspf" or "pls".
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "m3u8", "m3u":
+		return M3U8, nil
+	case "xspf":
+		return XSPF, nil
+	case "pls":
+		return PLS, nil
+	}
+
+	return "", fmt.Errorf("unsupported playlist format %q", name)
+}
+
+// Negotiate picks the first format listed in an Accept header.
+func Negotiate(accept string) (Format, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if format, ok := mediaTypes[mediaType]; ok {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Encode writes the tracks to w. Tracks without a location cannot be played
+// and are skipped.
+func Encode(w io.Writer, format Format, title string, tracks []Track) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case M3U8:
+		err = encodeM3U8(bw, title, tracks)
+	case XSPF:
+		err = encodeXSPF(bw, title, tracks)
+	case PLS:
+		err = encodePLS(bw, tracks)
+	default:
+		return fmt.Errorf("unsupported playlist format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func encodeM3U8(w io.Writer, title string, tracks []Track) error {
+	if _, err := fmt.Fprintf(w, "#EXTM3U\n#PLAYLIST:%s\n", singleLine(title)); err != nil {
+		return err
+	}
+
+	for _, track := range tracks {
+		if track.Location == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "#EXTINF:-1,%s\n%s\n", singleLine(displayName(track)), singleLine(track.Location)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type xspfTrack struct {
+	XMLName  xml.Name `xml:"track"`
+	Location string   `xml:"location"`
+	Title    string   `xml:"title,omitempty"`
+	Creator  string   `xml:"creator,omitempty"`
+	Album    string   `xml:"album,omitempty"`
+}
+
+func encodeXSPF(w io.Writer, title string, tracks []Track) error {
+	if _, err := io.WriteString(w, xml.Header+`<playlist version="1" xmlns="http://xspf.org/ns/0/">`+"\n"); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "  <title>"); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(w, []byte(title)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "</title>\n  <trackList>\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("    ", "  ")
+	for _, track := range tracks {
+		if track.Location == "" {
+			continue
+		}
+		err := encoder.Encode(xspfTrack{
+			Location: track.Location,
+			Title:    track.Title,
+			Creator:  track.Creator,
+			Album:    track.Album,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "  </trackList>\n</playlist>\n")
+	return err
+}
+
+func encodePLS(w io.Writer, tracks []Track) error {
+	if _, err := io.WriteString(w, "[playlist]\n"); err != nil {
+		return err
+	}
+
+	number := 0
+	for _, track := range tracks {
+		if track.Location == "" {
+			continue
+		}
+		number++
+		_, err := fmt.Fprintf(w, "File%d=%s\nTitle%d=%s\nLength%d=-1\n",
+			number, singleLine(track.Location),
+			number, singleLine(displayName(track)),
+			number)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", number)
+	return err
+}
+
+func displayName(track Track) string {
+	if track.Creator == "" {
+		return track.Title
+	}
+	return track.Creator + " - " + track.Title
+}
+
+// singleLine keeps line-based formats intact when values contain line breaks.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\r", " ")), " ")
+}
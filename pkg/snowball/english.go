@@ -0,0 +1,307 @@
+// Package snowball reduces English words to their stems with the Snowball
+// English (Porter2) stemmer, which the english_stem dictionary of postgres
+// uses, and tells its stop words apart.
+package snowball
+
+import (
+	"slices"
+)
+
+// exceptions are the words the stemmer does not reduce by its rules.
+var exceptions = map[string]string{
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+	"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli", "singly": "singl",
+	"sky": "sky", "news": "news", "howe": "howe", "atlas": "atlas", "cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// invariants are the words left as they are once their plural is removed.
+var invariants = []string{"inning", "outing", "canning", "herring", "earring", "proceed", "exceed", "succeed"}
+
+// English returns the stem of word, which must be lowercase.
+func English(word string) string {
+	w := []rune(word)
+	if len(w) <= 2 {
+		return word
+	}
+	if stem, ok := exceptions[word]; ok {
+		return stem
+	}
+
+	// a y standing for a consonant is marked Y
+	if w[0] == 'y' {
+		w[0] = 'Y'
+	}
+	for i := 1; i < len(w); i++ {
+		if w[i] == 'y' && isVowel(w[i-1]) {
+			w[i] = 'Y'
+		}
+	}
+	r1, r2 := regions(w)
+
+	w = step1a(w)
+	if slices.Contains(invariants, string(w)) {
+		return string(w)
+	}
+	w = step1b(w, r1)
+	w = step1c(w)
+	w = step2(w, r1)
+	w = step3(w, r1, r2)
+	w = step4(w, r2)
+	w = step5(w, r1, r2)
+
+	for i, r := range w {
+		if r == 'Y' {
+			w[i] = 'y'
+		}
+	}
+
+	return string(w)
+}
+
+func isVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+
+	return false
+}
+
+// regions returns where the regions R1 and R2 of the word begin: R1 after the
+// first non-vowel following a vowel, R2 after the next one within R1.
+func regions(w []rune) (int, int) {
+	r1 := -1
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if hasSuffixAt(w, prefix, 0) {
+			r1 = len([]rune(prefix))
+			break
+		}
+	}
+	if r1 < 0 {
+		r1 = afterVowelConsonant(w, 0)
+	}
+
+	return r1, afterVowelConsonant(w, r1)
+}
+
+func afterVowelConsonant(w []rune, start int) int {
+	for i := start + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+
+	return len(w)
+}
+
+// hasSuffixAt tells whether w holds s at the position start.
+func hasSuffixAt(w []rune, s string, start int) bool {
+	runes := []rune(s)
+	if start < 0 || start+len(runes) > len(w) {
+		return false
+	}
+
+	return slices.Equal(w[start:start+len(runes)], runes)
+}
+
+// longestSuffix returns the longest of suffixes ending w and where it begins.
+func longestSuffix(w []rune, suffixes ...string) (string, int, bool) {
+	var found string
+	for _, suffix := range suffixes {
+		if len(suffix) > len(found) && hasSuffixAt(w, suffix, len(w)-len(suffix)) {
+			found = suffix
+		}
+	}
+
+	return found, len(w) - len(found), found != ""
+}
+
+func replace(w []rune, start int, replacement string) []rune {
+	return append(w[:start:start], []rune(replacement)...)
+}
+
+// endsShortSyllable tells whether w ends with a non-vowel, a vowel and a
+// non-vowel other than w, x or Y, or is a vowel and a non-vowel.
+func endsShortSyllable(w []rune) bool {
+	n := len(w)
+	switch {
+	case n >= 3:
+		return !isVowel(w[n-3]) && isVowel(w[n-2]) && !isVowel(w[n-1]) && w[n-1] != 'w' && w[n-1] != 'x' && w[n-1] != 'Y'
+	case n == 2:
+		return isVowel(w[0]) && !isVowel(w[1])
+	}
+
+	return false
+}
+
+func endsDouble(w []rune) bool {
+	n := len(w)
+	if n < 2 || w[n-1] != w[n-2] {
+		return false
+	}
+
+	switch w[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+
+	return false
+}
+
+func containsVowel(w []rune) bool {
+	return slices.ContainsFunc(w, isVowel)
+}
+
+// step1a removes plurals.
+func step1a(w []rune) []rune {
+	suffix, start, ok := longestSuffix(w, "sses", "ied", "ies", "us", "ss", "s")
+	if !ok {
+		return w
+	}
+
+	switch suffix {
+	case "sses":
+		return replace(w, start, "ss")
+	case "ied", "ies":
+		if start > 1 {
+			return replace(w, start, "i")
+		}
+		return replace(w, start, "ie")
+	case "s":
+		// the s goes when a vowel comes before the letter it follows
+		if containsVowel(w[:start-1]) {
+			return w[:start]
+		}
+	}
+
+	return w
+}
+
+// step1b removes past tenses and gerunds.
+func step1b(w []rune, r1 int) []rune {
+	suffix, start, ok := longestSuffix(w, "eed", "eedly", "ed", "edly", "ing", "ingly")
+	if !ok {
+		return w
+	}
+
+	if suffix == "eed" || suffix == "eedly" {
+		if start >= r1 {
+			return replace(w, start, "ee")
+		}
+		return w
+	}
+
+	if !containsVowel(w[:start]) {
+		return w
+	}
+	w = w[:start]
+	switch {
+	case hasSuffixAt(w, "at", len(w)-2), hasSuffixAt(w, "bl", len(w)-2), hasSuffixAt(w, "iz", len(w)-2):
+		return append(w, 'e')
+	case endsDouble(w):
+		return w[:len(w)-1]
+	case r1 >= len(w) && endsShortSyllable(w):
+		return append(w, 'e')
+	}
+
+	return w
+}
+
+// step1c turns a final y following a non-vowel into i.
+func step1c(w []rune) []rune {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+
+	return w
+}
+
+var step2Suffixes = map[string]string{
+	"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able", "entli": "ent",
+	"izer": "ize", "ization": "ize", "ational": "ate", "ation": "ate", "ator": "ate",
+	"alism": "al", "aliti": "al", "alli": "al", "fulness": "ful", "ousli": "ous", "ousness": "ous",
+	"iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble", "ogi": "og",
+	"fulli": "ful", "lessli": "less", "li": "",
+}
+
+func step2(w []rune, r1 int) []rune {
+	suffix, start, ok := longestSuffix(w, mapKeys(step2Suffixes)...)
+	if !ok || start < r1 {
+		return w
+	}
+
+	switch suffix {
+	case "ogi":
+		if start == 0 || w[start-1] != 'l' {
+			return w
+		}
+	case "li":
+		if start == 0 || !isLiEnding(w[start-1]) {
+			return w
+		}
+	}
+
+	return replace(w, start, step2Suffixes[suffix])
+}
+
+func isLiEnding(r rune) bool {
+	switch r {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	}
+
+	return false
+}
+
+var step3Suffixes = map[string]string{
+	"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic", "iciti": "ic", "ical": "ic",
+	"ful": "", "ness": "", "ative": "",
+}
+
+func step3(w []rune, r1, r2 int) []rune {
+	suffix, start, ok := longestSuffix(w, mapKeys(step3Suffixes)...)
+	if !ok || start < r1 || (suffix == "ative" && start < r2) {
+		return w
+	}
+
+	return replace(w, start, step3Suffixes[suffix])
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+	"ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func step4(w []rune, r2 int) []rune {
+	suffix, start, ok := longestSuffix(w, step4Suffixes...)
+	if !ok || start < r2 {
+		return w
+	}
+	if suffix == "ion" && (start == 0 || (w[start-1] != 's' && w[start-1] != 't')) {
+		return w
+	}
+
+	return w[:start]
+}
+
+func step5(w []rune, r1, r2 int) []rune {
+	last := len(w) - 1
+	switch {
+	case w[last] == 'e' && (last >= r2 || (last >= r1 && !endsShortSyllable(w[:last]))):
+		return w[:last]
+	case w[last] == 'l' && last >= r2 && last > 0 && w[last-1] == 'l':
+		return w[:last]
+	}
+
+	return w
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
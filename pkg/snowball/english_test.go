@@ -0,0 +1,47 @@
+package snowball
+
+import (
+	"testing"
+)
+
+func TestEnglish(t *testing.T) {
+	for word, want := range map[string]string{
+		"caresses":    "caress",
+		"ponies":      "poni",
+		"ties":        "tie",
+		"cats":        "cat",
+		"agreed":      "agre",
+		"plastered":   "plaster",
+		"motoring":    "motor",
+		"sing":        "sing",
+		"hopping":     "hop",
+		"tanned":      "tan",
+		"falling":     "fall",
+		"hissing":     "hiss",
+		"fizzed":      "fizz",
+		"failing":     "fail",
+		"filing":      "file",
+		"happy":       "happi",
+		"running":     "run",
+		"loved":       "love",
+		"loves":       "love",
+		"dogs":        "dog",
+		"generously":  "generous",
+		"communism":   "communism",
+		"relational":  "relat",
+		"hopefulness": "hope",
+		"adjustment":  "adjust",
+		"dying":       "die",
+		"news":        "news",
+		"sky":         "sky",
+		"cry":         "cri",
+		"by":          "by",
+		"yearly":      "year",
+		"succeeds":    "succeed",
+		"night":       "night",
+	} {
+		if got := English(word); got != want {
+			t.Errorf("English(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
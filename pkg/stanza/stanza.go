@@ -0,0 +1,81 @@
+// Package stanza splits lyrics into stanzas, the groups of lines that blank
+// lines set apart, and writes them back as text. A stanza may open with a
+// label line in brackets, such as [Chorus] or [Verse 2].
+package stanza
+
+import (
+	"strings"
+)
+
+// Stanza is a group of lines. Label names the part of the song, and is empty
+// when the stanza has none.
+type Stanza struct {
+	Label string
+	Lines []string
+}
+
+// Parse splits text into stanzas. Any number of blank lines separates two
+// stanzas, and blank lines around the lyrics are dropped. A label line is
+// taken as the label of the stanza it opens, unless it is its only line.
+func Parse(text string) []Stanza {
+	var stanzas []Stanza
+	var lines []string
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		stanza := Stanza{Lines: lines}
+		if label, ok := Label(lines[0]); ok && len(lines) > 1 {
+			stanza = Stanza{Label: label, Lines: lines[1:]}
+		}
+		stanzas = append(stanzas, stanza)
+		lines = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return stanzas
+}
+
+// Format writes the stanzas as Parse reads them: each stanza is its label
+// line, if it has a label, followed by its lines, and a blank line separates
+// two stanzas.
+func Format(stanzas []Stanza) string {
+	var b strings.Builder
+	for ind, stanza := range stanzas {
+		if ind > 0 {
+			b.WriteString("\n\n")
+		}
+		if stanza.Label != "" {
+			b.WriteString("[" + stanza.Label + "]\n")
+		}
+		b.WriteString(strings.Join(stanza.Lines, "\n"))
+	}
+
+	return b.String()
+}
+
+// Label returns the label of a label line such as [Chorus], and whether line
+// is one.
+func Label(line string) (string, bool) {
+	inner, ok := strings.CutPrefix(strings.TrimSpace(line), "[")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, "]")
+	if !ok || strings.ContainsAny(inner, "[]") {
+		return "", false
+	}
+
+	label := strings.TrimSpace(inner)
+	return label, label != ""
+}
@@ -0,0 +1,191 @@
+// Package textquery parses search queries written the way search engines take
+// them: words, "quoted phrases", prefixes ending with *, terms excluded with a
+// leading - and alternatives joined by OR.
+package textquery
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrEmpty means the query has nothing to search for: no words, or only
+// excluded ones.
+var ErrEmpty = errors.New("query has no terms to search for")
+
+// Term is a word, or a phrase of several words that must follow each other.
+// With Prefix the last word matches every word it begins. An empty word
+// matches any word, as a stop word within a phrase does in postgres.
+type Term struct {
+	Words  []string
+	Prefix bool
+}
+
+// Query matches text that matches every clause and none of the excluded
+// terms. A clause matches when any of its terms does.
+type Query struct {
+	Clauses  [][]Term
+	Excluded []Term
+}
+
+// Parse reads the query. Words are lowercased and stripped of punctuation; a
+// word that punctuation splits, such as rock'n'roll, becomes a phrase.
+func Parse(value string) (*Query, error) {
+	query := &Query{}
+	orNext := false
+
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		excluded := false
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			excluded = true
+			i++
+		}
+
+		var raw string
+		quoted := runes[i] == '"'
+		if quoted {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			raw = string(runes[i+1 : end])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			raw = string(runes[i:end])
+			i = end
+		}
+
+		if !quoted && !excluded && raw == "OR" {
+			orNext = len(query.Clauses) > 0
+			continue
+		}
+
+		term, ok := newTerm(raw)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case excluded:
+			query.Excluded = append(query.Excluded, term)
+		case orNext:
+			last := len(query.Clauses) - 1
+			query.Clauses[last] = append(query.Clauses[last], term)
+		default:
+			query.Clauses = append(query.Clauses, []Term{term})
+		}
+		orNext = false
+	}
+
+	if len(query.Clauses) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return query, nil
+}
+
+func newTerm(raw string) (Term, bool) {
+	raw = strings.TrimSpace(raw)
+	prefix := strings.HasSuffix(raw, "*")
+
+	words := Tokenize(raw)
+	if len(words) == 0 {
+		return Term{}, false
+	}
+
+	return Term{Words: words, Prefix: prefix}, true
+}
+
+// Tokenize splits text into lowercased words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// TSQuery writes the term in the syntax of the postgres to_tsquery function.
+func (t Term) TSQuery() string {
+	query := strings.Join(t.Words, " <-> ")
+	if t.Prefix {
+		query += ":*"
+	}
+	if len(t.Words) > 1 {
+		query = "(" + query + ")"
+	}
+
+	return query
+}
+
+// Match tells whether the term occurs in words, as returned by Tokenize.
+func (t Term) Match(words []string) bool {
+	return t.Index(words) >= 0
+}
+
+// Index returns the position of the first occurrence of the term in words,
+// or -1.
+func (t Term) Index(words []string) int {
+	for start := 0; start+len(t.Words) <= len(words); start++ {
+		if t.matchAt(words, start) {
+			return start
+		}
+	}
+
+	return -1
+}
+
+func (t Term) matchAt(words []string, start int) bool {
+	for ind, word := range t.Words {
+		candidate := words[start+ind]
+		if t.Prefix && ind == len(t.Words)-1 {
+			if !strings.HasPrefix(candidate, word) {
+				return false
+			}
+		} else if word != "" && candidate != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClauseTSQuery writes the alternatives of a clause in the syntax of the
+// postgres to_tsquery function.
+func ClauseTSQuery(clause []Term) string {
+	return joinTSQuery(clause, " | ")
+}
+
+// AnyTSQuery matches text holding any of the terms of the clauses, which is
+// what a part of a matching text, such as a single verse, holds.
+func (q *Query) AnyTSQuery() string {
+	var terms []Term
+	for _, clause := range q.Clauses {
+		terms = append(terms, clause...)
+	}
+
+	return joinTSQuery(terms, " | ")
+}
+
+// ExcludedTSQuery matches text holding any of the excluded terms, or is empty
+// when there are none.
+func (q *Query) ExcludedTSQuery() string {
+	return joinTSQuery(q.Excluded, " | ")
+}
+
+func joinTSQuery(terms []Term, operator string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, term.TSQuery())
+	}
+
+	return strings.Join(parts, operator)
+}
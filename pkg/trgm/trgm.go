@@ -0,0 +1,61 @@
+// Package trgm measures how alike two strings are by the trigrams they share,
+// the way the postgres pg_trgm extension does.
+package trgm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Trigrams returns the set of trigrams of s. As in pg_trgm, s is lowercased
+// and split into words of letters and digits, and every word is padded with
+// two spaces in front and one behind.
+func Trigrams(s string) map[string]struct{} {
+	trigrams := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			trigrams[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+
+	return trigrams
+}
+
+// Similarity returns the share of the trigrams of a and b that both have,
+// from 0 to 1, as the pg_trgm similarity function does.
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	common := countCommon(ta, tb)
+	if total := len(ta) + len(tb) - common; total > 0 {
+		return float64(common) / float64(total)
+	}
+
+	return 0
+}
+
+// WordSimilarity returns the share of the trigrams of needle found in
+// haystack, from 0 to 1, as the pg_trgm word_similarity function does. It is
+// 1 when needle is a whole word of haystack, however long haystack is.
+func WordSimilarity(needle, haystack string) float64 {
+	tn := Trigrams(needle)
+	if len(tn) == 0 {
+		return 0
+	}
+
+	return float64(countCommon(tn, Trigrams(haystack))) / float64(len(tn))
+}
+
+func countCommon(a, b map[string]struct{}) int {
+	var common int
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			common++
+		}
+	}
+
+	return common
+}